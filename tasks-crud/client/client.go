@@ -0,0 +1,251 @@
+// Package client is a Go client for the Tasks REST API.
+//
+//	c, err := client.New("http://localhost:8080")
+//	task, err := c.CreateTask(ctx, client.CreateTaskRequest{Title: "Write docs"})
+//
+// Requests that fail with a network error, 429 or a 5xx status are retried
+// with exponential backoff. POST requests carry an Idempotency-Key so that a
+// retried create never produces a duplicate task.
+package client
+
+import (
+	"bytes"
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+    defaultTimeout    = 30 * time.Second
+    defaultMaxRetries = 3
+    defaultMinBackoff = 200 * time.Millisecond
+    defaultMaxBackoff = 5 * time.Second
+    apiPrefix         = "/api/v1"
+    userAgent         = "tasks-crud-client/1"
+)
+
+type Client struct {
+    baseURL    *url.URL
+    httpClient *http.Client
+    token      string
+    userAgent  string
+    maxRetries int
+    minBackoff time.Duration
+    maxBackoff time.Duration
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces the default HTTP client with a 30s timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+    return func(c *Client) {
+        c.httpClient = httpClient
+    }
+}
+
+// WithToken sends the token as a bearer credential with every request.
+func WithToken(token string) Option {
+    return func(c *Client) {
+        c.token = token
+    }
+}
+
+func WithUserAgent(userAgent string) Option {
+    return func(c *Client) {
+        c.userAgent = userAgent
+    }
+}
+
+// WithRetries sets how many times a failed request is retried; 0 disables retries.
+func WithRetries(maxRetries int) Option {
+    return func(c *Client) {
+        c.maxRetries = max(maxRetries, 0)
+    }
+}
+
+// WithBackoff sets the delay before the first retry and the upper bound the
+// delay grows to.
+func WithBackoff(minBackoff, maxBackoff time.Duration) Option {
+    return func(c *Client) {
+        c.minBackoff = minBackoff
+        c.maxBackoff = max(maxBackoff, minBackoff)
+    }
+}
+
+// New creates a client for the server at baseURL, e.g. "http://localhost:8080".
+func New(baseURL string, opts ...Option) (*Client, error) {
+    u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+    if err != nil {
+        return nil, fmt.Errorf("invalid base URL: %w", err)
+    }
+    if u.Scheme != "http" && u.Scheme != "https" {
+        return nil, fmt.Errorf("invalid base URL '%s': scheme must be http or https", baseURL)
+    }
+    
+    c := &Client{
+        baseURL:    u,
+        httpClient: &http.Client{Timeout: defaultTimeout},
+        userAgent:  userAgent,
+        maxRetries: defaultMaxRetries,
+        minBackoff: defaultMinBackoff,
+        maxBackoff: defaultMaxBackoff,
+    }
+    for _, opt := range opts {
+        opt(c)
+    }
+    
+    return c, nil
+}
+
+type request struct {
+    method      string
+    path        string
+    query       url.Values
+    body        interface{}
+    contentType string
+}
+
+// do sends req, retrying transient failures, and decodes a successful JSON
+// response into out when out is not nil.
+func (c *Client) do(ctx context.Context, req request, out interface{}) (*http.Response, error) {
+    var body []byte
+    if req.body != nil {
+        var err error
+        body, err = json.Marshal(req.body)
+        if err != nil {
+            return nil, fmt.Errorf("failed to encode request: %w", err)
+        }
+    }
+    
+    var idempotencyKey string
+    if req.method == http.MethodPost {
+        idempotencyKey = newIdempotencyKey()
+    }
+    
+    for attempt := 0; ; attempt++ {
+        resp, err := c.send(ctx, req, body, idempotencyKey)
+        if err == nil && resp.StatusCode < 400 {
+            defer resp.Body.Close()
+            if out != nil {
+                if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+                    return nil, fmt.Errorf("failed to decode response: %w", err)
+                }
+            }
+            return resp, nil
+        }
+        
+        if err != nil {
+            if ctx.Err() != nil || attempt >= c.maxRetries {
+                return nil, err
+            }
+        } else {
+            apiErr := decodeError(resp)
+            if !retryable(resp.StatusCode) || attempt >= c.maxRetries {
+                return nil, apiErr
+            }
+            err = apiErr
+        }
+        
+        if waitErr := c.wait(ctx, attempt, err); waitErr != nil {
+            return nil, err
+        }
+    }
+}
+
+func (c *Client) send(ctx context.Context, req request, body []byte, idempotencyKey string) (*http.Response, error) {
+    u := c.baseURL.JoinPath(apiPrefix, req.path)
+    if len(req.query) > 0 {
+        u.RawQuery = req.query.Encode()
+    }
+    
+    var reader io.Reader
+    if body != nil {
+        reader = bytes.NewReader(body)
+    }
+    
+    httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), reader)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create request: %w", err)
+    }
+    
+    httpReq.Header.Set("Accept", "application/json")
+    httpReq.Header.Set("User-Agent", c.userAgent)
+    if body != nil {
+        contentType := req.contentType
+        if contentType == "" {
+            contentType = "application/json"
+        }
+        httpReq.Header.Set("Content-Type", contentType)
+    }
+    if idempotencyKey != "" {
+        httpReq.Header.Set("Idempotency-Key", idempotencyKey)
+    }
+    if c.token != "" {
+        httpReq.Header.Set("Authorization", "Bearer "+c.token)
+    }
+    
+    resp, err := c.httpClient.Do(httpReq)
+    if err != nil {
+        return nil, fmt.Errorf("%s %s: %w", req.method, u.Path, err)
+    }
+    
+    return resp, nil
+}
+
+// wait sleeps before the next attempt: the server's Retry-After if it sent
+// one, otherwise exponential backoff with full jitter.
+func (c *Client) wait(ctx context.Context, attempt int, err error) error {
+    delay := c.minBackoff << attempt
+    if delay <= 0 || delay > c.maxBackoff {
+        delay = c.maxBackoff
+    }
+    delay = time.Duration(rand.Int64N(int64(delay) + 1))
+    
+    if apiErr, ok := err.(*APIError); ok && apiErr.RetryAfter > 0 {
+        delay = apiErr.RetryAfter
+    }
+    
+    timer := time.NewTimer(delay)
+    defer timer.Stop()
+    
+    select {
+    case <-ctx.Done():
+        return ctx.Err()
+    case <-timer.C:
+        return nil
+    }
+}
+
+func retryable(statusCode int) bool {
+    return statusCode == http.StatusTooManyRequests || (statusCode >= 500 && statusCode != http.StatusNotImplemented)
+}
+
+func parseRetryAfter(value string) time.Duration {
+    if value == "" {
+        return 0
+    }
+    if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+        return time.Duration(seconds) * time.Second
+    }
+    if at, err := http.ParseTime(value); err == nil {
+        return max(time.Until(at), 0)
+    }
+    return 0
+}
+
+func newIdempotencyKey() string {
+    buf := make([]byte, 16)
+    if _, err := cryptorand.Read(buf); err != nil {
+        return strconv.FormatInt(time.Now().UnixNano(), 36)
+    }
+    return hex.EncodeToString(buf)
+}
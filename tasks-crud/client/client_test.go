@@ -0,0 +1,232 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tasks-crud/client"
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/handler"
+	"tasks-crud/internal/middleware"
+	"tasks-crud/internal/repository"
+	"tasks-crud/internal/service"
+)
+
+// newServer serves handler.TaskHandler behind the idempotency middleware,
+// which is how cmd/api serves /api/v1/tasks. wrap, if set, wraps the
+// whole server.
+func newServer(t *testing.T, wrap func(next http.Handler) http.Handler) *client.Client {
+    t.Helper()
+    return newServerFor(t, service.NewTaskService(repository.NewEmptyInMemoryTaskRepository()), wrap)
+}
+
+func newServerFor(t *testing.T, taskService *service.TaskService, wrap func(next http.Handler) http.Handler) *client.Client {
+    t.Helper()
+    
+    mux := http.NewServeMux()
+    mux.HandleFunc("/api/v1/tasks/events", handler.NewEventsHandler(taskService).Stream)
+    mux.Handle("/api/v1/", middleware.Idempotency(middleware.NewIdempotencyStore(time.Hour))(handler.NewTaskHandler(taskService)))
+    
+    var h http.Handler = mux
+    if wrap != nil {
+        h = wrap(mux)
+    }
+    server := httptest.NewServer(h)
+    t.Cleanup(server.Close)
+    
+    c, err := client.New(server.URL, client.WithBackoff(time.Millisecond, 10*time.Millisecond))
+    if err != nil {
+        t.Fatal(err)
+    }
+    return c
+}
+
+func TestTaskLifecycle(t *testing.T) {
+    c := newServer(t, nil)
+    ctx := context.Background()
+    
+    parent, err := c.CreateTask(ctx, client.CreateTaskRequest{Title: "Release"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    due := time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)
+    task, err := c.CreateTask(ctx, client.CreateTaskRequest{
+        Title:    "Write notes",
+        Priority: client.PriorityHigh,
+        Tags:     []string{"docs"},
+        DueDate:  &due,
+        ParentID: &parent.ID,
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    if task.ID == 0 || task.Priority != client.PriorityHigh || len(task.Tags) != 1 || task.ParentID == nil || *task.ParentID != parent.ID || !task.DueDate.Equal(due) {
+        t.Fatalf("created task = %+v", task)
+    }
+    
+    got, err := c.GetTask(ctx, task.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got.Title != "Write notes" || got.Revision != task.Revision {
+        t.Errorf("GetTask = %+v, want %+v", got, task)
+    }
+    
+    title, completed, detach := "Write release notes", true, 0
+    updated, err := c.UpdateTask(ctx, task.ID, client.UpdateTaskRequest{Title: &title, Completed: &completed, ParentID: &detach})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if updated.Title != title || !updated.Completed || updated.ParentID != nil || updated.Priority != client.PriorityHigh {
+        t.Errorf("updated task = %+v", updated)
+    }
+    
+    if err := c.DeleteTask(ctx, task.ID); err != nil {
+        t.Fatal(err)
+    }
+    _, err = c.GetTask(ctx, task.ID)
+    var apiErr *client.APIError
+    if !errors.Is(err, client.ErrNotFound) || !errors.As(err, &apiErr) || apiErr.Message != "Task not found" {
+        t.Errorf("GetTask after delete: %v, want a 404 APIError", err)
+    }
+    if _, err := c.UpdateTask(ctx, task.ID, client.UpdateTaskRequest{Title: &title}); !errors.Is(err, client.ErrNotFound) {
+        t.Errorf("UpdateTask after delete: %v, want ErrNotFound", err)
+    }
+}
+
+func TestCreateValidationError(t *testing.T) {
+    c := newServer(t, nil)
+    
+    _, err := c.CreateTask(context.Background(), client.CreateTaskRequest{Title: ""})
+    if !errors.Is(err, client.ErrBadRequest) {
+        t.Errorf("CreateTask without title: %v, want ErrBadRequest", err)
+    }
+}
+
+func TestCreateOverQuota(t *testing.T) {
+    var requests atomic.Int32
+    taskService := service.NewTaskService(repository.NewEmptyInMemoryTaskRepository()).WithQuotas(domain.Quotas{MaxTasksPerTenant: 1})
+    c := newServerFor(t, taskService, func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            requests.Add(1)
+            next.ServeHTTP(w, r)
+        })
+    })
+    ctx := context.Background()
+    
+    if _, err := c.CreateTask(ctx, client.CreateTaskRequest{Title: "first"}); err != nil {
+        t.Fatal(err)
+    }
+    _, err := c.CreateTask(ctx, client.CreateTaskRequest{Title: "second"})
+    if !errors.Is(err, client.ErrQuotaExceeded) || !errors.Is(err, client.ErrConflict) || errors.Is(err, client.ErrForbidden) {
+        t.Fatalf("CreateTask over quota: %v, want ErrQuotaExceeded", err)
+    }
+    if _, err := c.CreateTask(ctx, client.CreateTaskRequest{Title: "first"}); errors.Is(err, client.ErrQuotaExceeded) {
+        t.Errorf("a duplicate title was reported as over quota: %v", err)
+    }
+    if n := requests.Load(); n != 3 {
+        t.Errorf("%d requests sent, want 3: quota errors are not retried", n)
+    }
+}
+
+func TestListPages(t *testing.T) {
+    c := newServer(t, nil)
+    ctx := context.Background()
+    
+    for _, title := range []string{"a", "b", "c", "d", "e"} {
+        if _, err := c.CreateTask(ctx, client.CreateTaskRequest{Title: title}); err != nil {
+            t.Fatal(err)
+        }
+    }
+    
+    pages := 0
+    for page, err := range c.Pages(ctx, client.ListOptions{PageSize: 2}) {
+        if err != nil {
+            t.Fatal(err)
+        }
+        pages++
+        if page.Total != 5 {
+            t.Errorf("page %d: Total = %d, want 5", pages, page.Total)
+        }
+    }
+    if pages != 3 {
+        t.Errorf("got %d pages, want 3", pages)
+    }
+    
+    var titles []string
+    for task, err := range c.AllTasks(ctx, client.ListOptions{Query: "sort:title", PageSize: 2}) {
+        if err != nil {
+            t.Fatal(err)
+        }
+        titles = append(titles, task.Title)
+    }
+    if len(titles) != 5 || titles[0] != "a" || titles[4] != "e" {
+        t.Errorf("AllTasks titles = %v", titles)
+    }
+}
+
+// The server creates the task but its response is lost; the retry must be
+// answered from the idempotency store instead of creating a second task.
+func TestCreateRetriesWithoutDuplicates(t *testing.T) {
+    var lost atomic.Bool
+    c := newServer(t, func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            if r.Method == http.MethodPost && lost.CompareAndSwap(false, true) {
+                next.ServeHTTP(httptest.NewRecorder(), r)
+                http.Error(w, `{"error":"Bad gateway"}`, http.StatusBadGateway)
+                return
+            }
+            next.ServeHTTP(w, r)
+        })
+    })
+    ctx := context.Background()
+    
+    task, err := c.CreateTask(ctx, client.CreateTaskRequest{Title: "once"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    page, err := c.ListTasks(ctx, client.ListOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !lost.Load() || page.Total != 1 || page.Tasks[0].ID != task.ID {
+        t.Errorf("after a retried create: %d tasks, want only task %d", page.Total, task.ID)
+    }
+}
+
+func TestWatch(t *testing.T) {
+    c := newServer(t, nil)
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    
+    events, err := c.Watch(ctx)
+    if err != nil {
+        t.Fatal(err)
+    }
+    task, err := c.CreateTask(ctx, client.CreateTaskRequest{Title: "watched", Tags: []string{"live"}})
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    select {
+    case event, ok := <-events:
+        if !ok {
+            t.Fatal("event stream closed")
+        }
+        if event.Type != client.TaskEventCreated || event.TaskID != task.ID || event.Task == nil || event.Task.Tags[0] != "live" {
+            t.Errorf("event = %+v, want creation of task %d", event, task.ID)
+        }
+    case <-ctx.Done():
+        t.Fatal("no event received")
+    }
+    
+    cancel()
+    for range events {
+    }
+}
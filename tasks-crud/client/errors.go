@@ -0,0 +1,94 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Sentinel errors matched by APIError via errors.Is.
+var (
+    ErrBadRequest    = errors.New("bad request")
+    ErrUnauthorized  = errors.New("unauthorized")
+    ErrForbidden     = errors.New("forbidden")
+    ErrNotFound      = errors.New("not found")
+    ErrConflict      = errors.New("conflict")
+    // ErrQuotaExceeded is a conflict too: the task would go over a quota.
+    ErrQuotaExceeded = errors.New("quota exceeded")
+    ErrRateLimited   = errors.New("rate limited")
+    ErrServer        = errors.New("server error")
+)
+
+// APIError is returned for every non-2xx response.
+type APIError struct {
+    StatusCode int
+    Message    string
+    Details    string
+    // Code tells some errors apart, e.g. "QUOTA_EXCEEDED".
+    Code       string
+    // Query and Position are set when the server rejected a list query.
+    Query      string
+    Position   int
+    RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+    msg := fmt.Sprintf("tasks api: %d %s", e.StatusCode, e.Message)
+    if e.Details != "" {
+        msg += ": " + e.Details
+    }
+    return msg
+}
+
+func (e *APIError) Is(target error) bool {
+    switch target {
+    case ErrBadRequest:
+        return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+    case ErrUnauthorized:
+        return e.StatusCode == http.StatusUnauthorized
+    case ErrForbidden:
+        return e.StatusCode == http.StatusForbidden
+    case ErrNotFound:
+        return e.StatusCode == http.StatusNotFound
+    case ErrConflict:
+        return e.StatusCode == http.StatusConflict
+    case ErrQuotaExceeded:
+        return e.StatusCode == http.StatusConflict && e.Code == "QUOTA_EXCEEDED"
+    case ErrRateLimited:
+        return e.StatusCode == http.StatusTooManyRequests
+    case ErrServer:
+        return e.StatusCode >= 500
+    }
+    return false
+}
+
+func decodeError(resp *http.Response) *APIError {
+    defer resp.Body.Close()
+    
+    apiErr := &APIError{
+        StatusCode: resp.StatusCode,
+        Message:    http.StatusText(resp.StatusCode),
+        RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+    }
+    
+    var body struct {
+        Error    string `json:"error"`
+        Details  string `json:"details"`
+        Code     string `json:"code"`
+        Query    string `json:"query"`
+        Position int    `json:"position"`
+    }
+    data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+    if err := json.Unmarshal(data, &body); err == nil && body.Error != "" {
+        apiErr.Message = body.Error
+        apiErr.Details = body.Details
+        apiErr.Code = body.Code
+        apiErr.Query = body.Query
+        apiErr.Position = body.Position
+    }
+    
+    return apiErr
+}
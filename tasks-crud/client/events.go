@@ -0,0 +1,94 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Types of TaskEvent.
+const (
+    TaskEventCreated = "created"
+    TaskEventUpdated = "updated"
+    TaskEventDeleted = "deleted"
+)
+
+// TaskEvent is a change to a task. Task is the task after the change, or
+// as it was when deleted.
+type TaskEvent struct {
+    Type     string    `json:"type"`
+    TaskID   int       `json:"task_id"`
+    Revision int64     `json:"revision"`
+    Task     *Task     `json:"task,omitempty"`
+    At       time.Time `json:"at"`
+}
+
+// Watch streams task change events from GET /tasks/events. The channel is
+// closed when ctx is cancelled, the connection drops or the server drops a
+// client that fell behind; Watch does not reconnect by itself, and changes
+// missed in between have to be fetched again (e.g. GET /sync).
+func (c *Client) Watch(ctx context.Context) (<-chan TaskEvent, error) {
+    req := request{method: http.MethodGet, path: "/tasks/events"}
+    
+    // The default client timeout would cut the stream, so use one without it.
+    streaming := *c
+    httpClient := *c.httpClient
+    httpClient.Timeout = 0
+    streaming.httpClient = &httpClient
+    
+    resp, err := streaming.send(ctx, req, nil, "")
+    if err != nil {
+        return nil, err
+    }
+    if resp.StatusCode >= 400 {
+        return nil, decodeError(resp)
+    }
+    if mediaType := resp.Header.Get("Content-Type"); !strings.HasPrefix(mediaType, "text/event-stream") {
+        resp.Body.Close()
+        return nil, fmt.Errorf("unexpected content type '%s' for event stream", mediaType)
+    }
+    
+    events := make(chan TaskEvent)
+    go func() {
+        defer close(events)
+        defer resp.Body.Close()
+        
+        scanner := bufio.NewScanner(resp.Body)
+        scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+        
+        var data strings.Builder
+        for scanner.Scan() {
+            line := scanner.Text()
+            switch {
+            case line == "":
+                if data.Len() == 0 {
+                    continue
+                }
+                var event TaskEvent
+                err := json.Unmarshal([]byte(data.String()), &event)
+                data.Reset()
+                // The closing "resync" event carries no task; the channel
+                // is closed right after it.
+                if err != nil || event.Type == "" {
+                    continue
+                }
+                select {
+                case events <- event:
+                case <-ctx.Done():
+                    return
+                }
+            case strings.HasPrefix(line, "data:"):
+                if data.Len() > 0 {
+                    data.WriteByte('\n')
+                }
+                data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+            }
+        }
+    }()
+    
+    return events, nil
+}
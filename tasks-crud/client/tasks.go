@@ -0,0 +1,221 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Task priorities.
+const (
+    PriorityNone   = 0
+    PriorityLow    = 1
+    PriorityMedium = 2
+    PriorityHigh   = 3
+)
+
+// Task is a task as the API returns it.
+type Task struct {
+    ID          int        `json:"id"`
+    Title       string     `json:"title"`
+    Completed   bool       `json:"completed"`
+    Priority    int        `json:"priority"`
+    Tags        []string   `json:"tags"`
+    DueDate     *time.Time `json:"due_date"`
+    ParentID    *int       `json:"parent_id"`
+    CreatedAt   time.Time  `json:"created_at"`
+    UpdatedAt   time.Time  `json:"updated_at"`
+    CompletedAt *time.Time `json:"completed_at"`
+    Revision    int64      `json:"revision"`
+    OwnerID     string     `json:"owner_id,omitempty"`
+}
+
+type CreateTaskRequest struct {
+    Title    string     `json:"title"`
+    Priority int        `json:"priority,omitempty"`
+    Tags     []string   `json:"tags,omitempty"`
+    DueDate  *time.Time `json:"due_date,omitempty"`
+    ParentID *int       `json:"parent_id,omitempty"`
+}
+
+// UpdateTaskRequest lists the fields UpdateTask changes; nil ones are left
+// as they are.
+type UpdateTaskRequest struct {
+    Title     *string
+    Completed *bool
+    Priority  *int
+    Tags      []string
+    DueDate   *time.Time
+    ParentID  *int
+}
+
+// ListOptions filters and pages ListTasks. Status and Tags are shorthands
+// appended to Query, which accepts the full task query language, e.g.
+// "tag:backend due<7d sort:priority".
+type ListOptions struct {
+    Query    string
+    Status   string
+    Tags     []string
+    PageSize int
+    Offset   int
+}
+
+// TaskPage is one page of ListTasks results.
+type TaskPage struct {
+    Tasks      []Task
+    Total      int
+    NextOffset int
+    HasNext    bool
+}
+
+func (c *Client) CreateTask(ctx context.Context, req CreateTaskRequest) (*Task, error) {
+    var task Task
+    if _, err := c.do(ctx, request{method: http.MethodPost, path: "/tasks", body: req}, &task); err != nil {
+        return nil, err
+    }
+    return &task, nil
+}
+
+func (c *Client) GetTask(ctx context.Context, id int) (*Task, error) {
+    var task Task
+    if _, err := c.do(ctx, request{method: http.MethodGet, path: taskPath(id)}, &task); err != nil {
+        return nil, err
+    }
+    return &task, nil
+}
+
+// UpdateTask changes the fields set in req and leaves the others untouched.
+// Tags replace the current tags when non-nil, ParentID 0 detaches the task.
+func (c *Client) UpdateTask(ctx context.Context, id int, req UpdateTaskRequest) (*Task, error) {
+    mergePatch := make(map[string]interface{})
+    if req.Title != nil {
+        mergePatch["title"] = *req.Title
+    }
+    if req.Completed != nil {
+        mergePatch["completed"] = *req.Completed
+    }
+    if req.Priority != nil {
+        mergePatch["priority"] = *req.Priority
+    }
+    if req.Tags != nil {
+        mergePatch["tags"] = req.Tags
+    }
+    if req.DueDate != nil {
+        mergePatch["due_date"] = req.DueDate
+    }
+    if req.ParentID != nil {
+        if *req.ParentID == 0 {
+            mergePatch["parent_id"] = nil
+        } else {
+            mergePatch["parent_id"] = *req.ParentID
+        }
+    }
+    
+    var task Task
+    _, err := c.do(ctx, request{
+        method:      http.MethodPatch,
+        path:        taskPath(id),
+        body:        mergePatch,
+        contentType: "application/merge-patch+json",
+    }, &task)
+    if err != nil {
+        return nil, err
+    }
+    return &task, nil
+}
+
+func (c *Client) DeleteTask(ctx context.Context, id int) error {
+    _, err := c.do(ctx, request{method: http.MethodDelete, path: taskPath(id)}, nil)
+    return err
+}
+
+// ListTasks returns a single page of tasks. Without PageSize all matching
+// tasks are returned at once.
+func (c *Client) ListTasks(ctx context.Context, opts ListOptions) (*TaskPage, error) {
+    query := url.Values{}
+    if q := opts.query(); q != "" {
+        query.Set("q", q)
+    }
+    if opts.PageSize > 0 {
+        query.Set("limit", strconv.Itoa(opts.PageSize))
+    }
+    if opts.Offset > 0 {
+        query.Set("offset", strconv.Itoa(opts.Offset))
+    }
+    
+    var tasks []Task
+    resp, err := c.do(ctx, request{method: http.MethodGet, path: "/tasks", query: query}, &tasks)
+    if err != nil {
+        return nil, err
+    }
+    
+    page := &TaskPage{
+        Tasks:      tasks,
+        Total:      len(tasks),
+        NextOffset: opts.Offset + len(tasks),
+    }
+    if total, err := strconv.Atoi(resp.Header.Get("X-Total-Count")); err == nil {
+        page.Total = total
+    }
+    page.HasNext = len(tasks) > 0 && page.NextOffset < page.Total
+    
+    return page, nil
+}
+
+// Pages iterates over all pages matching opts, starting at opts.Offset.
+// Iteration stops after the first error.
+func (c *Client) Pages(ctx context.Context, opts ListOptions) iter.Seq2[*TaskPage, error] {
+    return func(yield func(*TaskPage, error) bool) {
+        for {
+            page, err := c.ListTasks(ctx, opts)
+            if err != nil {
+                yield(nil, err)
+                return
+            }
+            if !yield(page, nil) || !page.HasNext {
+                return
+            }
+            opts.Offset = page.NextOffset
+        }
+    }
+}
+
+// AllTasks iterates over every task matching opts, fetching pages lazily.
+func (c *Client) AllTasks(ctx context.Context, opts ListOptions) iter.Seq2[Task, error] {
+    return func(yield func(Task, error) bool) {
+        for page, err := range c.Pages(ctx, opts) {
+            if err != nil {
+                yield(Task{}, err)
+                return
+            }
+            for _, task := range page.Tasks {
+                if !yield(task, nil) {
+                    return
+                }
+            }
+        }
+    }
+}
+
+func (o ListOptions) query() string {
+    parts := make([]string, 0, 3)
+    if o.Status != "" {
+        parts = append(parts, "status:"+o.Status)
+    }
+    if len(o.Tags) > 0 {
+        parts = append(parts, "tag:"+strings.Join(o.Tags, ","))
+    }
+    if q := strings.TrimSpace(o.Query); q != "" {
+        parts = append(parts, q)
+    }
+    return strings.Join(parts, " ")
+}
+
+func taskPath(id int) string {
+    return fmt.Sprintf("/tasks/%d", id)
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"crypto/rand"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"strconv"
 	"time"
@@ -12,124 +15,181 @@ import (
 
 	"github.com/gorilla/mux"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
 
+	"tasks-crud/internal/caldav"
+	"tasks-crud/internal/certs"
 	"tasks-crud/internal/config"
 	"tasks-crud/internal/domain"
+	"tasks-crud/internal/gql"
+	"tasks-crud/internal/grpcserver"
+	"tasks-crud/internal/handler"
+	"tasks-crud/internal/middleware"
+	"tasks-crud/internal/oidc"
 	"tasks-crud/internal/repository"
 	"tasks-crud/internal/service"
+	"tasks-crud/internal/web"
 )
 
-type TaskHandler struct {
-    service *service.TaskService
+func HealthCheck(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]string{
+        "status":    "ok",
+        "timestamp": time.Now().Format(time.RFC3339),
+        "service":   "todo-api",
+    })
 }
 
-func NewTaskHandler(service *service.TaskService) *TaskHandler {
-    return &TaskHandler{service: service}
+// publicPaths stay reachable without a token: health checks, API docs,
+// CalDAV discovery, calendar feeds, which carry their own secret, the
+// endpoints and the web UI page that hand out tokens and the operator
+// endpoints, which check ADMIN_TOKEN instead.
+var publicPaths = []string{
+    "/health",
+    "/swagger/",
+    "/docs",
+    "/.well-known/",
+    "/api/v1/tasks.ics",
+    "/api/v1/auth/register",
+    "/api/v1/auth/login",
+    "/api/v1/auth/refresh",
+    "/api/v1/auth/oidc/",
+    "/api/v1/admin/",
+    "/static/",
+    "/login",
 }
 
-
-func (h *TaskHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
-    tasks, err := h.service.GetAllTasks()
-    if err != nil {
-        sendError(w, http.StatusInternalServerError, "Failed to get tasks", err)
-        return
-    }
-    sendJSON(w, http.StatusOK, tasks)
+// tenantlessPaths don't belong to any tenant. The OIDC callback gets its
+// tenant from the login it completes.
+var tenantlessPaths = []string{
+    "/health",
+    "/swagger/",
+    "/docs",
+    "/.well-known/",
+    "/api/v1/auth/oidc/callback",
+    "/api/v1/admin/",
+    "/static/",
 }
 
-func (h *TaskHandler) GetTaskByID(w http.ResponseWriter, r *http.Request) {
-    vars := mux.Vars(r)
-    id, err := strconv.Atoi(vars["id"])
-    if err != nil {
-        sendError(w, http.StatusBadRequest, "Invalid task ID", err)
-        return
+func newJWTVerifier(cfg *config.Config) *middleware.JWTVerifier {
+    var keys middleware.KeySets
+    if cfg.JWTSecret != "" {
+        keys = append(keys, middleware.SecretKey(cfg.JWTSecret))
+    }
+    if cfg.JWKS != "" {
+        keys = append(keys, middleware.NewJWKS(cfg.JWKS, cfg.JWKSCacheTTL))
     }
     
-    task, err := h.service.GetTaskByID(id)
-    if err != nil {
-        sendError(w, http.StatusNotFound, "Task not found", err)
-        return
+    return &middleware.JWTVerifier{
+        Keys:     keys,
+        Issuer:   cfg.JWTIssuer,
+        Audience: cfg.JWTAudience,
+        Skew:     cfg.JWTClockSkew,
     }
-    sendJSON(w, http.StatusOK, task)
 }
 
-func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
-    var req domain.CreateTaskRequest
-    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        sendError(w, http.StatusBadRequest, "Invalid JSON", err)
-        return
+// newAuthService issues tokens for local accounts. They are signed with
+// JWT_SECRET; when only JWT_JWKS is set, a per-process key is generated, so
+// sessions don't survive a restart. The verifier accepts tokens of the
+// local issuer only with that key. With JWT_JWKS the local issuer is never
+// JWT_ISSUER, so that the key set's tokens can't pass for local accounts.
+func newAuthService(cfg *config.Config, verifier *middleware.JWTVerifier, users repository.UserRepository, sessions repository.SessionRepository) *service.AuthService {
+    signingKey := []byte(cfg.JWTSecret)
+    if len(signingKey) == 0 {
+        signingKey = make([]byte, 32)
+        if _, err := rand.Read(signingKey); err != nil {
+            log.Fatalf("Failed to generate signing key: %v", err)
+        }
     }
-    defer r.Body.Close()
     
-    task, err := h.service.CreateTask(req)
-    if err != nil {
-        sendError(w, http.StatusBadRequest, "Failed to create task", err)
-        return
+    issuer := cfg.JWTIssuer
+    if issuer == "" || cfg.JWKS != "" {
+        issuer = middleware.AuthRealm
     }
+    verifier.LocalKeys = middleware.SecretKey(signingKey)
+    verifier.LocalIssuer = issuer
+    
+    authService := service.NewAuthService(
+        users,
+        sessions,
+        service.AuthConfig{
+            SigningKey:   signingKey,
+            Issuer:       issuer,
+            Audience:     cfg.JWTAudience,
+            AccessTTL:    cfg.AccessTokenTTL,
+            RefreshTTL:   cfg.RefreshTokenTTL,
+            PasswordHash: cfg.PasswordHash,
+        },
+    )
+    verifier.Sessions = authService
     
-    sendJSON(w, http.StatusCreated, task)
+    return authService
 }
 
-func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
-    vars := mux.Vars(r)
-    id, err := strconv.Atoi(vars["id"])
-    if err != nil {
-        sendError(w, http.StatusBadRequest, "Invalid task ID", err)
-        return
+// newOIDCService sets up single sign-on. Unknown roles in the group
+// mapping are a configuration error.
+func newOIDCService(cfg *config.Config, auth *service.AuthService, users repository.UserRepository) *service.OIDCService {
+    groupRoles := make(map[string]domain.Role, len(cfg.OIDCGroupRoles))
+    for group, role := range cfg.OIDCGroupRoles {
+        if !domain.Role(role).Valid() {
+            log.Fatalf("Unknown role %q for group %q in OIDC_GROUP_ROLES", role, group)
+        }
+        groupRoles[group] = domain.Role(role)
     }
     
-    var req domain.UpdateTaskRequest
-    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        sendError(w, http.StatusBadRequest, "Invalid JSON", err)
-        return
+    defaultRole := domain.Role(cfg.OIDCDefaultRole)
+    if defaultRole != "" && !defaultRole.Valid() {
+        log.Fatalf("Unknown role %q in OIDC_DEFAULT_ROLE", cfg.OIDCDefaultRole)
     }
-    defer r.Body.Close()
     
-    task, err := h.service.UpdateTask(id, req)
-    if err != nil {
-        sendError(w, http.StatusBadRequest, "Failed to update task", err)
-        return
-    }
+    client := oidc.NewClient(oidc.Config{
+        Issuer:       cfg.OIDCIssuer,
+        ClientID:     cfg.OIDCClientID,
+        ClientSecret: cfg.OIDCSecret,
+        RedirectURL:  cfg.OIDCRedirectURL,
+        Scopes:       cfg.OIDCScopes,
+    })
     
-    sendJSON(w, http.StatusOK, task)
+    return service.NewOIDCService(client, auth, users, service.OIDCConfig{
+        GroupsClaim: cfg.OIDCGroupsClaim,
+        GroupRoles:  groupRoles,
+        DefaultRole: defaultRole,
+    })
 }
 
-func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
-    vars := mux.Vars(r)
-    id, err := strconv.Atoi(vars["id"])
+// newCertStore loads the server certificate and picks how client
+// certificates are checked: "require" turns away clients without one,
+// "optional" verifies only those that present one.
+func newCertStore(cfg *config.Config) (*certs.Store, tls.ClientAuthType) {
+    store, err := certs.NewStore(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCA)
     if err != nil {
-        sendError(w, http.StatusBadRequest, "Invalid task ID", err)
-        return
+        log.Fatalf("Failed to load TLS certificates: %v", err)
     }
     
-    if err := h.service.DeleteTask(id); err != nil {
-        sendError(w, http.StatusNotFound, "Task not found", err)
-        return
+    switch cfg.TLSClientAuth {
+    case "require":
+        return store, tls.RequireAndVerifyClientCert
+    case "optional":
+        return store, tls.VerifyClientCertIfGiven
     }
-    w.WriteHeader(http.StatusNoContent)
-}
-
-func HealthCheck(w http.ResponseWriter, r *http.Request) {
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(map[string]string{
-        "status":    "ok",
-        "timestamp": time.Now().Format(time.RFC3339),
-        "service":   "todo-api",
-    })
+    log.Fatalf("Unknown TLS_CLIENT_AUTH %q (use require or optional)", cfg.TLSClientAuth)
+    return nil, tls.NoClientCert
 }
 
-func sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
-    w.Header().Set("Content-Type", "application/json")
-    w.WriteHeader(statusCode)
-    json.NewEncoder(w).Encode(data)
-}
-
-func sendError(w http.ResponseWriter, statusCode int, message string, err error) {
-    w.Header().Set("Content-Type", "application/json")
-    w.WriteHeader(statusCode)
-    json.NewEncoder(w).Encode(domain.ErrorResponse{
-        Error:   message,
-        Details: err.Error(),
+// redirectToHTTPS sends plain HTTP requests to the same host on the HTTPS
+// port. 308 keeps the method and body of API calls.
+func redirectToHTTPS(port int) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        host := r.Host
+        if h, _, err := net.SplitHostPort(host); err == nil {
+            host = h
+        }
+        if port != 443 {
+            host = net.JoinHostPort(host, strconv.Itoa(port))
+        }
+        http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
     })
 }
 
@@ -138,21 +198,197 @@ func main() {
     
     cfg := config.Load()
     fmt.Printf("📋 Конфигурация:\n   Порт: %d\n", cfg.Port)
+    fmt.Printf("   Порт gRPC: %d\n", cfg.GRPCPort)
+    fmt.Printf("   TTL ключей идемпотентности: %s\n", cfg.IdempotencyTTL)
+    
+    var verifier *middleware.JWTVerifier
+    if cfg.AuthEnabled() {
+        verifier = newJWTVerifier(cfg)
+        fmt.Printf("   Аутентификация: JWT (издатель %q, аудитория %q)\n", cfg.JWTIssuer, cfg.JWTAudience)
+    } else {
+        fmt.Println("   ⚠️  Аутентификация выключена: задайте JWT_SECRET или JWT_JWKS")
+    }
+    if verifier != nil && cfg.OIDCEnabled() {
+        fmt.Printf("   Вход через OIDC: %s (клиент %q)\n", cfg.OIDCIssuer, cfg.OIDCClientID)
+    }
+    corsConfig := middleware.CORSConfig{
+        Origins:        cfg.CORSOrigins,
+        Methods:        cfg.CORSMethods,
+        Headers:        cfg.CORSHeaders,
+        ExposedHeaders: cfg.CORSExposed,
+        Credentials:    cfg.CORSCredentials,
+        MaxAge:         cfg.CORSMaxAge,
+    }
+    if err := corsConfig.Validate(); err != nil {
+        log.Fatalf("Invalid CORS settings: %v", err)
+    }
+    if cfg.MultiTenant {
+        fmt.Printf("   Мультитенантность: заголовок %s, домен %q, тенанты %v\n", cfg.TenantHeader, cfg.TenantDomain, cfg.Tenants)
+    }
+    
+    var certStore *certs.Store
+    clientAuth := tls.NoClientCert
+    scheme := "http"
+    if cfg.TLSEnabled() {
+        certStore, clientAuth = newCertStore(cfg)
+        go certStore.Watch(cfg.TLSPollInterval)
+        scheme = "https"
+        fmt.Printf("   TLS: %s (проверка изменений каждые %s)\n", cfg.TLSCertFile, cfg.TLSPollInterval)
+        if cfg.TLSClientCA != "" {
+            fmt.Printf("   Клиентские сертификаты (%s): %s\n", cfg.TLSClientAuth, cfg.TLSClientCA)
+        }
+    }
     
     taskRepo := repository.NewInMemoryTaskRepository()
-    taskService := service.NewTaskService(taskRepo)
-    taskHandler := NewTaskHandler(taskService)
+    userRepo := repository.NewInMemoryUserRepository()
+    sessionRepo := repository.NewInMemorySessionRepository()
+    apiKeyRepo := repository.NewInMemoryAPIKeyRepository()
+    shareRepo := repository.NewInMemoryShareRepository()
+    taskService := service.NewTaskService(taskRepo).WithShares(shareRepo).WithQuotas(domain.Quotas{
+        MaxTasksPerUser:   cfg.MaxUserTasks,
+        MaxTasksPerTenant: cfg.MaxTenantTasks,
+    })
+    shareHandler := handler.NewShareHandler(service.NewShareService(shareRepo, taskService, userRepo))
+    filterService := service.NewFilterService(repository.NewInMemorySavedFilterRepository(), taskService)
+    feedService := service.NewFeedService(repository.NewInMemoryCalendarFeedRepository(), taskService)
+    taskHandler := handler.NewTaskHandler(taskService)
+    syncHandler := handler.NewSyncHandler(taskService)
+    bulkHandler := handler.NewBulkHandler(taskService)
+    searchHandler := handler.NewSearchHandler(taskService)
+    filterHandler := handler.NewFilterHandler(filterService)
+    eventsHandler := handler.NewEventsHandler(taskService)
+    transferHandler := handler.NewTransferHandler(taskService)
+    feedHandler := handler.NewFeedHandler(feedService)
     
+    schema, err := gql.NewSchema(taskService)
+    if err != nil {
+        log.Fatalf("Failed to build GraphQL schema: %v", err)
+    }
+    graphQLHandler := handler.NewGraphQLHandler(schema)
+    
+    caldavHandler := caldav.NewHandler(taskService)
+    
+    webHandler, err := web.NewHandler(taskService)
+    if err != nil {
+        log.Fatalf("Failed to load web UI: %v", err)
+    }
+    
+    var grpcOptions []grpc.ServerOption
     router := mux.NewRouter()
+    if verifier != nil {
+        apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+        credentials := &middleware.Credentials{
+            Tokens:    verifier,
+            APIKeys:   apiKeyService,
+            KeyPrefix: service.APIKeyPrefix,
+        }
+        if certStore != nil && cfg.TLSClientCA != "" {
+            credentials.Certificates = middleware.ClientCertificates{}
+        }
+        authService := newAuthService(cfg, verifier, userRepo, sessionRepo)
+        webHandler = webHandler.WithLogin(authService)
+        router.Use(middleware.WebSession(verifier, authService, "/login", web.IsPage))
+        router.Use(middleware.RequireAuth(credentials, publicPaths...))
+        router.Use(middleware.APIKeyScopes([]string{"/api/v1/auth/", "/api/v1/api-keys"}, "/graphql"))
+        grpcOptions = grpcserver.WithAuth(credentials)
+    
+        // Token and key responses must not be replayed from the idempotency
+        // store, so these routes live outside the api subrouter.
+        apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+        router.HandleFunc("/api/v1/api-keys", apiKeyHandler.GetAllKeys).Methods("GET")
+        router.HandleFunc("/api/v1/api-keys", apiKeyHandler.CreateKey).Methods("POST")
+        router.HandleFunc("/api/v1/api-keys/{id}", apiKeyHandler.RevokeKey).Methods("DELETE")
+    
+        authHandler := handler.NewAuthHandler(authService)
+        auth := router.PathPrefix("/api/v1/auth").Subrouter()
+        auth.HandleFunc("/register", authHandler.Register).Methods("POST")
+        auth.HandleFunc("/login", authHandler.Login).Methods("POST")
+        auth.HandleFunc("/refresh", authHandler.Refresh).Methods("POST")
+        auth.HandleFunc("/logout", authHandler.Logout).Methods("POST")
+        auth.HandleFunc("/password", authHandler.ChangePassword).Methods("POST")
+        auth.HandleFunc("/me", authHandler.Me).Methods("GET")
+        
+        if cfg.OIDCEnabled() {
+            oidcHandler := handler.NewOIDCHandler(newOIDCService(cfg, authService, userRepo))
+            auth.HandleFunc("/oidc/login", oidcHandler.Login).Methods("GET")
+            auth.HandleFunc("/oidc/callback", oidcHandler.Callback).Methods("GET")
+        }
+    }
+    
+    if cfg.MultiTenant {
+        tenantRepo := repository.NewInMemoryTenantRepository()
+        tenantService := service.NewTenantService(tenantRepo, userRepo, sessionRepo, apiKeyRepo).WithCleanup(caldavHandler.ForgetTenant)
+        for _, id := range cfg.Tenants {
+            if _, err := tenantService.CreateTenant(domain.CreateTenantRequest{ID: id}); err != nil {
+                log.Fatalf("Failed to create tenant %q: %v", id, err)
+            }
+        }
+        
+        // Registered after RequireAuth, so the tenant claim of the caller
+        // is known when the tenant is resolved.
+        resolver := &middleware.TenantResolver{
+            Tenants: tenantRepo,
+            Header:  cfg.TenantHeader,
+            Domain:  cfg.TenantDomain,
+        }
+        router.Use(middleware.Tenancy(resolver, tenantlessPaths...))
+        grpcOptions = append(grpcOptions, grpcserver.WithTenancy(resolver)...)
+        
+        tenantHandler := handler.NewTenantHandler(tenantService)
+        admin := router.PathPrefix("/api/v1/admin").Subrouter()
+        admin.Use(middleware.AdminToken(cfg.AdminToken))
+        admin.HandleFunc("/tenants", tenantHandler.GetAllTenants).Methods("GET")
+        admin.HandleFunc("/tenants", tenantHandler.CreateTenant).Methods("POST")
+        admin.HandleFunc("/tenants/{id}", tenantHandler.GetTenant).Methods("GET")
+        admin.HandleFunc("/tenants/{id}", tenantHandler.DeleteTenant).Methods("DELETE")
+        admin.HandleFunc("/tenants/{id}/suspend", tenantHandler.SuspendTenant).Methods("POST")
+        admin.HandleFunc("/tenants/{id}/resume", tenantHandler.ResumeTenant).Methods("POST")
+    }
+    
+    // Registered last, so that callers are told apart by their key, user
+    // and tenant rather than by IP.
+    limiter := middleware.NewRateLimiter(
+        middleware.RateLimit{Requests: cfg.RateLimitReads, Window: cfg.RateLimitWindow},
+        middleware.RateLimit{Requests: cfg.RateLimitWrites, Window: cfg.RateLimitWindow},
+    )
+    router.Use(middleware.RateLimited(limiter, "/health"))
+    grpcOptions = append(grpcOptions, grpcserver.WithRateLimit(limiter)...)
     
     api := router.PathPrefix("/api/v1").Subrouter()
-    api.HandleFunc("/tasks", taskHandler.GetAllTasks).Methods("GET")
-    api.HandleFunc("/tasks", taskHandler.CreateTask).Methods("POST")
-    api.HandleFunc("/tasks/{id}", taskHandler.GetTaskByID).Methods("GET")
-    api.HandleFunc("/tasks/{id}", taskHandler.UpdateTask).Methods("PUT")
-    api.HandleFunc("/tasks/{id}", taskHandler.DeleteTask).Methods("DELETE")
+    api.Use(middleware.Idempotency(middleware.NewIdempotencyStore(cfg.IdempotencyTTL)))
+    api.Handle("/tasks", taskHandler).Methods("GET", "POST")
+    api.HandleFunc("/tasks.ics", feedHandler.Calendar).Methods("GET")
+    api.HandleFunc("/tasks/bulk", bulkHandler.Execute).Methods("POST")
+    api.HandleFunc("/tasks/search", searchHandler.Search).Methods("GET")
+    api.HandleFunc("/tasks/events", eventsHandler.Stream).Methods("GET")
+    api.HandleFunc("/tasks/export", transferHandler.Export).Methods("GET")
+    api.HandleFunc("/tasks/import", transferHandler.Import).Methods("POST")
+    api.Handle("/tasks/{id}", taskHandler).Methods("GET", "PUT", "PATCH", "DELETE")
+    api.HandleFunc("/filters", filterHandler.GetAllFilters).Methods("GET")
+    api.HandleFunc("/filters", filterHandler.CreateFilter).Methods("POST")
+    api.HandleFunc("/filters/{id}", filterHandler.GetFilterByID).Methods("GET")
+    api.HandleFunc("/filters/{id}", filterHandler.UpdateFilter).Methods("PUT")
+    api.HandleFunc("/filters/{id}", filterHandler.DeleteFilter).Methods("DELETE")
+    api.HandleFunc("/filters/{id}/tasks", filterHandler.GetFilterTasks).Methods("GET")
+    api.HandleFunc("/feeds", feedHandler.GetAllFeeds).Methods("GET")
+    api.HandleFunc("/feeds", feedHandler.CreateFeed).Methods("POST")
+    api.HandleFunc("/feeds/{id}", feedHandler.DeleteFeed).Methods("DELETE")
+    api.HandleFunc("/sync", syncHandler.GetChanges).Methods("GET")
+    api.HandleFunc("/sync", syncHandler.PushChanges).Methods("POST")
+    if verifier != nil {
+        api.HandleFunc("/shares", shareHandler.GetSharedWithMe).Methods("GET")
+        api.HandleFunc("/tasks/{id}/shares", shareHandler.GetTaskShares).Methods("GET")
+        api.HandleFunc("/tasks/{id}/shares", shareHandler.ShareTask).Methods("POST")
+        api.HandleFunc("/tasks/{id}/shares/{grantee}", shareHandler.UnshareTask).Methods("DELETE")
+        api.HandleFunc("/projects/{project}/shares", shareHandler.GetProjectShares).Methods("GET")
+        api.HandleFunc("/projects/{project}/shares", shareHandler.ShareProject).Methods("POST")
+        api.HandleFunc("/projects/{project}/shares/{grantee}", shareHandler.UnshareProject).Methods("DELETE")
+    }
     
     router.HandleFunc("/health", HealthCheck).Methods("GET")
+    router.Handle("/graphql", graphQLHandler).Methods("GET", "POST")
+    router.HandleFunc("/.well-known/caldav", caldav.WellKnown)
+    router.PathPrefix(caldav.Prefix).Handler(caldavHandler)
     
     router.PathPrefix("/swagger/").Handler(httpSwagger.Handler(
         httpSwagger.URL("/swagger/doc.json"),
@@ -165,23 +401,65 @@ func main() {
         http.Redirect(w, r, "/swagger/index.html", http.StatusMovedPermanently)
     })
     
-    router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-        http.Redirect(w, r, "/swagger/index.html", http.StatusSeeOther)
-    })
+    webHandler.Register(router)
+    
+    // These wrap the router instead of being registered on it: preflight
+    // requests match no route, and oversized bodies should be turned away
+    // before authentication. Imports and CalDAV limit their own bodies.
+    var h http.Handler = router
+    h = middleware.MaxBodySize(int64(cfg.MaxBodySize), "/api/v1/tasks/import", caldav.Prefix)(h)
+    h = middleware.CORS(corsConfig)(h)
+    h = middleware.SecurityHeaders(cfg.SecurityPolicy, "/swagger/")(h)
     
     addr := fmt.Sprintf(":%d", cfg.Port)
     server := &http.Server{
         Addr:         addr,
-        Handler:      router,
+        Handler:      h,
         ReadTimeout:  15 * time.Second,
         WriteTimeout: 15 * time.Second,
         IdleTimeout:  60 * time.Second,
     }
+    if certStore != nil {
+        server.TLSConfig = certStore.TLSConfig(clientAuth, "h2", "http/1.1")
+        grpcOptions = append(grpcOptions, grpc.Creds(credentials.NewTLS(certStore.TLSConfig(clientAuth, "h2"))))
+    }
     
-    fmt.Printf("🌐 Сервер запущен на http://localhost:%d\n", cfg.Port)
-    fmt.Printf("📚 Swagger UI: http://localhost:%d/swagger/index.html\n", cfg.Port)
-    fmt.Printf("📖 API документация: http://localhost:%d/docs\n", cfg.Port)
+    fmt.Printf("🌐 Сервер запущен на %s://localhost:%d\n", scheme, cfg.Port)
+    fmt.Printf("🖥  Веб-интерфейс: %s://localhost:%d/\n", scheme, cfg.Port)
+    fmt.Printf("📚 Swagger UI: %s://localhost:%d/swagger/index.html\n", scheme, cfg.Port)
+    fmt.Printf("📖 API документация: %s://localhost:%d/docs\n", scheme, cfg.Port)
+    fmt.Printf("🔗 GraphQL: %s://localhost:%d/graphql\n", scheme, cfg.Port)
+    fmt.Printf("📅 CalDAV: %s://localhost:%d/caldav/\n", scheme, cfg.Port)
+    fmt.Printf("📡 gRPC: localhost:%d\n", cfg.GRPCPort)
     fmt.Println("🛑 Для остановки нажмите Ctrl+C")
     
-    log.Fatal(server.ListenAndServe())
+    grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+    if err != nil {
+        log.Fatalf("Failed to listen for gRPC: %v", err)
+    }
+    grpcServer := grpcserver.Register(taskService, grpcOptions...)
+    reflection.Register(grpcServer)
+    go func() {
+        log.Fatal(grpcServer.Serve(grpcListener))
+    }()
+    
+    if certStore == nil {
+        log.Fatal(server.ListenAndServe())
+    }
+    
+    if cfg.RedirectPort != 0 {
+        fmt.Printf("↪️  Перенаправление на HTTPS: http://localhost:%d\n", cfg.RedirectPort)
+        redirect := &http.Server{
+            Addr:         fmt.Sprintf(":%d", cfg.RedirectPort),
+            Handler:      redirectToHTTPS(cfg.Port),
+            ReadTimeout:  5 * time.Second,
+            WriteTimeout: 5 * time.Second,
+        }
+        go func() {
+            log.Fatal(redirect.ListenAndServe())
+        }()
+    }
+    
+    // The certificate comes from the store, which reloads it on change.
+    log.Fatal(server.ListenAndServeTLS("", ""))
 }
\ No newline at end of file
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+
+	"tasks-crud/client"
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/service"
+)
+
+// backend is what the UI needs from either a remote server or an embedded
+// TaskService.
+type backend interface {
+    Name() string
+    List(ctx context.Context, query string) ([]domain.Task, error)
+    Create(ctx context.Context, title string) (*domain.Task, error)
+    Update(ctx context.Context, id int, req domain.UpdateTaskRequest) (*domain.Task, error)
+    Delete(ctx context.Context, id int) error
+    // Watch returns a stream of changes, or an error if the backend has none.
+    Watch(ctx context.Context) (<-chan domain.TaskEvent, error)
+}
+
+type remoteBackend struct {
+    server string
+    client *client.Client
+}
+
+func newRemoteBackend(server, token string) (*remoteBackend, error) {
+    opts := []client.Option{client.WithUserAgent("tasks-tui")}
+    if token != "" {
+        opts = append(opts, client.WithToken(token))
+    }
+    
+    c, err := client.New(server, opts...)
+    if err != nil {
+        return nil, err
+    }
+    
+    return &remoteBackend{server: server, client: c}, nil
+}
+
+func (b *remoteBackend) Name() string {
+    return b.server
+}
+
+func (b *remoteBackend) List(ctx context.Context, query string) ([]domain.Task, error) {
+    tasks := make([]domain.Task, 0)
+    for task, err := range b.client.AllTasks(ctx, client.ListOptions{Query: query, PageSize: 500}) {
+        if err != nil {
+            return nil, err
+        }
+        tasks = append(tasks, fromClientTask(task))
+    }
+    return tasks, nil
+}
+
+func (b *remoteBackend) Create(ctx context.Context, title string) (*domain.Task, error) {
+    task, err := b.client.CreateTask(ctx, client.CreateTaskRequest{Title: title})
+    if err != nil {
+        return nil, err
+    }
+    created := fromClientTask(*task)
+    return &created, nil
+}
+
+func (b *remoteBackend) Update(ctx context.Context, id int, req domain.UpdateTaskRequest) (*domain.Task, error) {
+    task, err := b.client.UpdateTask(ctx, id, client.UpdateTaskRequest{
+        Title:     req.Title,
+        Completed: req.Completed,
+        Priority:  req.Priority,
+        Tags:      req.Tags,
+        DueDate:   req.DueDate,
+        ParentID:  req.ParentID,
+    })
+    if err != nil {
+        return nil, err
+    }
+    updated := fromClientTask(*task)
+    return &updated, nil
+}
+
+func (b *remoteBackend) Delete(ctx context.Context, id int) error {
+    return b.client.DeleteTask(ctx, id)
+}
+
+func (b *remoteBackend) Watch(ctx context.Context) (<-chan domain.TaskEvent, error) {
+    events, err := b.client.Watch(ctx)
+    if err != nil {
+        return nil, err
+    }
+    
+    converted := make(chan domain.TaskEvent)
+    go func() {
+        defer close(converted)
+        for event := range events {
+            taskEvent := domain.TaskEvent{
+                Type:     event.Type,
+                TaskID:   event.TaskID,
+                Revision: event.Revision,
+                At:       event.At,
+            }
+            if event.Task != nil {
+                task := fromClientTask(*event.Task)
+                taskEvent.Task = &task
+            }
+            select {
+            case converted <- taskEvent:
+            case <-ctx.Done():
+                return
+            }
+        }
+    }()
+    
+    return converted, nil
+}
+
+func fromClientTask(task client.Task) domain.Task {
+    return domain.Task{
+        ID:          task.ID,
+        Title:       task.Title,
+        Completed:   task.Completed,
+        Priority:    task.Priority,
+        Tags:        task.Tags,
+        DueDate:     task.DueDate,
+        ParentID:    task.ParentID,
+        CreatedAt:   task.CreatedAt,
+        UpdatedAt:   task.UpdatedAt,
+        CompletedAt: task.CompletedAt,
+        Revision:    task.Revision,
+        OwnerID:     task.OwnerID,
+    }
+}
+
+type localBackend struct {
+    service *service.TaskService
+}
+
+func newLocalBackend(service *service.TaskService) *localBackend {
+    return &localBackend{service: service}
+}
+
+func (b *localBackend) Name() string {
+    return "embedded"
+}
+
+func (b *localBackend) List(ctx context.Context, query string) ([]domain.Task, error) {
+    return b.service.ListTasks(query)
+}
+
+func (b *localBackend) Create(ctx context.Context, title string) (*domain.Task, error) {
+    return b.service.CreateTask(domain.CreateTaskRequest{Title: title})
+}
+
+func (b *localBackend) Update(ctx context.Context, id int, req domain.UpdateTaskRequest) (*domain.Task, error) {
+    return b.service.UpdateTask(id, req)
+}
+
+func (b *localBackend) Delete(ctx context.Context, id int) error {
+    return b.service.DeleteTask(id)
+}
+
+func (b *localBackend) Watch(ctx context.Context) (<-chan domain.TaskEvent, error) {
+    return b.service.WatchTasks(ctx)
+}
@@ -0,0 +1,51 @@
+// Command tasks-tui is a keyboard-driven terminal UI for tasks. It talks to
+// a remote Tasks API or, with -embedded, to an in-memory TaskService.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"tasks-crud/internal/repository"
+	"tasks-crud/internal/service"
+)
+
+func main() {
+    server := flag.String("server", envOr("TASKSCTL_SERVER", "http://localhost:8080"), "API server URL")
+    token := flag.String("token", os.Getenv("TASKSCTL_TOKEN"), "bearer token")
+    embedded := flag.Bool("embedded", false, "use an in-memory task service instead of a server")
+    query := flag.String("q", "status:open", "initial filter in the task query language")
+    flag.Parse()
+    
+    var b backend
+    if *embedded {
+        b = newLocalBackend(service.NewTaskService(repository.NewInMemoryTaskRepository()))
+    } else {
+        remote, err := newRemoteBackend(*server, *token)
+        if err != nil {
+            fmt.Fprintln(os.Stderr, "tasks-tui:", err)
+            os.Exit(1)
+        }
+        b = remote
+    }
+    
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    
+    program := tea.NewProgram(newModel(ctx, b, *query), tea.WithAltScreen())
+    if _, err := program.Run(); err != nil {
+        fmt.Fprintln(os.Stderr, "tasks-tui:", err)
+        os.Exit(1)
+    }
+}
+
+func envOr(key, defaultValue string) string {
+    if value, ok := os.LookupEnv(key); ok {
+        return value
+    }
+    return defaultValue
+}
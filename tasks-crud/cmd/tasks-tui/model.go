@@ -0,0 +1,445 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tasks-crud/internal/domain"
+)
+
+const (
+    pollInterval      = 5 * time.Second
+    reconnectInterval = 5 * time.Second
+)
+
+type mode int
+
+const (
+    modeList mode = iota
+    modeFilter
+    modeEdit
+    modeCreate
+    modeConfirmDelete
+)
+
+var (
+    titleStyle    = lipgloss.NewStyle().Bold(true)
+    selectedStyle = lipgloss.NewStyle().Reverse(true)
+    doneStyle     = lipgloss.NewStyle().Faint(true).Strikethrough(true)
+    dimStyle      = lipgloss.NewStyle().Faint(true)
+    errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+    priorityMarks = []string{" ", "·", "!", "‼"}
+)
+
+type (
+    tasksLoadedMsg struct {
+        tasks []domain.Task
+        err   error
+    }
+    taskSavedMsg struct {
+        action string
+        err    error
+    }
+    watchStartedMsg struct {
+        events <-chan domain.TaskEvent
+        err    error
+    }
+    taskEventMsg struct {
+        event domain.TaskEvent
+        ok    bool
+    }
+    pollMsg      struct{}
+    reconnectMsg struct{}
+)
+
+type model struct {
+    ctx     context.Context
+    backend backend
+    
+    query   string
+    tasks   []domain.Task
+    cursor  int
+    offset  int
+    width   int
+    height  int
+    mode    mode
+    input   textinput.Model
+    
+    events  <-chan domain.TaskEvent
+    live    bool
+    status  string
+    err     error
+    loading bool
+}
+
+func newModel(ctx context.Context, b backend, query string) model {
+    input := textinput.New()
+    input.Prompt = "> "
+    input.CharLimit = 200
+    
+    return model{
+        ctx:     ctx,
+        backend: b,
+        query:   query,
+        input:   input,
+        loading: true,
+    }
+}
+
+func (m model) Init() tea.Cmd {
+    return tea.Batch(m.load(), m.watch())
+}
+
+func (m model) load() tea.Cmd {
+    return func() tea.Msg {
+        tasks, err := m.backend.List(m.ctx, m.query)
+        return tasksLoadedMsg{tasks: tasks, err: err}
+    }
+}
+
+func (m model) watch() tea.Cmd {
+    return func() tea.Msg {
+        events, err := m.backend.Watch(m.ctx)
+        return watchStartedMsg{events: events, err: err}
+    }
+}
+
+func waitForEvent(events <-chan domain.TaskEvent) tea.Cmd {
+    return func() tea.Msg {
+        event, ok := <-events
+        return taskEventMsg{event: event, ok: ok}
+    }
+}
+
+func (m model) save(action string, fn func() error) tea.Cmd {
+    return func() tea.Msg {
+        return taskSavedMsg{action: action, err: fn()}
+    }
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+    switch msg := msg.(type) {
+    case tea.WindowSizeMsg:
+        m.width, m.height = msg.Width, msg.Height
+        m.input.Width = max(msg.Width-4, 10)
+        m.scroll()
+        return m, nil
+        
+    case tasksLoadedMsg:
+        m.loading = false
+        if msg.err != nil {
+            m.err = msg.err
+            return m, nil
+        }
+        m.err = nil
+        m.setTasks(msg.tasks)
+        return m, nil
+        
+    case taskSavedMsg:
+        if msg.err != nil {
+            m.err = msg.err
+            return m, nil
+        }
+        m.err = nil
+        m.status = msg.action
+        // With a live stream the change event triggers the reload.
+        if m.live {
+            return m, nil
+        }
+        return m, m.load()
+        
+    case watchStartedMsg:
+        if msg.err != nil {
+            m.live = false
+            m.status = "live updates unavailable, polling"
+            return m, tea.Tick(pollInterval, func(time.Time) tea.Msg { return pollMsg{} })
+        }
+        m.events = msg.events
+        m.live = true
+        return m, tea.Batch(waitForEvent(m.events), m.load())
+        
+    case taskEventMsg:
+        if !msg.ok {
+            m.live = false
+            if m.ctx.Err() != nil {
+                return m, nil
+            }
+            m.status = "change stream closed, reconnecting"
+            return m, tea.Tick(reconnectInterval, func(time.Time) tea.Msg { return reconnectMsg{} })
+        }
+        return m, tea.Batch(waitForEvent(m.events), m.load())
+        
+    case pollMsg:
+        if m.live {
+            return m, nil
+        }
+        return m, tea.Batch(m.load(), tea.Tick(pollInterval, func(time.Time) tea.Msg { return pollMsg{} }))
+        
+    case reconnectMsg:
+        return m, m.watch()
+        
+    case tea.KeyMsg:
+        if m.mode == modeList {
+            return m.updateList(msg)
+        }
+        return m.updateInput(msg)
+    }
+    
+    return m, nil
+}
+
+func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+    m.status = ""
+    
+    switch msg.String() {
+    case "ctrl+c", "q":
+        return m, tea.Quit
+    case "up", "k":
+        m.moveCursor(-1)
+    case "down", "j":
+        m.moveCursor(1)
+    case "pgup":
+        m.moveCursor(-m.listHeight())
+    case "pgdown":
+        m.moveCursor(m.listHeight())
+    case "home", "g":
+        m.moveCursor(-len(m.tasks))
+    case "end", "G":
+        m.moveCursor(len(m.tasks))
+    case "r":
+        m.loading = true
+        return m, m.load()
+    case " ", "x":
+        task, ok := m.selected()
+        if !ok {
+            return m, nil
+        }
+        completed := !task.Completed
+        return m, m.save(fmt.Sprintf("task %d updated", task.ID), func() error {
+            _, err := m.backend.Update(m.ctx, task.ID, domain.UpdateTaskRequest{Completed: &completed})
+            return err
+        })
+    case "e", "enter":
+        task, ok := m.selected()
+        if !ok {
+            return m, nil
+        }
+        return m.startInput(modeEdit, task.Title)
+    case "n":
+        return m.startInput(modeCreate, "")
+    case "/":
+        return m.startInput(modeFilter, m.query)
+    case "d":
+        if _, ok := m.selected(); ok {
+            m.mode = modeConfirmDelete
+        }
+    }
+    
+    return m, nil
+}
+
+func (m model) startInput(next mode, value string) (tea.Model, tea.Cmd) {
+    m.mode = next
+    m.err = nil
+    m.input.SetValue(value)
+    m.input.CursorEnd()
+    return m, m.input.Focus()
+}
+
+func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+    if m.mode == modeConfirmDelete {
+        m.mode = modeList
+        task, ok := m.selected()
+        if !ok || (msg.String() != "y" && msg.String() != "Y") {
+            return m, nil
+        }
+        return m, m.save(fmt.Sprintf("task %d deleted", task.ID), func() error {
+            return m.backend.Delete(m.ctx, task.ID)
+        })
+    }
+    
+    switch msg.String() {
+    case "ctrl+c":
+        return m, tea.Quit
+    case "esc":
+        m.mode = modeList
+        m.input.Blur()
+        return m, nil
+    case "enter":
+        value := strings.TrimSpace(m.input.Value())
+        current := m.mode
+        m.mode = modeList
+        m.input.Blur()
+        
+        switch current {
+        case modeFilter:
+            m.query = value
+            m.cursor, m.offset = 0, 0
+            m.loading = true
+            return m, m.load()
+        case modeCreate:
+            if value == "" {
+                return m, nil
+            }
+            return m, m.save("task created", func() error {
+                _, err := m.backend.Create(m.ctx, value)
+                return err
+            })
+        case modeEdit:
+            task, ok := m.selected()
+            if !ok || value == task.Title {
+                return m, nil
+            }
+            return m, m.save(fmt.Sprintf("task %d renamed", task.ID), func() error {
+                _, err := m.backend.Update(m.ctx, task.ID, domain.UpdateTaskRequest{Title: &value})
+                return err
+            })
+        }
+        return m, nil
+    }
+    
+    var cmd tea.Cmd
+    m.input, cmd = m.input.Update(msg)
+    return m, cmd
+}
+
+// setTasks replaces the list, keeping the cursor on the same task if it is
+// still there.
+func (m *model) setTasks(tasks []domain.Task) {
+    selectedID := 0
+    if task, ok := m.selected(); ok {
+        selectedID = task.ID
+    }
+    
+    m.tasks = tasks
+    for i, task := range tasks {
+        if task.ID == selectedID {
+            m.cursor = i
+            break
+        }
+    }
+    m.moveCursor(0)
+}
+
+func (m model) selected() (domain.Task, bool) {
+    if m.cursor < 0 || m.cursor >= len(m.tasks) {
+        return domain.Task{}, false
+    }
+    return m.tasks[m.cursor], true
+}
+
+func (m *model) moveCursor(delta int) {
+    m.cursor = max(min(m.cursor+delta, len(m.tasks)-1), 0)
+    m.scroll()
+}
+
+func (m *model) scroll() {
+    height := m.listHeight()
+    if m.cursor < m.offset {
+        m.offset = m.cursor
+    }
+    if m.cursor >= m.offset+height {
+        m.offset = m.cursor - height + 1
+    }
+    m.offset = max(min(m.offset, len(m.tasks)-height), 0)
+}
+
+// listHeight is the number of rows left for tasks after the header and footer.
+func (m model) listHeight() int {
+    if m.height == 0 {
+        return 20
+    }
+    return max(m.height-5, 1)
+}
+
+func (m model) View() string {
+    var b strings.Builder
+    
+    live := dimStyle.Render("polling")
+    if m.live {
+        live = "live"
+    }
+    query := m.query
+    if query == "" {
+        query = dimStyle.Render("(all tasks)")
+    }
+    fmt.Fprintf(&b, "%s  %s  %s\n", titleStyle.Render("Tasks"), dimStyle.Render(m.backend.Name()), live)
+    fmt.Fprintf(&b, "filter: %s\n\n", query)
+    
+    if len(m.tasks) == 0 {
+        if m.loading {
+            b.WriteString(dimStyle.Render("loading...") + "\n")
+        } else {
+            b.WriteString(dimStyle.Render("no tasks") + "\n")
+        }
+    }
+    
+    end := min(m.offset+m.listHeight(), len(m.tasks))
+    for i := m.offset; i < end; i++ {
+        line := m.renderTask(m.tasks[i])
+        if i == m.cursor {
+            line = selectedStyle.Render(line)
+        }
+        b.WriteString(line + "\n")
+    }
+    
+    b.WriteString("\n" + m.footer())
+    return b.String()
+}
+
+func (m model) renderTask(task domain.Task) string {
+    check := "[ ]"
+    if task.Completed {
+        check = "[x]"
+    }
+    mark := " "
+    if task.Priority >= 0 && task.Priority < len(priorityMarks) {
+        mark = priorityMarks[task.Priority]
+    }
+    
+    title := task.Title
+    if task.Completed {
+        title = doneStyle.Render(title)
+    }
+    
+    var extra []string
+    if task.DueDate != nil {
+        extra = append(extra, "due "+task.DueDate.Local().Format("2006-01-02"))
+    }
+    for _, tag := range task.Tags {
+        extra = append(extra, "#"+tag)
+    }
+    
+    line := fmt.Sprintf("%s %s %4d  %s", check, mark, task.ID, title)
+    if len(extra) > 0 {
+        line += "  " + dimStyle.Render(strings.Join(extra, " "))
+    }
+    return line
+}
+
+func (m model) footer() string {
+    switch m.mode {
+    case modeFilter:
+        return "filter (enter to apply, esc to cancel)\n" + m.input.View()
+    case modeCreate:
+        return "new task title (enter to save, esc to cancel)\n" + m.input.View()
+    case modeEdit:
+        return "edit title (enter to save, esc to cancel)\n" + m.input.View()
+    case modeConfirmDelete:
+        task, _ := m.selected()
+        return fmt.Sprintf("delete task %d %q? (y/n)", task.ID, task.Title)
+    }
+    
+    status := m.status
+    if m.err != nil {
+        status = errorStyle.Render(m.err.Error())
+    }
+    help := dimStyle.Render("j/k move  space toggle  e edit  n new  d delete  / filter  r refresh  q quit")
+    return status + "\n" + help
+}
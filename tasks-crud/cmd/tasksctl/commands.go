@@ -0,0 +1,386 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"tasks-crud/client"
+)
+
+func (a *app) newAddCommand() *cobra.Command {
+    var (
+        priority string
+        tags     []string
+        due      string
+        parent   int
+    )
+    
+    cmd := &cobra.Command{
+        Use:   "add TITLE...",
+        Short: "Create a task",
+        Args:  cobra.MinimumNArgs(1),
+        RunE: func(cmd *cobra.Command, args []string) error {
+            c, err := a.api()
+            if err != nil {
+                return err
+            }
+            
+            req := client.CreateTaskRequest{
+                Title: strings.Join(args, " "),
+                Tags:  tags,
+            }
+            if priority != "" {
+                if req.Priority, err = parsePriority(priority); err != nil {
+                    return err
+                }
+            }
+            if due != "" {
+                dueDate, err := parseDue(due)
+                if err != nil {
+                    return err
+                }
+                req.DueDate = &dueDate
+            }
+            if parent > 0 {
+                req.ParentID = &parent
+            }
+            
+            task, err := c.CreateTask(cmd.Context(), req)
+            if err != nil {
+                return err
+            }
+            return printTask(cmd.OutOrStdout(), a.cfg.Output, task)
+        },
+    }
+    
+    cmd.Flags().StringVarP(&priority, "priority", "p", "", "priority: none, low, medium, high")
+    cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "tag (repeatable or comma-separated)")
+    cmd.Flags().StringVar(&due, "due", "", "due date: YYYY-MM-DD or RFC 3339")
+    cmd.Flags().IntVar(&parent, "parent", 0, "parent task ID")
+    cmd.RegisterFlagCompletionFunc("priority", cobra.FixedCompletions(priorityNames, cobra.ShellCompDirectiveNoFileComp))
+    cmd.RegisterFlagCompletionFunc("parent", a.completeTaskIDs)
+    
+    return cmd
+}
+
+func (a *app) newListCommand() *cobra.Command {
+    var (
+        status   string
+        tags     []string
+        all      bool
+        pageSize int
+    )
+    
+    cmd := &cobra.Command{
+        Use:     "ls [QUERY...]",
+        Aliases: []string{"list"},
+        Short:   "List tasks",
+        Long: `List tasks, optionally filtered with the task query language, e.g.
+
+  tasksctl ls tag:backend due<7d sort:priority
+
+Only open tasks are listed unless --all or --status is given.`,
+        RunE: func(cmd *cobra.Command, args []string) error {
+            c, err := a.api()
+            if err != nil {
+                return err
+            }
+            
+            opts := client.ListOptions{
+                Query:    strings.Join(args, " "),
+                Status:   status,
+                Tags:     tags,
+                PageSize: pageSize,
+            }
+            if opts.Status == "" && !all {
+                opts.Status = "open"
+            }
+            
+            tasks := make([]client.Task, 0)
+            for task, err := range c.AllTasks(cmd.Context(), opts) {
+                if err != nil {
+                    return err
+                }
+                tasks = append(tasks, task)
+            }
+            return printTasks(cmd.OutOrStdout(), a.cfg.Output, tasks)
+        },
+    }
+    
+    cmd.Flags().StringVarP(&status, "status", "s", "", "status: open, done, overdue or all")
+    cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "only tasks with any of these tags")
+    cmd.Flags().BoolVarP(&all, "all", "a", false, "include completed tasks")
+    cmd.Flags().IntVar(&pageSize, "page-size", 100, "tasks fetched per request")
+    cmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions([]string{"open", "done", "overdue", "all"}, cobra.ShellCompDirectiveNoFileComp))
+    
+    return cmd
+}
+
+func (a *app) newDoneCommand() *cobra.Command {
+    var undo bool
+    
+    cmd := &cobra.Command{
+        Use:               "done ID...",
+        Short:             "Mark tasks as completed",
+        Args:              cobra.MinimumNArgs(1),
+        ValidArgsFunction: a.completeTaskIDs,
+        RunE: func(cmd *cobra.Command, args []string) error {
+            ids, err := parseIDs(args)
+            if err != nil {
+                return err
+            }
+            c, err := a.api()
+            if err != nil {
+                return err
+            }
+            
+            completed := !undo
+            tasks := make([]client.Task, 0, len(ids))
+            for _, id := range ids {
+                task, err := c.UpdateTask(cmd.Context(), id, client.UpdateTaskRequest{Completed: &completed})
+                if err != nil {
+                    return fmt.Errorf("task %d: %w", id, err)
+                }
+                tasks = append(tasks, *task)
+            }
+            return printTasks(cmd.OutOrStdout(), a.cfg.Output, tasks)
+        },
+    }
+    
+    cmd.Flags().BoolVar(&undo, "undo", false, "mark tasks as not completed")
+    
+    return cmd
+}
+
+func (a *app) newEditCommand() *cobra.Command {
+    var (
+        title    string
+        priority string
+        tags     []string
+        due      string
+        parent   int
+    )
+    
+    cmd := &cobra.Command{
+        Use:               "edit ID",
+        Short:             "Change fields of a task",
+        Args:              cobra.ExactArgs(1),
+        ValidArgsFunction: a.completeTaskIDs,
+        RunE: func(cmd *cobra.Command, args []string) error {
+            ids, err := parseIDs(args)
+            if err != nil {
+                return err
+            }
+            c, err := a.api()
+            if err != nil {
+                return err
+            }
+            
+            flags := cmd.Flags()
+            if !flags.Changed("title") && !flags.Changed("priority") && !flags.Changed("tag") && !flags.Changed("due") && !flags.Changed("parent") {
+                return fmt.Errorf("nothing to change: pass --title, --priority, --tag, --due or --parent")
+            }
+            
+            var req client.UpdateTaskRequest
+            if flags.Changed("title") {
+                req.Title = &title
+            }
+            if flags.Changed("priority") {
+                value, err := parsePriority(priority)
+                if err != nil {
+                    return err
+                }
+                req.Priority = &value
+            }
+            if flags.Changed("tag") {
+                req.Tags = append([]string{}, tags...)
+            }
+            if flags.Changed("due") {
+                dueDate, err := parseDue(due)
+                if err != nil {
+                    return err
+                }
+                req.DueDate = &dueDate
+            }
+            if flags.Changed("parent") {
+                req.ParentID = &parent
+            }
+            
+            task, err := c.UpdateTask(cmd.Context(), ids[0], req)
+            if err != nil {
+                return err
+            }
+            return printTask(cmd.OutOrStdout(), a.cfg.Output, task)
+        },
+    }
+    
+    cmd.Flags().StringVar(&title, "title", "", "new title")
+    cmd.Flags().StringVarP(&priority, "priority", "p", "", "priority: none, low, medium, high")
+    cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "replace tags (pass --tag= to clear)")
+    cmd.Flags().StringVar(&due, "due", "", "due date: YYYY-MM-DD or RFC 3339")
+    cmd.Flags().IntVar(&parent, "parent", 0, "parent task ID, 0 to detach")
+    cmd.RegisterFlagCompletionFunc("priority", cobra.FixedCompletions(priorityNames, cobra.ShellCompDirectiveNoFileComp))
+    cmd.RegisterFlagCompletionFunc("parent", a.completeTaskIDs)
+    
+    return cmd
+}
+
+func (a *app) newRemoveCommand() *cobra.Command {
+    return &cobra.Command{
+        Use:               "rm ID...",
+        Aliases:           []string{"delete"},
+        Short:             "Delete tasks",
+        Args:              cobra.MinimumNArgs(1),
+        ValidArgsFunction: a.completeTaskIDs,
+        RunE: func(cmd *cobra.Command, args []string) error {
+            ids, err := parseIDs(args)
+            if err != nil {
+                return err
+            }
+            c, err := a.api()
+            if err != nil {
+                return err
+            }
+            
+            for _, id := range ids {
+                if err := c.DeleteTask(cmd.Context(), id); err != nil {
+                    return fmt.Errorf("task %d: %w", id, err)
+                }
+                fmt.Fprintf(cmd.ErrOrStderr(), "deleted task %d\n", id)
+            }
+            return nil
+        },
+    }
+}
+
+func (a *app) newShowCommand() *cobra.Command {
+    return &cobra.Command{
+        Use:               "show ID",
+        Short:             "Show a task",
+        Args:              cobra.ExactArgs(1),
+        ValidArgsFunction: a.completeTaskIDs,
+        RunE: func(cmd *cobra.Command, args []string) error {
+            ids, err := parseIDs(args)
+            if err != nil {
+                return err
+            }
+            c, err := a.api()
+            if err != nil {
+                return err
+            }
+            
+            task, err := c.GetTask(cmd.Context(), ids[0])
+            if err != nil {
+                return err
+            }
+            return printTask(cmd.OutOrStdout(), a.cfg.Output, task)
+        },
+    }
+}
+
+func (a *app) newConfigCommand() *cobra.Command {
+    cmd := &cobra.Command{
+        Use:   "config",
+        Short: "View or change the config file",
+    }
+    
+    cmd.AddCommand(&cobra.Command{
+        Use:   "view",
+        Short: "Print the effective configuration",
+        Args:  cobra.NoArgs,
+        RunE: func(cmd *cobra.Command, args []string) error {
+            if err := a.load(); err != nil {
+                return err
+            }
+            cfg := *a.cfg
+            if cfg.Token != "" {
+                cfg.Token = "********"
+            }
+            fmt.Fprintf(cmd.OutOrStdout(), "# %s\n", a.configPath)
+            return printYAML(cmd.OutOrStdout(), map[string]string{
+                "server": cfg.Server,
+                "token":  cfg.Token,
+                "output": cfg.Output,
+            })
+        },
+    })
+    
+    cmd.AddCommand(&cobra.Command{
+        Use:       "set KEY VALUE",
+        Short:     "Set server, token or output in the config file",
+        Args:      cobra.ExactArgs(2),
+        ValidArgs: []string{"server", "token", "output"},
+        RunE: func(cmd *cobra.Command, args []string) error {
+            cfg, err := loadConfig(a.configPath)
+            if err != nil {
+                return err
+            }
+            
+            switch args[0] {
+            case "server":
+                if _, err := client.New(args[1]); err != nil {
+                    return err
+                }
+                cfg.Server = args[1]
+            case "token":
+                cfg.Token = args[1]
+            case "output":
+                cfg.Output = args[1]
+            default:
+                return fmt.Errorf("unknown key '%s' (expected server, token or output)", args[0])
+            }
+            
+            return saveConfig(a.configPath, cfg)
+        },
+    })
+    
+    return cmd
+}
+
+// completeTaskIDs suggests IDs of open tasks, with titles as descriptions.
+func (a *app) completeTaskIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+    c, err := a.api()
+    if err != nil {
+        return nil, cobra.ShellCompDirectiveNoFileComp
+    }
+    
+    page, err := c.ListTasks(cmd.Context(), client.ListOptions{Status: "open", PageSize: 200})
+    if err != nil {
+        return nil, cobra.ShellCompDirectiveNoFileComp
+    }
+    
+    completions := make([]string, 0, len(page.Tasks))
+    for _, task := range page.Tasks {
+        id := strconv.Itoa(task.ID)
+        if strings.HasPrefix(id, toComplete) {
+            completions = append(completions, id+"\t"+task.Title)
+        }
+    }
+    return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func parseIDs(args []string) ([]int, error) {
+    ids := make([]int, 0, len(args))
+    for _, arg := range args {
+        id, err := strconv.Atoi(arg)
+        if err != nil || id <= 0 {
+            return nil, fmt.Errorf("invalid task ID '%s'", arg)
+        }
+        ids = append(ids, id)
+    }
+    return ids, nil
+}
+
+func parseDue(value string) (time.Time, error) {
+    if due, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+        return due, nil
+    }
+    if due, err := time.Parse(time.RFC3339, value); err == nil {
+        return due, nil
+    }
+    return time.Time{}, fmt.Errorf("invalid due date '%s' (expected YYYY-MM-DD or RFC 3339)", value)
+}
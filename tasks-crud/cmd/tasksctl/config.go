@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultServer = "http://localhost:8080"
+
+// Config is stored in $XDG_CONFIG_HOME/tasksctl/config.yaml (or the path
+// given by --config / TASKSCTL_CONFIG).
+type Config struct {
+    Server string `yaml:"server"`
+    Token  string `yaml:"token,omitempty"`
+    Output string `yaml:"output,omitempty"`
+}
+
+func defaultConfigPath() string {
+    if path := os.Getenv("TASKSCTL_CONFIG"); path != "" {
+        return path
+    }
+    
+    dir, err := os.UserConfigDir()
+    if err != nil {
+        return "tasksctl.yaml"
+    }
+    return filepath.Join(dir, "tasksctl", "config.yaml")
+}
+
+// loadConfig reads the config file, if any, and applies environment overrides.
+func loadConfig(path string) (*Config, error) {
+    cfg := &Config{Server: defaultServer, Output: "table"}
+    
+    data, err := os.ReadFile(path)
+    switch {
+    case err == nil:
+        if err := yaml.Unmarshal(data, cfg); err != nil {
+            return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+        }
+    case !os.IsNotExist(err):
+        return nil, fmt.Errorf("failed to read config file: %w", err)
+    }
+    
+    if server := os.Getenv("TASKSCTL_SERVER"); server != "" {
+        cfg.Server = server
+    }
+    if token := os.Getenv("TASKSCTL_TOKEN"); token != "" {
+        cfg.Token = token
+    }
+    
+    return cfg, nil
+}
+
+func saveConfig(path string, cfg *Config) error {
+    data, err := yaml.Marshal(cfg)
+    if err != nil {
+        return fmt.Errorf("failed to encode config: %w", err)
+    }
+    
+    if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+        return fmt.Errorf("failed to create config directory: %w", err)
+    }
+    
+    // The file may contain a token, so keep it private.
+    if err := os.WriteFile(path, data, 0o600); err != nil {
+        return fmt.Errorf("failed to write config file: %w", err)
+    }
+    
+    return nil
+}
@@ -0,0 +1,101 @@
+// Command tasksctl manages tasks from the terminal through the Tasks API.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"tasks-crud/client"
+)
+
+type app struct {
+    configPath string
+    server     string
+    token      string
+    output     string
+    
+    cfg    *Config
+    client *client.Client
+}
+
+func main() {
+    if err := newRootCommand().Execute(); err != nil {
+        os.Exit(1)
+    }
+}
+
+func newRootCommand() *cobra.Command {
+    a := &app{}
+    
+    root := &cobra.Command{
+        Use:           "tasksctl",
+        Short:         "Manage tasks from the command line",
+        SilenceUsage:  true,
+        SilenceErrors: false,
+    }
+    root.PersistentFlags().StringVar(&a.configPath, "config", defaultConfigPath(), "config file")
+    root.PersistentFlags().StringVar(&a.server, "server", "", "API server URL (overrides the config file)")
+    root.PersistentFlags().StringVar(&a.token, "token", "", "bearer token (overrides the config file)")
+    root.PersistentFlags().StringVarP(&a.output, "output", "o", "", "output format: table, json or yaml")
+    root.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"table", "json", "yaml"}, cobra.ShellCompDirectiveNoFileComp))
+    
+    root.AddCommand(
+        a.newAddCommand(),
+        a.newListCommand(),
+        a.newDoneCommand(),
+        a.newEditCommand(),
+        a.newRemoveCommand(),
+        a.newShowCommand(),
+        a.newConfigCommand(),
+    )
+    
+    return root
+}
+
+// load reads the config file and applies command-line overrides.
+func (a *app) load() error {
+    if a.cfg != nil {
+        return nil
+    }
+    
+    cfg, err := loadConfig(a.configPath)
+    if err != nil {
+        return err
+    }
+    if a.server != "" {
+        cfg.Server = a.server
+    }
+    if a.token != "" {
+        cfg.Token = a.token
+    }
+    if a.output != "" {
+        cfg.Output = a.output
+    }
+    
+    a.cfg = cfg
+    return nil
+}
+
+func (a *app) api() (*client.Client, error) {
+    if a.client != nil {
+        return a.client, nil
+    }
+    if err := a.load(); err != nil {
+        return nil, err
+    }
+    
+    opts := []client.Option{client.WithUserAgent("tasksctl")}
+    if a.cfg.Token != "" {
+        opts = append(opts, client.WithToken(a.cfg.Token))
+    }
+    
+    c, err := client.New(a.cfg.Server, opts...)
+    if err != nil {
+        return nil, fmt.Errorf("invalid server in %s: %w", a.configPath, err)
+    }
+    
+    a.client = c
+    return c, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"tasks-crud/client"
+)
+
+var priorityNames = []string{"none", "low", "medium", "high"}
+
+func printTasks(w io.Writer, format string, tasks []client.Task) error {
+    switch format {
+    case "json":
+        return printJSON(w, tasks)
+    case "yaml":
+        return printYAML(w, tasks)
+    case "table", "":
+        tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+        fmt.Fprintln(tw, "ID\tDONE\tPRIORITY\tDUE\tTAGS\tTITLE")
+        for _, task := range tasks {
+            fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+                task.ID,
+                doneMark(task.Completed),
+                priorityName(task.Priority),
+                formatDue(task),
+                strings.Join(task.Tags, ","),
+                task.Title,
+            )
+        }
+        return tw.Flush()
+    default:
+        return fmt.Errorf("unknown output format '%s' (expected table, json or yaml)", format)
+    }
+}
+
+func printTask(w io.Writer, format string, task *client.Task) error {
+    switch format {
+    case "json":
+        return printJSON(w, task)
+    case "yaml":
+        return printYAML(w, task)
+    case "table", "":
+        tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+        fmt.Fprintf(tw, "ID:\t%d\n", task.ID)
+        fmt.Fprintf(tw, "Title:\t%s\n", task.Title)
+        fmt.Fprintf(tw, "Done:\t%s\n", doneMark(task.Completed))
+        fmt.Fprintf(tw, "Priority:\t%s\n", priorityName(task.Priority))
+        fmt.Fprintf(tw, "Tags:\t%s\n", strings.Join(task.Tags, ", "))
+        fmt.Fprintf(tw, "Due:\t%s\n", formatDue(*task))
+        if task.ParentID != nil {
+            fmt.Fprintf(tw, "Parent:\t%d\n", *task.ParentID)
+        }
+        fmt.Fprintf(tw, "Created:\t%s\n", task.CreatedAt.Local().Format("2006-01-02 15:04"))
+        fmt.Fprintf(tw, "Updated:\t%s\n", task.UpdatedAt.Local().Format("2006-01-02 15:04"))
+        fmt.Fprintf(tw, "Revision:\t%d\n", task.Revision)
+        return tw.Flush()
+    default:
+        return fmt.Errorf("unknown output format '%s' (expected table, json or yaml)", format)
+    }
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+    encoder := json.NewEncoder(w)
+    encoder.SetIndent("", "  ")
+    return encoder.Encode(v)
+}
+
+// printYAML goes through JSON so that YAML keys match the API field names.
+func printYAML(w io.Writer, v interface{}) error {
+    data, err := json.Marshal(v)
+    if err != nil {
+        return err
+    }
+    
+    var generic interface{}
+    if err := json.Unmarshal(data, &generic); err != nil {
+        return err
+    }
+    
+    encoder := yaml.NewEncoder(w)
+    encoder.SetIndent(2)
+    defer encoder.Close()
+    return encoder.Encode(generic)
+}
+
+func doneMark(completed bool) string {
+    if completed {
+        return "x"
+    }
+    return ""
+}
+
+func formatDue(task client.Task) string {
+    if task.DueDate == nil {
+        return ""
+    }
+    return task.DueDate.Local().Format("2006-01-02")
+}
+
+func priorityName(priority int) string {
+    if priority >= 0 && priority < len(priorityNames) {
+        return priorityNames[priority]
+    }
+    return strconv.Itoa(priority)
+}
+
+func parsePriority(value string) (int, error) {
+    for i, name := range priorityNames {
+        if strings.EqualFold(value, name) {
+            return i, nil
+        }
+    }
+    
+    priority, err := strconv.Atoi(value)
+    if err != nil || priority < 0 || priority >= len(priorityNames) {
+        return 0, fmt.Errorf("invalid priority '%s' (expected none, low, medium, high or 0-3)", value)
+    }
+    return priority, nil
+}
@@ -116,6 +116,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Квота задач исчерпана (code: QUOTA_EXCEEDED)",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -278,6 +284,10 @@ const docTemplate = `{
             "description": "Структура ошибки API",
             "type": "object",
             "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "QUOTA_EXCEEDED"
+                },
                 "details": {
                     "type": "string",
                     "example": "database connection failed"
@@ -0,0 +1,490 @@
+// Package caldav serves tasks over CalDAV (RFC 4791) so that standard
+// to-do clients can read and edit them. Every project becomes a calendar
+// collection and every task a VTODO resource inside it:
+//
+//	/caldav/                       principal and calendar home
+//	/caldav/{project}/             calendar collection
+//	/caldav/{project}/{name}.ics   task
+//
+// A task's project is its first tag; tasks without tags live in the
+// "inbox" collection. All reads and writes go through TaskService, so
+// changes are visible in the REST API immediately.
+package caldav
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/ical"
+	"tasks-crud/internal/repository"
+	"tasks-crud/internal/service"
+	"tasks-crud/internal/transfer"
+)
+
+const (
+    Prefix          = "/caldav/"
+    InboxCollection = "inbox"
+    maxResourceSize = 1 << 20
+    calendarType    = "text/calendar; charset=utf-8"
+)
+
+var (
+    errNameTaken          = errors.New("resource name is taken")
+    errPreconditionFailed = errors.New("precondition failed")
+)
+
+type Handler struct {
+    service   *service.TaskService
+    resources *resourceNames
+    scopes    *scopedResources
+}
+
+func NewHandler(service *service.TaskService) *Handler {
+    return &Handler{
+        service: service,
+        scopes:  newScopedResources(),
+    }
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("DAV", "1, 3, calendar-access")
+    
+    // Each caller sees a calendar home with their own tasks and resource
+    // names only.
+    scoped := h.service.WithContext(r.Context())
+    resources := h.scopes.get(repository.TenantDataFromContext(r.Context()), scoped.Owner())
+    h = &Handler{service: scoped, resources: resources, scopes: h.scopes}
+    
+    collection, name, ok := splitPath(r.URL.Path)
+    if !ok {
+        http.NotFound(w, r)
+        return
+    }
+    
+    switch r.Method {
+    case http.MethodOptions:
+        w.Header().Set("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+        w.WriteHeader(http.StatusOK)
+    case "PROPFIND":
+        h.propfind(w, r, collection, name)
+    case "REPORT":
+        h.report(w, r, collection)
+    case http.MethodGet, http.MethodHead:
+        h.get(w, r, collection, name)
+    case http.MethodPut:
+        h.put(w, r, collection, name)
+    case http.MethodDelete:
+        h.delete(w, r, collection, name)
+    default:
+        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+    }
+}
+
+// ForgetTenant drops the resource names kept for a deleted tenant; see
+// service.TenantService.WithCleanup.
+func (h *Handler) ForgetTenant(data *repository.TenantData) {
+    h.scopes.forgetTenant(data)
+}
+
+// WellKnown redirects /.well-known/caldav to the calendar home (RFC 6764).
+func WellKnown(w http.ResponseWriter, r *http.Request) {
+    http.Redirect(w, r, Prefix, http.StatusMovedPermanently)
+}
+
+// Collection returns the name of the collection task belongs to.
+func Collection(task domain.Task) string {
+    if len(task.Tags) == 0 {
+        return InboxCollection
+    }
+    return task.Tags[0]
+}
+
+// splitPath splits /caldav/{collection}/{name} into its parts; both are
+// empty for the home and name is empty for a collection.
+func splitPath(path string) (string, string, bool) {
+    rest, found := strings.CutPrefix(path, Prefix)
+    if !found {
+        return "", "", path+"/" == Prefix
+    }
+    
+    rest = strings.TrimSuffix(rest, "/")
+    if rest == "" {
+        return "", "", true
+    }
+    
+    collection, name, _ := strings.Cut(rest, "/")
+    if strings.Contains(name, "/") {
+        return "", "", false
+    }
+    
+    collection, err := url.PathUnescape(collection)
+    if err != nil {
+        return "", "", false
+    }
+    name, err = url.PathUnescape(name)
+    if err != nil {
+        return "", "", false
+    }
+    
+    return strings.ToLower(collection), name, true
+}
+
+func collectionHref(collection string) string {
+    return Prefix + url.PathEscape(collection) + "/"
+}
+
+func (h *Handler) resourceHref(task domain.Task) string {
+    return collectionHref(Collection(task)) + url.PathEscape(h.resources.name(task.ID))
+}
+
+func etag(task domain.Task) string {
+    return `"` + strconv.FormatInt(task.Revision, 10) + `"`
+}
+
+// collectionTasks returns the tasks of one collection, or of all of them
+// when collection is empty.
+func (h *Handler) collectionTasks(collection string) ([]domain.Task, error) {
+    tasks, err := h.service.GetAllTasks()
+    if err != nil {
+        return nil, err
+    }
+    if collection == "" {
+        return tasks, nil
+    }
+    
+    selected := make([]domain.Task, 0)
+    for _, task := range tasks {
+        if Collection(task) == collection {
+            selected = append(selected, task)
+        }
+    }
+    return selected, nil
+}
+
+// collections lists every project that has tasks, plus the inbox.
+func (h *Handler) collections() ([]string, error) {
+    tasks, err := h.service.GetAllTasks()
+    if err != nil {
+        return nil, err
+    }
+    
+    names := []string{InboxCollection}
+    seen := map[string]bool{InboxCollection: true}
+    for _, task := range tasks {
+        if name := Collection(task); !seen[name] {
+            seen[name] = true
+            names = append(names, name)
+        }
+    }
+    return names, nil
+}
+
+// lookup finds the task stored at collection/name.
+func (h *Handler) lookup(collection, name string) (*domain.Task, error) {
+    id, ok := h.resources.id(name)
+    if !ok {
+        return nil, fmt.Errorf("resource %w", domain.ErrNotFound)
+    }
+    
+    task, err := h.service.GetTaskByID(id)
+    if err != nil || Collection(*task) != collection {
+        return nil, fmt.Errorf("resource %w", domain.ErrNotFound)
+    }
+    return task, nil
+}
+
+// nameTaken reports whether a new resource can't be stored under name:
+// either another task already uses it or it has the form of a server
+// assigned name (task-{id}.ics).
+func (h *Handler) nameTaken(name string) bool {
+    id, ok := h.resources.id(name)
+    if !ok {
+        return false
+    }
+    if !h.resources.aliased(name) {
+        return true
+    }
+    
+    if _, err := h.service.GetTaskByID(id); err != nil {
+        h.resources.forget(id)
+        return false
+    }
+    return true
+}
+
+// calendarComponent returns task as a VCALENDAR holding a single VTODO,
+// with the UID the client gave it if it was created over CalDAV.
+func (h *Handler) calendarComponent(task domain.Task) *ical.Component {
+    todo := transfer.VTodo(task)
+    if uid := h.resources.uid(task.ID); uid != "" {
+        todo.Get("UID").Value = uid
+    }
+    return transfer.Calendar(todo)
+}
+
+func (h *Handler) calendarObject(task domain.Task) []byte {
+    var buf bytes.Buffer
+    ical.Encode(&buf, h.calendarComponent(task))
+    return buf.Bytes()
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, collection, name string) {
+    if name == "" {
+        http.Error(w, "collections can't be downloaded, use PROPFIND or REPORT", http.StatusMethodNotAllowed)
+        return
+    }
+    
+    task, err := h.lookup(collection, name)
+    if err != nil {
+        http.NotFound(w, r)
+        return
+    }
+    
+    body := h.calendarObject(*task)
+    w.Header().Set("Content-Type", calendarType)
+    w.Header().Set("ETag", etag(*task))
+    w.Header().Set("Last-Modified", task.UpdatedAt.UTC().Format(http.TimeFormat))
+    w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+    w.WriteHeader(http.StatusOK)
+    if r.Method != http.MethodHead {
+        w.Write(body)
+    }
+}
+
+// put creates or replaces the task at collection/name. If-Match and
+// If-None-Match: * are honoured so that clients don't overwrite changes
+// made elsewhere; they are checked in the same transaction as the write.
+func (h *Handler) put(w http.ResponseWriter, r *http.Request, collection, name string) {
+    if collection == "" || name == "" {
+        http.Error(w, "PUT is only allowed on calendar resources", http.StatusMethodNotAllowed)
+        return
+    }
+    
+    body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxResourceSize))
+    if err != nil {
+        if bodyErrorStatus(err) == http.StatusRequestEntityTooLarge {
+            sendPrecondition(w, http.StatusRequestEntityTooLarge, "max-resource-size")
+            return
+        }
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    defer r.Body.Close()
+    
+    todo, err := parseCalendarObject(body)
+    if err != nil {
+        sendPrecondition(w, http.StatusForbidden, err.Error())
+        return
+    }
+    
+    record := transfer.VTodoRecord(todo)
+    if record.Err != nil {
+        sendPrecondition(w, http.StatusForbidden, "valid-calendar-data")
+        return
+    }
+    
+    replace := domain.ReplaceTaskRequest{
+        Title:     record.Task.Title,
+        Completed: record.Completed,
+        Priority:  record.Task.Priority,
+        Tags:      projectTags(collection, record.Task.Tags),
+        DueDate:   record.Task.DueDate,
+    }
+    if related := todo.Get("RELATED-TO"); related != nil {
+        if parentID, ok := h.resources.idForUID(related.Value); ok {
+            replace.ParentID = &parentID
+        }
+    }
+    
+    uid := ""
+    if prop := todo.Get("UID"); prop != nil {
+        uid = prop.Value
+    }
+    
+    var task *domain.Task
+    status := http.StatusNoContent
+    err = h.service.WithinTransaction(func(tx *service.TaskService) error {
+        txHandler := &Handler{service: tx, resources: h.resources, scopes: h.scopes}
+        
+        existing, err := txHandler.lookup(collection, name)
+        if err != nil {
+            existing = nil
+            if txHandler.nameTaken(name) {
+                return errNameTaken
+            }
+        }
+        
+        if !preconditionsHold(r, existing) {
+            return errPreconditionFailed
+        }
+        
+        if existing != nil {
+            if replace.ParentID == nil {
+                replace.ParentID = existing.ParentID
+            }
+            task, err = tx.ReplaceTask(existing.ID, replace)
+            return err
+        }
+        
+        task, err = txHandler.create(replace)
+        if err != nil {
+            return err
+        }
+        h.resources.bind(task.ID, name, uid)
+        status = http.StatusCreated
+        return nil
+    })
+    if err != nil {
+        switch {
+        case errors.Is(err, errNameTaken):
+            sendPrecondition(w, http.StatusConflict, "no-uid-conflict")
+        case errors.Is(err, errPreconditionFailed):
+            w.WriteHeader(http.StatusPreconditionFailed)
+        case errors.Is(err, domain.ErrForbidden):
+            http.Error(w, err.Error(), http.StatusForbidden)
+        case errors.Is(err, domain.ErrQuotaExceeded):
+            // RFC 4918, section 11.5
+            http.Error(w, err.Error(), http.StatusInsufficientStorage)
+        default:
+            http.Error(w, err.Error(), http.StatusBadRequest)
+        }
+        return
+    }
+    
+    w.Header().Set("ETag", etag(*task))
+    w.WriteHeader(status)
+}
+
+// create adds a task, completing it in a second step if needed, as
+// CreateTaskRequest has no completed flag.
+func (h *Handler) create(req domain.ReplaceTaskRequest) (*domain.Task, error) {
+    task, err := h.service.CreateTask(domain.CreateTaskRequest{
+        Title:    req.Title,
+        Priority: req.Priority,
+        Tags:     req.Tags,
+        DueDate:  req.DueDate,
+        ParentID: req.ParentID,
+    })
+    if err != nil || !req.Completed {
+        return task, err
+    }
+    
+    return h.service.ReplaceTask(task.ID, req)
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request, collection, name string) {
+    if name == "" {
+        http.Error(w, "collections are removed by deleting or retagging their tasks", http.StatusForbidden)
+        return
+    }
+    
+    task, err := h.lookup(collection, name)
+    if err != nil {
+        http.NotFound(w, r)
+        return
+    }
+    
+    if !checkPreconditions(w, r, task) {
+        return
+    }
+    
+    if err := h.service.DeleteTask(task.ID); err != nil {
+        if errors.Is(err, domain.ErrForbidden) {
+            http.Error(w, err.Error(), http.StatusForbidden)
+            return
+        }
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+    h.resources.forget(task.ID)
+    
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// checkPreconditions applies If-Match and If-None-Match to the current
+// state of a resource (nil when it doesn't exist), answering 412 if they
+// don't hold.
+func checkPreconditions(w http.ResponseWriter, r *http.Request, task *domain.Task) bool {
+    if !preconditionsHold(r, task) {
+        w.WriteHeader(http.StatusPreconditionFailed)
+        return false
+    }
+    return true
+}
+
+func preconditionsHold(r *http.Request, task *domain.Task) bool {
+    if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+        if task == nil || (ifMatch != "*" && !etagListContains(ifMatch, etag(*task))) {
+            return false
+        }
+    }
+    
+    if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && task != nil {
+        if ifNoneMatch == "*" || etagListContains(ifNoneMatch, etag(*task)) {
+            return false
+        }
+    }
+    
+    return true
+}
+
+func etagListContains(list, tag string) bool {
+    for _, candidate := range strings.Split(list, ",") {
+        candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+        if candidate == tag {
+            return true
+        }
+    }
+    return false
+}
+
+// projectTags puts the collection's project first so that the task stays
+// in the collection it was saved to.
+func projectTags(collection string, tags []string) []string {
+    if collection == InboxCollection {
+        return tags
+    }
+    
+    result := []string{collection}
+    for _, tag := range tags {
+        if !strings.EqualFold(tag, collection) {
+            result = append(result, tag)
+        }
+    }
+    return result
+}
+
+// parseCalendarObject returns the single VTODO of a calendar object
+// resource. The error text is the CalDAV precondition that failed.
+func parseCalendarObject(body []byte) (*ical.Component, error) {
+    components, err := ical.Parse(bytes.NewReader(body))
+    if err != nil || len(components) != 1 || components[0].Name != "VCALENDAR" {
+        return nil, fmt.Errorf("valid-calendar-data")
+    }
+    
+    var todo *ical.Component
+    for _, component := range components[0].Components {
+        switch component.Name {
+        case "VTODO":
+            if todo != nil {
+                return nil, fmt.Errorf("valid-calendar-object-resource")
+            }
+            todo = component
+        case "VTIMEZONE":
+        default:
+            return nil, fmt.Errorf("supported-calendar-component")
+        }
+    }
+    
+    if todo == nil {
+        return nil, fmt.Errorf("supported-calendar-component")
+    }
+    return todo, nil
+}
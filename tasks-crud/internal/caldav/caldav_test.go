@@ -0,0 +1,106 @@
+package caldav
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/repository"
+	"tasks-crud/internal/service"
+)
+
+func todoObject(uid, summary string) string {
+    return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\n" +
+        "BEGIN:VTODO\r\nUID:" + uid + "\r\nSUMMARY:" + summary + "\r\nEND:VTODO\r\n" +
+        "END:VCALENDAR\r\n"
+}
+
+func serve(h http.Handler, subject, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+    req := httptest.NewRequest(method, path, strings.NewReader(body))
+    for name, values := range header {
+        req.Header[name] = values
+    }
+    if subject != "" {
+        req = req.WithContext(domain.WithPrincipal(req.Context(), &domain.Principal{Subject: subject}))
+    }
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, req)
+    return rec
+}
+
+// A resource name one user picked must neither block nor reveal anything
+// to another user.
+func TestResourceNamesArePerOwner(t *testing.T) {
+    h := NewHandler(service.NewTaskService(repository.NewEmptyInMemoryTaskRepository()))
+    
+    rec := serve(h, "alice", http.MethodPut, "/caldav/inbox/groceries.ics", todoObject("a-1", "Buy milk"), nil)
+    if rec.Code != http.StatusCreated {
+        t.Fatalf("alice PUT: got %d, want 201: %s", rec.Code, rec.Body)
+    }
+    
+    if rec := serve(h, "bob", http.MethodGet, "/caldav/inbox/groceries.ics", "", nil); rec.Code != http.StatusNotFound {
+        t.Fatalf("bob GET: got %d, want 404", rec.Code)
+    }
+    
+    rec = serve(h, "bob", http.MethodPut, "/caldav/inbox/groceries.ics", todoObject("b-1", "Buy bread"), nil)
+    if rec.Code != http.StatusCreated {
+        t.Fatalf("bob PUT: got %d, want 201: %s", rec.Code, rec.Body)
+    }
+    
+    rec = serve(h, "alice", http.MethodGet, "/caldav/inbox/groceries.ics", "", nil)
+    if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Buy milk") {
+        t.Fatalf("alice GET: got %d %q, want her own task", rec.Code, rec.Body)
+    }
+}
+
+func TestPutHonoursIfMatch(t *testing.T) {
+    h := NewHandler(service.NewTaskService(repository.NewEmptyInMemoryTaskRepository()))
+    
+    rec := serve(h, "", http.MethodPut, "/caldav/inbox/a.ics", todoObject("a", "First"), nil)
+    if rec.Code != http.StatusCreated {
+        t.Fatalf("create: got %d, want 201: %s", rec.Code, rec.Body)
+    }
+    current := rec.Header().Get("ETag")
+    
+    stale := http.Header{"If-Match": {`"0"`}}
+    if rec := serve(h, "", http.MethodPut, "/caldav/inbox/a.ics", todoObject("a", "Second"), stale); rec.Code != http.StatusPreconditionFailed {
+        t.Fatalf("stale If-Match: got %d, want 412", rec.Code)
+    }
+    
+    fresh := http.Header{"If-Match": {current}}
+    if rec := serve(h, "", http.MethodPut, "/caldav/inbox/a.ics", todoObject("a", "Third"), fresh); rec.Code != http.StatusNoContent {
+        t.Fatalf("current If-Match: got %d, want 204: %s", rec.Code, rec.Body)
+    }
+    
+    if rec := serve(h, "", http.MethodPut, "/caldav/inbox/b.ics", todoObject("b", "Other"), http.Header{"If-Match": {"*"}}); rec.Code != http.StatusPreconditionFailed {
+        t.Fatalf("If-Match on a missing resource: got %d, want 412", rec.Code)
+    }
+}
+
+func TestOversizedXMLBodyIsRejected(t *testing.T) {
+    h := NewHandler(service.NewTaskService(repository.NewEmptyInMemoryTaskRepository()))
+    
+    body := `<?xml version="1.0"?><d:propfind xmlns:d="DAV:"><d:prop>` + strings.Repeat(" ", maxXMLBody) + `</d:prop></d:propfind>`
+    if rec := serve(h, "", "PROPFIND", "/caldav/", body, nil); rec.Code != http.StatusRequestEntityTooLarge {
+        t.Fatalf("got %d, want 413", rec.Code)
+    }
+}
+
+func TestForgetTenantDropsItsResourceNames(t *testing.T) {
+    h := NewHandler(service.NewTaskService(repository.NewEmptyInMemoryTaskRepository()))
+    deleted, kept := repository.NewTenantData(), repository.NewTenantData()
+    h.scopes.get(deleted, "alice").bind(1, "a.ics", "")
+    h.scopes.get(deleted, "bob").bind(1, "b.ics", "")
+    h.scopes.get(kept, "alice").bind(1, "a.ics", "")
+    
+    h.ForgetTenant(deleted)
+    
+    if len(h.scopes.names) != 1 {
+        t.Fatalf("%d scopes left, want only the other tenant's", len(h.scopes.names))
+    }
+    if _, ok := h.scopes.names[resourceScope{tenant: kept, owner: "alice"}]; !ok {
+        t.Fatal("the other tenant's names were dropped")
+    }
+}
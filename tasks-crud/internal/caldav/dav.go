@@ -0,0 +1,380 @@
+package caldav
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tasks-crud/internal/domain"
+)
+
+const (
+    nsDAV         = "DAV:"
+    nsCalDAV      = "urn:ietf:params:xml:ns:caldav"
+    nsCalServer   = "http://calendarserver.org/ns/"
+    maxXMLBody    = 1 << 20
+    homeName      = "Tasks"
+    statusOK      = "HTTP/1.1 200 OK"
+    statusMissing = "HTTP/1.1 404 Not Found"
+)
+
+var prefixes = map[string]string{
+    nsDAV:       "d",
+    nsCalDAV:    "c",
+    nsCalServer: "cs",
+}
+
+var propCalendarData = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+
+// propRequest is the part of PROPFIND and REPORT bodies that selects
+// properties.
+type propRequest struct {
+    AllProp  *struct{}  `xml:"DAV: allprop"`
+    PropName *struct{}  `xml:"DAV: propname"`
+    Prop     *propNames `xml:"DAV: prop"`
+}
+
+type propNames struct {
+    Names []anyElement `xml:",any"`
+}
+
+type anyElement struct {
+    XMLName xml.Name
+}
+
+type propfindRequest struct {
+    XMLName xml.Name `xml:"DAV: propfind"`
+    propRequest
+}
+
+type reportRequest struct {
+    XMLName xml.Name
+    propRequest
+    Filter *compFilter `xml:"urn:ietf:params:xml:ns:caldav filter>comp-filter"`
+    Hrefs  []string    `xml:"DAV: href"`
+}
+
+// properties maps property names to their inner XML.
+type properties map[xml.Name]string
+
+func (h *Handler) propfind(w http.ResponseWriter, r *http.Request, collection, name string) {
+    var req propfindRequest
+    if err := decodeXML(w, r, &req); err != nil {
+        http.Error(w, err.Error(), bodyErrorStatus(err))
+        return
+    }
+    
+    // Depth: infinity is served as 1; the tree is only two levels deep.
+    children := r.Header.Get("Depth") != "0"
+    
+    ms := newMultistatus()
+    switch {
+    case collection == "":
+        ms.add(Prefix, h.homeProperties(), req.propRequest)
+        if children {
+            names, err := h.collections()
+            if err != nil {
+                http.Error(w, err.Error(), http.StatusInternalServerError)
+                return
+            }
+            for _, name := range names {
+                tasks, err := h.collectionTasks(name)
+                if err != nil {
+                    http.Error(w, err.Error(), http.StatusInternalServerError)
+                    return
+                }
+                ms.add(collectionHref(name), collectionProperties(name, tasks), req.propRequest)
+            }
+        }
+    case name == "":
+        tasks, err := h.collectionTasks(collection)
+        if err != nil {
+            http.Error(w, err.Error(), http.StatusInternalServerError)
+            return
+        }
+        if len(tasks) == 0 && collection != InboxCollection {
+            http.NotFound(w, r)
+            return
+        }
+        ms.add(collectionHref(collection), collectionProperties(collection, tasks), req.propRequest)
+        if children {
+            for _, task := range tasks {
+                ms.add(h.resourceHref(task), h.resourceProperties(task), req.propRequest)
+            }
+        }
+    default:
+        task, err := h.lookup(collection, name)
+        if err != nil {
+            http.NotFound(w, r)
+            return
+        }
+        ms.add(h.resourceHref(*task), h.resourceProperties(*task), req.propRequest)
+    }
+    
+    ms.write(w)
+}
+
+// report serves calendar-query and calendar-multiget.
+func (h *Handler) report(w http.ResponseWriter, r *http.Request, collection string) {
+    var req reportRequest
+    if err := decodeXML(w, r, &req); err != nil {
+        http.Error(w, err.Error(), bodyErrorStatus(err))
+        return
+    }
+    
+    ms := newMultistatus()
+    switch req.XMLName {
+    case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+        tasks, err := h.collectionTasks(collection)
+        if err != nil {
+            http.Error(w, err.Error(), http.StatusInternalServerError)
+            return
+        }
+        for _, task := range tasks {
+            if req.Filter != nil && !req.Filter.matchCalendar(h.calendarComponent(task)) {
+                continue
+            }
+            ms.add(h.resourceHref(task), h.resourceProperties(task), req.propRequest)
+        }
+    case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+        for _, href := range req.Hrefs {
+            hrefURL, err := url.Parse(strings.TrimSpace(href))
+            if err != nil {
+                ms.missing(href)
+                continue
+            }
+            hrefCollection, hrefName, ok := splitPath(hrefURL.Path)
+            if !ok || hrefName == "" {
+                ms.missing(href)
+                continue
+            }
+            task, err := h.lookup(hrefCollection, hrefName)
+            if err != nil {
+                ms.missing(href)
+                continue
+            }
+            ms.add(h.resourceHref(*task), h.resourceProperties(*task), req.propRequest)
+        }
+    default:
+        sendError(w, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "supported-report"})
+        return
+    }
+    
+    ms.write(w)
+}
+
+func (h *Handler) homeProperties() properties {
+    principal := href(Prefix)
+    return properties{
+        {Space: nsDAV, Local: "resourcetype"}:           "<d:collection/><d:principal/>",
+        {Space: nsDAV, Local: "displayname"}:            escape(homeName),
+        {Space: nsDAV, Local: "current-user-principal"}: principal,
+        {Space: nsDAV, Local: "principal-URL"}:          principal,
+        {Space: nsCalDAV, Local: "calendar-home-set"}:   principal,
+    }
+}
+
+func collectionProperties(collection string, tasks []domain.Task) properties {
+    return properties{
+        {Space: nsDAV, Local: "resourcetype"}:                        "<d:collection/><c:calendar/>",
+        {Space: nsDAV, Local: "displayname"}:                         escape(collection),
+        {Space: nsDAV, Local: "current-user-principal"}:              href(Prefix),
+        {Space: nsDAV, Local: "current-user-privilege-set"}:          privileges("read", "write", "write-content", "bind", "unbind"),
+        {Space: nsDAV, Local: "supported-report-set"}:                supportedReports("calendar-query", "calendar-multiget"),
+        {Space: nsCalDAV, Local: "supported-calendar-component-set"}: `<c:comp name="VTODO"/>`,
+        {Space: nsCalServer, Local: "getctag"}:                       escape(ctag(tasks)),
+    }
+}
+
+func (h *Handler) resourceProperties(task domain.Task) properties {
+    data := h.calendarObject(task)
+    return properties{
+        {Space: nsDAV, Local: "resourcetype"}:     "",
+        {Space: nsDAV, Local: "getetag"}:          escape(etag(task)),
+        {Space: nsDAV, Local: "getcontenttype"}:   escape(calendarType + "; component=vtodo"),
+        {Space: nsDAV, Local: "getcontentlength"}: strconv.Itoa(len(data)),
+        {Space: nsDAV, Local: "getlastmodified"}:  escape(task.UpdatedAt.UTC().Format(http.TimeFormat)),
+        propCalendarData:                          escape(string(data)),
+    }
+}
+
+// ctag changes whenever a task in the collection is added, changed or
+// removed.
+func ctag(tasks []domain.Task) string {
+    hash := sha256.New()
+    for _, task := range tasks {
+        fmt.Fprintf(hash, "%d:%d;", task.ID, task.Revision)
+    }
+    return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+type multistatus struct {
+    buf bytes.Buffer
+}
+
+func newMultistatus() *multistatus {
+    ms := &multistatus{}
+    ms.buf.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+    ms.buf.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/">`)
+    return ms
+}
+
+// add writes a response for one resource. Without an explicit property
+// list every property except calendar-data is returned, as allprop
+// requires; requested properties that the resource lacks are reported
+// with 404.
+func (ms *multistatus) add(hrefPath string, available properties, req propRequest) {
+    found := make(properties)
+    var missing []xml.Name
+    
+    switch {
+    case req.Prop != nil:
+        for _, element := range req.Prop.Names {
+            if value, ok := available[element.XMLName]; ok {
+                found[element.XMLName] = value
+            } else {
+                missing = append(missing, element.XMLName)
+            }
+        }
+    case req.PropName != nil:
+        for name := range available {
+            found[name] = ""
+        }
+    default:
+        for name, value := range available {
+            if name != propCalendarData {
+                found[name] = value
+            }
+        }
+    }
+    
+    ms.buf.WriteString("<d:response>" + href(hrefPath))
+    if len(found) > 0 {
+        ms.buf.WriteString("<d:propstat><d:prop>")
+        for _, name := range sortedNames(found) {
+            ms.buf.WriteString(element(name, found[name]))
+        }
+        ms.buf.WriteString("</d:prop><d:status>" + statusOK + "</d:status></d:propstat>")
+    }
+    if len(missing) > 0 {
+        ms.buf.WriteString("<d:propstat><d:prop>")
+        for _, name := range missing {
+            ms.buf.WriteString(element(name, ""))
+        }
+        ms.buf.WriteString("</d:prop><d:status>" + statusMissing + "</d:status></d:propstat>")
+    }
+    ms.buf.WriteString("</d:response>")
+}
+
+func (ms *multistatus) missing(hrefPath string) {
+    ms.buf.WriteString("<d:response>" + href(hrefPath) + "<d:status>" + statusMissing + "</d:status></d:response>")
+}
+
+func (ms *multistatus) write(w http.ResponseWriter) {
+    ms.buf.WriteString("</d:multistatus>")
+    w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+    w.WriteHeader(http.StatusMultiStatus)
+    w.Write(ms.buf.Bytes())
+}
+
+// sendError writes a DAV:error body naming the failed precondition.
+func sendError(w http.ResponseWriter, status int, condition xml.Name) {
+    w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+    w.WriteHeader(status)
+    fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>`+"\n"+`<d:error xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">%s</d:error>`, element(condition, ""))
+}
+
+func sendPrecondition(w http.ResponseWriter, status int, condition string) {
+    sendError(w, status, xml.Name{Space: nsCalDAV, Local: condition})
+}
+
+// decodeXML reads an optional XML request body into v.
+func decodeXML(w http.ResponseWriter, r *http.Request, v interface{}) error {
+    body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxXMLBody))
+    if err != nil {
+        return fmt.Errorf("failed to read request body: %w", err)
+    }
+    defer r.Body.Close()
+    
+    if len(bytes.TrimSpace(body)) == 0 {
+        return nil
+    }
+    if err := xml.Unmarshal(body, v); err != nil {
+        return fmt.Errorf("invalid XML body: %w", err)
+    }
+    return nil
+}
+
+// bodyErrorStatus is the status for a failure to read the request body:
+// 413 if it was over the limit, 400 otherwise.
+func bodyErrorStatus(err error) int {
+    var maxBytesErr *http.MaxBytesError
+    if errors.As(err, &maxBytesErr) {
+        return http.StatusRequestEntityTooLarge
+    }
+    return http.StatusBadRequest
+}
+
+func element(name xml.Name, inner string) string {
+    if prefix, ok := prefixes[name.Space]; ok {
+        tag := prefix + ":" + name.Local
+        if inner == "" {
+            return "<" + tag + "/>"
+        }
+        return "<" + tag + ">" + inner + "</" + tag + ">"
+    }
+    
+    if inner == "" {
+        return `<x:` + name.Local + ` xmlns:x="` + escape(name.Space) + `"/>`
+    }
+    return `<x:` + name.Local + ` xmlns:x="` + escape(name.Space) + `">` + inner + `</x:` + name.Local + `>`
+}
+
+func href(path string) string {
+    return "<d:href>" + escape(path) + "</d:href>"
+}
+
+func privileges(names ...string) string {
+    var b strings.Builder
+    for _, name := range names {
+        b.WriteString("<d:privilege><d:" + name + "/></d:privilege>")
+    }
+    return b.String()
+}
+
+func supportedReports(names ...string) string {
+    var b strings.Builder
+    for _, name := range names {
+        b.WriteString("<d:supported-report><d:report><c:" + name + "/></d:report></d:supported-report>")
+    }
+    return b.String()
+}
+
+func escape(s string) string {
+    var b strings.Builder
+    xml.EscapeText(&b, []byte(s))
+    return b.String()
+}
+
+func sortedNames(props properties) []xml.Name {
+    names := make([]xml.Name, 0, len(props))
+    for name := range props {
+        names = append(names, name)
+    }
+    sort.Slice(names, func(i, j int) bool {
+        if names[i].Space != names[j].Space {
+            return names[i].Space < names[j].Space
+        }
+        return names[i].Local < names[j].Local
+    })
+    return names
+}
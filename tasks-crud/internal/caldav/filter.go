@@ -0,0 +1,139 @@
+package caldav
+
+import (
+	"strings"
+
+	"tasks-crud/internal/ical"
+)
+
+// compFilter is a CALDAV:comp-filter (RFC 4791, section 9.7.1). Filters
+// nested inside it must all match.
+type compFilter struct {
+    Name         string        `xml:"name,attr"`
+    IsNotDefined *struct{}     `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+    TimeRange    *timeRange    `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+    PropFilters  []propFilter  `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+    CompFilters  []compFilter  `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type propFilter struct {
+    Name         string     `xml:"name,attr"`
+    IsNotDefined *struct{}  `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+    TextMatch    *textMatch `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+type textMatch struct {
+    Value           string `xml:",chardata"`
+    NegateCondition string `xml:"negate-condition,attr"`
+}
+
+type timeRange struct {
+    Start string `xml:"start,attr"`
+    End   string `xml:"end,attr"`
+}
+
+// matchCalendar applies the top-level filter, which must name VCALENDAR.
+func (f *compFilter) matchCalendar(calendar *ical.Component) bool {
+    if !strings.EqualFold(f.Name, calendar.Name) {
+        return false
+    }
+    return f.match(calendar)
+}
+
+func (f *compFilter) match(component *ical.Component) bool {
+    if f.IsNotDefined != nil {
+        return false
+    }
+    
+    if f.TimeRange != nil && !f.TimeRange.match(component) {
+        return false
+    }
+    
+    for _, filter := range f.PropFilters {
+        if !filter.match(component) {
+            return false
+        }
+    }
+    
+    for _, filter := range f.CompFilters {
+        var children []*ical.Component
+        for _, child := range component.Components {
+            if strings.EqualFold(child.Name, filter.Name) {
+                children = append(children, child)
+            }
+        }
+        
+        if filter.IsNotDefined != nil {
+            if len(children) > 0 {
+                return false
+            }
+            continue
+        }
+        
+        matched := false
+        for _, child := range children {
+            if filter.match(child) {
+                matched = true
+                break
+            }
+        }
+        if !matched {
+            return false
+        }
+    }
+    
+    return true
+}
+
+func (f propFilter) match(component *ical.Component) bool {
+    props := component.GetAll(f.Name)
+    if f.IsNotDefined != nil {
+        return len(props) == 0
+    }
+    if len(props) == 0 {
+        return false
+    }
+    if f.TextMatch == nil {
+        return true
+    }
+    
+    needle := strings.ToLower(f.TextMatch.Value)
+    found := false
+    for _, prop := range props {
+        if strings.Contains(strings.ToLower(prop.Text()), needle) {
+            found = true
+            break
+        }
+    }
+    return found != (f.TextMatch.NegateCondition == "yes")
+}
+
+// match checks a VTODO against the range by its DUE date. To-dos without a
+// due date always match, so that clients asking for a range still see
+// undated to-dos.
+func (t *timeRange) match(component *ical.Component) bool {
+    due := component.Get("DUE")
+    if due == nil {
+        return true
+    }
+    
+    at, err := due.Time()
+    if err != nil {
+        return false
+    }
+    
+    if t.Start != "" {
+        start, err := ical.ParseTime(t.Start, "")
+        if err == nil && at.Before(start) {
+            return false
+        }
+    }
+    if t.End != "" {
+        end, err := ical.ParseTime(t.End, "")
+        if err == nil && !at.Before(end) {
+            return false
+        }
+    }
+    return true
+}
+
@@ -0,0 +1,161 @@
+package caldav
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"tasks-crud/internal/repository"
+	"tasks-crud/internal/transfer"
+)
+
+const resourceSuffix = ".ics"
+
+// resourceNames remembers the resource names and UIDs that clients chose
+// for the tasks they created. Other tasks are served as task-{id}.ics with
+// the UID from transfer.TaskUID.
+type resourceNames struct {
+    names  map[int]string
+    uids   map[int]string
+    byName map[string]int
+    byUID  map[string]int
+    mu     sync.RWMutex
+}
+
+func newResourceNames() *resourceNames {
+    return &resourceNames{
+        names:  make(map[int]string),
+        uids:   make(map[int]string),
+        byName: make(map[string]int),
+        byUID:  make(map[string]int),
+    }
+}
+
+func (r *resourceNames) name(id int) string {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    if name, exists := r.names[id]; exists {
+        return name
+    }
+    return "task-" + strconv.Itoa(id) + resourceSuffix
+}
+
+// uid returns the client-chosen UID of a task, or "" if it has none.
+func (r *resourceNames) uid(id int) string {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    return r.uids[id]
+}
+
+func (r *resourceNames) id(name string) (int, bool) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    if id, exists := r.byName[name]; exists {
+        return id, true
+    }
+    
+    rest, found := strings.CutPrefix(name, "task-")
+    if !found {
+        return 0, false
+    }
+    rest, found = strings.CutSuffix(rest, resourceSuffix)
+    if !found {
+        return 0, false
+    }
+    id, err := strconv.Atoi(rest)
+    return id, err == nil && id > 0
+}
+
+func (r *resourceNames) aliased(name string) bool {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    _, exists := r.byName[name]
+    return exists
+}
+
+func (r *resourceNames) idForUID(uid string) (int, bool) {
+    r.mu.RLock()
+    id, exists := r.byUID[uid]
+    r.mu.RUnlock()
+    
+    if exists {
+        return id, true
+    }
+    return transfer.TaskIDFromUID(uid)
+}
+
+// bind records the name and UID a client used when creating task id.
+func (r *resourceNames) bind(id int, name, uid string) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    if name != "task-"+strconv.Itoa(id)+resourceSuffix {
+        r.names[id] = name
+        r.byName[name] = id
+    }
+    if uid != "" && uid != transfer.TaskUID(id) {
+        r.uids[id] = uid
+        r.byUID[uid] = id
+    }
+}
+
+func (r *resourceNames) forget(id int) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    delete(r.byName, r.names[id])
+    delete(r.byUID, r.uids[id])
+    delete(r.names, id)
+    delete(r.uids, id)
+}
+
+// scopedResources keeps the resource names of each caller apart: task
+// IDs are only unique within a tenant, and the names one user chose must
+// not be visible to, or block, anyone else. Names are keyed by the
+// tenant's stores, so a deleted and recreated tenant starts afresh.
+type scopedResources struct {
+    names map[resourceScope]*resourceNames
+    mu    sync.Mutex
+}
+
+type resourceScope struct {
+    tenant *repository.TenantData
+    owner  string
+}
+
+func newScopedResources() *scopedResources {
+    return &scopedResources{
+        names: make(map[resourceScope]*resourceNames),
+    }
+}
+
+// get returns the names of owner within tenant; tenant is nil without
+// multi-tenancy and owner is "" without authentication.
+func (t *scopedResources) get(tenant *repository.TenantData, owner string) *resourceNames {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    
+    key := resourceScope{tenant: tenant, owner: owner}
+    names, exists := t.names[key]
+    if !exists {
+        names = newResourceNames()
+        t.names[key] = names
+    }
+    return names
+}
+
+// forgetTenant drops the names kept for a deleted tenant.
+func (t *scopedResources) forgetTenant(tenant *repository.TenantData) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    
+    for key := range t.names {
+        if key.tenant == tenant {
+            delete(t.names, key)
+        }
+    }
+}
@@ -0,0 +1,155 @@
+// Package certs keeps the server certificate and the client CA bundle in
+// memory and reloads them when their files change, so certificates can be
+// rotated without a restart.
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Store serves the certificate in CertFile and KeyFile and, when CAFile is
+// set, verifies client certificates against the bundle in it.
+type Store struct {
+    certFile string
+    keyFile  string
+    caFile   string
+    cert     *tls.Certificate
+    pool     *x509.CertPool
+    stamp    string
+    mu       sync.RWMutex
+}
+
+// NewStore loads the files once; they must be valid from the start.
+func NewStore(certFile, keyFile, caFile string) (*Store, error) {
+    s := &Store{
+        certFile: certFile,
+        keyFile:  keyFile,
+        caFile:   caFile,
+    }
+    if _, err := s.Reload(); err != nil {
+        return nil, err
+    }
+    
+    return s, nil
+}
+
+// Reload reads the files again if any of them changed since the last
+// successful load and reports whether it did. On error the certificates
+// in use are kept.
+func (s *Store) Reload() (bool, error) {
+    stamp, err := s.fileStamp()
+    if err != nil {
+        return false, err
+    }
+    
+    s.mu.RLock()
+    unchanged := stamp == s.stamp
+    s.mu.RUnlock()
+    if unchanged {
+        return false, nil
+    }
+    
+    cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+    if err != nil {
+        return false, fmt.Errorf("failed to load certificate: %w", err)
+    }
+    
+    var pool *x509.CertPool
+    if s.caFile != "" {
+        bundle, err := os.ReadFile(s.caFile)
+        if err != nil {
+            return false, fmt.Errorf("failed to read client CA bundle: %w", err)
+        }
+        pool = x509.NewCertPool()
+        if !pool.AppendCertsFromPEM(bundle) {
+            return false, fmt.Errorf("client CA bundle %s has no certificates", s.caFile)
+        }
+    }
+    
+    s.mu.Lock()
+    s.cert = &cert
+    s.pool = pool
+    s.stamp = stamp
+    s.mu.Unlock()
+    
+    return true, nil
+}
+
+// Watch checks the files every interval, for as long as the process runs.
+func (s *Store) Watch(interval time.Duration) {
+    if interval <= 0 {
+        return
+    }
+    
+    for range time.Tick(interval) {
+        reloaded, err := s.Reload()
+        switch {
+        case err != nil:
+            log.Printf("Failed to reload TLS certificates, keeping the current ones: %v", err)
+        case reloaded:
+            log.Printf("Reloaded TLS certificates from %s", s.certFile)
+        }
+    }
+}
+
+// fileStamp sums up the size and modification time of every file, which
+// is enough to notice a rotation.
+func (s *Store) fileStamp() (string, error) {
+    stamp := ""
+    for _, name := range []string{s.certFile, s.keyFile, s.caFile} {
+        if name == "" {
+            continue
+        }
+        info, err := os.Stat(name)
+        if err != nil {
+            return "", fmt.Errorf("failed to read %s: %w", name, err)
+        }
+        stamp += fmt.Sprintf("%s:%d:%d;", name, info.Size(), info.ModTime().UnixNano())
+    }
+    
+    return stamp, nil
+}
+
+func (s *Store) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    return s.cert, nil
+}
+
+func (s *Store) ClientCAs() *x509.CertPool {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    return s.pool
+}
+
+// TLSConfig returns a server configuration that always uses the current
+// certificate and CA bundle. clientAuth only applies when the store has a
+// CA bundle. nextProtos must be given up front: configs handed out per
+// connection don't see the protocols servers add to the original.
+func (s *Store) TLSConfig(clientAuth tls.ClientAuthType, nextProtos ...string) *tls.Config {
+    config := &tls.Config{
+        MinVersion:     tls.VersionTLS12,
+        GetCertificate: s.GetCertificate,
+        NextProtos:     nextProtos,
+    }
+    if s.caFile == "" {
+        return config
+    }
+    
+    config.ClientAuth = clientAuth
+    config.ClientCAs = s.ClientCAs()
+    config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+        current := config.Clone()
+        current.GetConfigForClient = nil
+        current.ClientCAs = s.ClientCAs()
+        return current, nil
+    }
+    
+    return config
+}
@@ -0,0 +1,216 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// authority is a throwaway CA that issues the test certificates.
+type authority struct {
+    cert *x509.Certificate
+    key  *ecdsa.PrivateKey
+    pem  []byte
+}
+
+var serial int64
+
+func newAuthority(t *testing.T, name string) *authority {
+    t.Helper()
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+    serial++
+    template := &x509.Certificate{
+        SerialNumber:          big.NewInt(serial),
+        Subject:               pkix.Name{CommonName: name},
+        NotBefore:             time.Now().Add(-time.Hour),
+        NotAfter:              time.Now().Add(time.Hour),
+        KeyUsage:              x509.KeyUsageCertSign,
+        BasicConstraintsValid: true,
+        IsCA:                  true,
+    }
+    der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    cert, err := x509.ParseCertificate(der)
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    return &authority{
+        cert: cert,
+        key:  key,
+        pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+    }
+}
+
+// issue returns a certificate for name and its key, both PEM-encoded.
+func (a *authority) issue(t *testing.T, name string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+    t.Helper()
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+    serial++
+    template := &x509.Certificate{
+        SerialNumber: big.NewInt(serial),
+        Subject:      pkix.Name{CommonName: name},
+        DNSNames:     []string{name},
+        IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+        NotBefore:    time.Now().Add(-time.Hour),
+        NotAfter:     time.Now().Add(time.Hour),
+        KeyUsage:     x509.KeyUsageDigitalSignature,
+        ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+    }
+    der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    keyDER, err := x509.MarshalECPrivateKey(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+        pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// rotate writes the server certificate and the client CA bundle of ca,
+// dated so the change is noticed however coarse the file system clock.
+func rotate(t *testing.T, dir string, ca *authority, generation int) {
+    t.Helper()
+    certPEM, keyPEM := ca.issue(t, "localhost", x509.ExtKeyUsageServerAuth)
+    stamp := time.Now().Add(time.Duration(generation) * time.Minute)
+    for name, data := range map[string][]byte{"server.crt": certPEM, "server.key": keyPEM, "ca.crt": ca.pem} {
+        path := filepath.Join(dir, name)
+        if err := os.WriteFile(path, data, 0o600); err != nil {
+            t.Fatal(err)
+        }
+        if err := os.Chtimes(path, stamp, stamp); err != nil {
+            t.Fatal(err)
+        }
+    }
+}
+
+// handshake connects to addr with a client certificate from ca and
+// returns the issuer of the server's certificate.
+func handshake(t *testing.T, addr string, ca *authority) (string, error) {
+    certPEM, keyPEM := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+    clientCert, err := tls.X509KeyPair(certPEM, keyPEM)
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    conn, err := tls.Dial("tcp", addr, &tls.Config{
+        Certificates:       []tls.Certificate{clientCert},
+        InsecureSkipVerify: true,
+    })
+    if err != nil {
+        return "", err
+    }
+    defer conn.Close()
+    
+    // With TLS 1.3 the server checks the client certificate after the
+    // client considers the handshake done. The server hangs up once it is,
+    // so a read returns EOF, or the alert if the certificate was refused.
+    if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+        return "", err
+    }
+    return conn.ConnectionState().PeerCertificates[0].Issuer.CommonName, nil
+}
+
+func TestRotationIsPickedUpByRunningListener(t *testing.T) {
+    dir := t.TempDir()
+    first, second := newAuthority(t, "first CA"), newAuthority(t, "second CA")
+    rotate(t, dir, first, 0)
+    
+    store, err := NewStore(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.crt"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    listener, err := tls.Listen("tcp", "127.0.0.1:0", store.TLSConfig(tls.RequireAndVerifyClientCert))
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer listener.Close()
+    go func() {
+        for {
+            conn, err := listener.Accept()
+            if err != nil {
+                return
+            }
+            go func() {
+                conn.(*tls.Conn).Handshake()
+                conn.Close()
+            }()
+        }
+    }()
+    addr := listener.Addr().String()
+    
+    if issuer, err := handshake(t, addr, first); err != nil || issuer != "first CA" {
+        t.Fatalf("before rotation: got %q, %v; want the first certificate", issuer, err)
+    }
+    
+    rotate(t, dir, second, 1)
+    go store.Watch(10 * time.Millisecond)
+    deadline := time.Now().Add(5 * time.Second)
+    for {
+        cert, _ := store.GetCertificate(nil)
+        if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil && leaf.Issuer.CommonName == "second CA" {
+            break
+        }
+        if time.Now().After(deadline) {
+            t.Fatal("Watch didn't pick up the rotated certificate")
+        }
+        time.Sleep(10 * time.Millisecond)
+    }
+    
+    if issuer, err := handshake(t, addr, second); err != nil || issuer != "second CA" {
+        t.Fatalf("after rotation: got %q, %v; want the second certificate", issuer, err)
+    }
+    if _, err := handshake(t, addr, first); err == nil {
+        t.Fatal("a client certificate from the replaced CA was still accepted")
+    }
+}
+
+func TestFailedReloadKeepsCurrentCertificate(t *testing.T) {
+    dir := t.TempDir()
+    rotate(t, dir, newAuthority(t, "first CA"), 0)
+    store, err := NewStore(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), "")
+    if err != nil {
+        t.Fatal(err)
+    }
+    before, _ := store.GetCertificate(nil)
+    
+    // A key written before its certificate, as a rotation in progress.
+    stamp := time.Now().Add(time.Minute)
+    path := filepath.Join(dir, "server.key")
+    _, keyPEM := newAuthority(t, "second CA").issue(t, "localhost", x509.ExtKeyUsageServerAuth)
+    if err := os.WriteFile(path, keyPEM, 0o600); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.Chtimes(path, stamp, stamp); err != nil {
+        t.Fatal(err)
+    }
+    
+    if reloaded, err := store.Reload(); err == nil || reloaded {
+        t.Fatalf("got %v, %v; want the mismatched pair refused", reloaded, err)
+    }
+    if after, _ := store.GetCertificate(nil); after != before {
+        t.Fatal("the certificate in use was replaced by a failed reload")
+    }
+}
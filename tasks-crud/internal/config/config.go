@@ -1,25 +1,141 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-    Port int    
-    Env  string
+    Port            int    
+    GRPCPort        int
+    Env             string
+    IdempotencyTTL  time.Duration
+    JWTSecret       string
+    JWKS            string
+    JWTIssuer       string
+    JWTAudience     string
+    JWTClockSkew    time.Duration
+    JWKSCacheTTL    time.Duration
+    AccessTokenTTL  time.Duration
+    RefreshTokenTTL time.Duration
+    PasswordHash    string
+    MultiTenant     bool
+    TenantHeader    string
+    TenantDomain    string
+    Tenants         []string
+    AdminToken      string
+    OIDCIssuer      string
+    OIDCClientID    string
+    OIDCSecret      string
+    OIDCRedirectURL string
+    OIDCScopes      []string
+    OIDCGroupsClaim string
+    OIDCGroupRoles  map[string]string
+    OIDCDefaultRole string
+    RateLimitReads  int
+    RateLimitWrites int
+    RateLimitWindow time.Duration
+    MaxUserTasks    int
+    MaxTenantTasks  int
+    CORSOrigins     []string
+    CORSMethods     []string
+    CORSHeaders     []string
+    CORSExposed     []string
+    CORSCredentials bool
+    CORSMaxAge      time.Duration
+    SecurityPolicy  string
+    MaxBodySize     int
+    TLSCertFile     string
+    TLSKeyFile      string
+    TLSClientCA     string
+    TLSClientAuth   string
+    TLSPollInterval time.Duration
+    RedirectPort    int
 }
 
 func Load() *Config {
     port := getEnvAsInt("PORT", 8080)
+    grpcPort := getEnvAsInt("GRPC_PORT", 9090)
     env := getEnv("ENV", "development")
+    idempotencyTTL := getEnvAsDuration("IDEMPOTENCY_TTL", 24*time.Hour)
+    oidcScopes := getEnvAsList("OIDC_SCOPES")
+    if len(oidcScopes) == 0 {
+        oidcScopes = []string{"openid", "profile", "email"}
+    }
+    tenantHeader := getEnv("TENANT_HEADER", "X-Tenant-ID")
+    tlsCertFile := getEnv("TLS_CERT_FILE", "")
+    scheme := "http"
+    if tlsCertFile != "" {
+        scheme = "https"
+    }
     
     return &Config{
-        Port: port,
-        Env:  env,
+        Port:            port,
+        GRPCPort:        grpcPort,
+        Env:             env,
+        IdempotencyTTL:  idempotencyTTL,
+        JWTSecret:       getEnv("JWT_SECRET", ""),
+        JWKS:            getEnv("JWT_JWKS", ""),
+        JWTIssuer:       getEnv("JWT_ISSUER", ""),
+        JWTAudience:     getEnv("JWT_AUDIENCE", ""),
+        JWTClockSkew:    getEnvAsDuration("JWT_CLOCK_SKEW", 30*time.Second),
+        JWKSCacheTTL:    getEnvAsDuration("JWT_JWKS_CACHE_TTL", 10*time.Minute),
+        AccessTokenTTL:  getEnvAsDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+        RefreshTokenTTL: getEnvAsDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+        PasswordHash:    getEnv("PASSWORD_HASH", "argon2id"),
+        MultiTenant:     getEnvAsBool("MULTI_TENANT", false),
+        TenantHeader:    tenantHeader,
+        TenantDomain:    getEnv("TENANT_DOMAIN", ""),
+        Tenants:         getEnvAsList("TENANTS"),
+        AdminToken:      getEnv("ADMIN_TOKEN", ""),
+        OIDCIssuer:      getEnv("OIDC_ISSUER", ""),
+        OIDCClientID:    getEnv("OIDC_CLIENT_ID", ""),
+        OIDCSecret:      getEnv("OIDC_CLIENT_SECRET", ""),
+        OIDCRedirectURL: getEnv("OIDC_REDIRECT_URL", fmt.Sprintf("%s://localhost:%d/api/v1/auth/oidc/callback", scheme, port)),
+        OIDCScopes:      oidcScopes,
+        OIDCGroupsClaim: getEnv("OIDC_GROUPS_CLAIM", "groups"),
+        OIDCGroupRoles:  getEnvAsMap("OIDC_GROUP_ROLES"),
+        OIDCDefaultRole: getEnv("OIDC_DEFAULT_ROLE", "editor"),
+        RateLimitReads:  getEnvAsInt("RATE_LIMIT_READS", 600),
+        RateLimitWrites: getEnvAsInt("RATE_LIMIT_WRITES", 120),
+        RateLimitWindow: getEnvAsDuration("RATE_LIMIT_WINDOW", time.Minute),
+        MaxUserTasks:    getEnvAsInt("MAX_TASKS_PER_USER", 0),
+        MaxTenantTasks:  getEnvAsInt("MAX_TASKS_PER_TENANT", 0),
+        CORSOrigins:     getEnvAsList("CORS_ALLOWED_ORIGINS"),
+        CORSMethods:     getEnvAsListOr("CORS_ALLOWED_METHODS", "GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"),
+        CORSHeaders:     getEnvAsListOr("CORS_ALLOWED_HEADERS", "Authorization", "Content-Type", "Idempotency-Key", "If-Match", "If-None-Match", tenantHeader),
+        CORSExposed:     getEnvAsListOr("CORS_EXPOSED_HEADERS", "ETag", "Link", "Location", "X-Total-Count", "Idempotent-Replayed", "RateLimit", "RateLimit-Policy", "Retry-After"),
+        CORSCredentials: getEnvAsBool("CORS_ALLOW_CREDENTIALS", false),
+        CORSMaxAge:      getEnvAsDuration("CORS_MAX_AGE", 10*time.Minute),
+        SecurityPolicy:  getEnv("CONTENT_SECURITY_POLICY", "default-src 'self'; frame-ancestors 'none'; object-src 'none'"),
+        MaxBodySize:     getEnvAsInt("MAX_BODY_SIZE", 1<<20),
+        TLSCertFile:     tlsCertFile,
+        TLSKeyFile:      getEnv("TLS_KEY_FILE", ""),
+        TLSClientCA:     getEnv("TLS_CLIENT_CA_FILE", ""),
+        TLSClientAuth:   getEnv("TLS_CLIENT_AUTH", "require"),
+        TLSPollInterval: getEnvAsDuration("TLS_RELOAD_INTERVAL", 30*time.Second),
+        RedirectPort:    getEnvAsInt("HTTP_REDIRECT_PORT", 0),
     }
 }
 
+// OIDCEnabled reports whether single sign-on is configured.
+func (c *Config) OIDCEnabled() bool {
+    return c.OIDCIssuer != "" && c.OIDCClientID != ""
+}
+
+// TLSEnabled reports whether the server serves HTTPS itself.
+func (c *Config) TLSEnabled() bool {
+    return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// AuthEnabled reports whether a JWT secret or key set is configured.
+func (c *Config) AuthEnabled() bool {
+    return c.JWTSecret != "" || c.JWKS != ""
+}
+
 func Get() *Config {
     return cfg
 }
@@ -40,4 +156,51 @@ func getEnvAsInt(key string, defaultValue int) int {
         }
     }
     return defaultValue
-}
\ No newline at end of file
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+    if value, exists := os.LookupEnv(key); exists {
+        if duration, err := time.ParseDuration(value); err == nil {
+            return duration
+        }
+    }
+    return defaultValue
+}
+func getEnvAsBool(key string, defaultValue bool) bool {
+    if value, exists := os.LookupEnv(key); exists {
+        if boolValue, err := strconv.ParseBool(value); err == nil {
+            return boolValue
+        }
+    }
+    return defaultValue
+}
+
+// getEnvAsList splits a comma-separated variable, dropping empty items.
+func getEnvAsList(key string) []string {
+    var list []string
+    for _, item := range strings.Split(os.Getenv(key), ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            list = append(list, item)
+        }
+    }
+    return list
+}
+
+// getEnvAsListOr is getEnvAsList with defaults for an unset or empty variable.
+func getEnvAsListOr(key string, defaultValue ...string) []string {
+    if list := getEnvAsList(key); len(list) > 0 {
+        return list
+    }
+    return defaultValue
+}
+
+// getEnvAsMap reads "key=value" pairs separated by commas.
+func getEnvAsMap(key string) map[string]string {
+    values := make(map[string]string)
+    for _, item := range getEnvAsList(key) {
+        if k, v, found := strings.Cut(item, "="); found {
+            values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+        }
+    }
+    return values
+}
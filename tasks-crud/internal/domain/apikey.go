@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// AuthMethodAPIKey is the Principal.Method of requests made with an API key.
+const AuthMethodAPIKey = "api_key"
+
+const (
+    ScopeTasksRead  = "tasks:read"
+    ScopeTasksWrite = "tasks:write"
+)
+
+// APIKey is a long-lived credential for scripts and CI jobs. It acts as
+// its owner, limited to its scopes. Only the SHA-256 hash of the key is
+// stored; Key is filled in once, in the response to its creation.
+type APIKey struct {
+    ID         int        `json:"id"`
+    Name       string     `json:"name"`
+    Prefix     string     `json:"prefix"`
+    Key        string     `json:"key,omitempty"`
+    KeyHash    string     `json:"-"`
+    Scopes     []string   `json:"scopes"`
+    OwnerID    string     `json:"owner_id"`
+    TenantID   string     `json:"tenant_id,omitempty"`
+    Role       Role       `json:"role,omitempty"`
+    CreatedAt  time.Time  `json:"created_at"`
+    ExpiresAt  *time.Time `json:"expires_at"`
+    LastUsedAt *time.Time `json:"last_used_at"`
+    RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the key can be used at now.
+func (k *APIKey) Active(now time.Time) bool {
+    return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+type CreateAPIKeyRequest struct {
+    Name      string     `json:"name"`
+    Scopes    []string   `json:"scopes"`
+    ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+const (
+    BulkOpCreate = "create"
+    BulkOpUpdate = "update"
+    BulkOpDelete = "delete"
+)
+
+const (
+    BulkStatusSucceeded  = "succeeded"
+    BulkStatusFailed     = "failed"
+    BulkStatusRolledBack = "rolled_back"
+    BulkStatusSkipped    = "skipped"
+)
+
+type BulkOperation struct {
+    Op        string     `json:"op"`
+    ID        int        `json:"id,omitempty"`
+    Title     *string    `json:"title,omitempty"`
+    Completed *bool      `json:"completed,omitempty"`
+    Priority  *int       `json:"priority,omitempty"`
+    Tags      []string   `json:"tags,omitempty"`
+    DueDate   *time.Time `json:"due_date,omitempty"`
+}
+
+type BulkRequest struct {
+    Atomic     bool            `json:"atomic"`
+    Operations []BulkOperation `json:"operations"`
+}
+
+type BulkResult struct {
+    Index  int    `json:"index"`
+    Op     string `json:"op"`
+    Status string `json:"status"`
+    Task   *Task  `json:"task,omitempty"`
+    Error  string `json:"error,omitempty"`
+}
+
+type BulkResponse struct {
+    Atomic    bool         `json:"atomic"`
+    Succeeded int          `json:"succeeded"`
+    Failed    int          `json:"failed"`
+    Results   []BulkResult `json:"results"`
+}
\ No newline at end of file
@@ -0,0 +1,38 @@
+package domain
+
+import "errors"
+
+// ErrNotFound and ErrAlreadyExists are wrapped by the errors of every
+// repository and service, so callers can tell them apart with errors.Is.
+// They read "not found" and "already exists", so wrapping them with %w at
+// the end of a message keeps it as before, e.g. "task with id 1 not found".
+var (
+    ErrNotFound      = errors.New("not found")
+    ErrAlreadyExists = errors.New("already exists")
+)
+
+// ErrInternal marks errors caused by the server rather than the request,
+// see Internal.
+var ErrInternal = errors.New("internal error")
+
+type internalError struct {
+    err error
+}
+
+// Internal marks err as a server failure without changing its message.
+// errors.Is still sees what err wraps, so a wrapped ErrNotFound is reported
+// as such.
+func Internal(err error) error {
+    if err == nil {
+        return nil
+    }
+    return &internalError{err: err}
+}
+
+func (e *internalError) Error() string {
+    return e.err.Error()
+}
+
+func (e *internalError) Unwrap() []error {
+    return []error{e.err, ErrInternal}
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+const (
+    TaskEventCreated = "created"
+    TaskEventUpdated = "updated"
+    TaskEventDeleted = "deleted"
+)
+
+type TaskEvent struct {
+    Type     string    `json:"type"`
+    TaskID   int       `json:"task_id"`
+    Revision int64     `json:"revision"`
+    Task     *Task     `json:"task,omitempty"`
+    At       time.Time `json:"at"`
+}
\ No newline at end of file
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// CalendarFeed is a secret iCalendar subscription URL. Only the SHA-256
+// hash of its token is stored, so the URL is returned once, on creation.
+type CalendarFeed struct {
+    ID         int        `json:"id"`
+    Name       string     `json:"name"`
+    Query      string     `json:"query"`
+    OwnerID    string     `json:"owner_id,omitempty"`
+    TokenHash  string     `json:"-"`
+    URL        string     `json:"url,omitempty"`
+    CreatedAt  time.Time  `json:"created_at"`
+    LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+type CreateCalendarFeedRequest struct {
+    Name  string `json:"name"`
+    Query string `json:"query"`
+}
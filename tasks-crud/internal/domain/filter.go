@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+    FilterFieldText     = "text"
+    FilterFieldStatus   = "status"
+    FilterFieldTag      = "tag"
+    FilterFieldPriority = "priority"
+    FilterFieldDue      = "due"
+    FilterFieldCreated  = "created"
+    FilterFieldUpdated  = "updated"
+    FilterFieldTitle    = "title"
+    FilterFieldID       = "id"
+)
+
+const (
+    FilterOpEq       = "eq"
+    FilterOpLt       = "lt"
+    FilterOpLte      = "lte"
+    FilterOpGt       = "gt"
+    FilterOpGte      = "gte"
+    FilterOpContains = "contains"
+    FilterOpExists   = "exists"
+    FilterOpBetween  = "between"
+)
+
+const (
+    StatusOpen    = "open"
+    StatusDone    = "done"
+    StatusOverdue = "overdue"
+)
+
+type FilterCondition struct {
+    Field  string    `json:"field"`
+    Op     string    `json:"op"`
+    Values []string  `json:"values,omitempty"`
+    Number int       `json:"number,omitempty"`
+    Time   time.Time `json:"time,omitempty"`
+    Until  time.Time `json:"until,omitempty"`
+    Negate bool      `json:"negate,omitempty"`
+}
+
+type SortOrder struct {
+    Field      string `json:"field"`
+    Descending bool   `json:"descending"`
+}
+
+type TaskFilter struct {
+    Conditions []FilterCondition `json:"conditions"`
+    Sort       []SortOrder       `json:"sort"`
+    Now        time.Time         `json:"now"`
+}
+
+func (f TaskFilter) Matches(task Task) bool {
+    for _, condition := range f.Conditions {
+        if condition.matches(task, f.Now) == condition.Negate {
+            return false
+        }
+    }
+    return true
+}
+
+func (f TaskFilter) SortTasks(tasks []Task) {
+    sort.SliceStable(tasks, func(i, j int) bool {
+        for _, order := range f.Sort {
+            cmp := compareTasks(tasks[i], tasks[j], order.Field)
+            if cmp == 0 {
+                continue
+            }
+            if order.Descending {
+                return cmp > 0
+            }
+            return cmp < 0
+        }
+        return tasks[i].ID < tasks[j].ID
+    })
+}
+
+func (c FilterCondition) matches(task Task, now time.Time) bool {
+    switch c.Field {
+    case FilterFieldText:
+        title := strings.ToLower(task.Title)
+        for _, value := range c.Values {
+            if strings.Contains(title, strings.ToLower(value)) {
+                return true
+            }
+        }
+        return false
+    case FilterFieldStatus:
+        for _, value := range c.Values {
+            switch value {
+            case StatusOpen:
+                if !task.Completed {
+                    return true
+                }
+            case StatusDone:
+                if task.Completed {
+                    return true
+                }
+            case StatusOverdue:
+                if !task.Completed && task.DueDate != nil && task.DueDate.Before(now) {
+                    return true
+                }
+            }
+        }
+        return false
+    case FilterFieldTag:
+        if c.Op == FilterOpExists {
+            return len(task.Tags) > 0
+        }
+        for _, value := range c.Values {
+            for _, tag := range task.Tags {
+                if tag == value {
+                    return true
+                }
+            }
+        }
+        return false
+    case FilterFieldPriority:
+        return compareInts(task.Priority, c.Number, c.Op)
+    case FilterFieldDue:
+        if c.Op == FilterOpExists {
+            return task.DueDate != nil
+        }
+        return task.DueDate != nil && c.matchesTime(*task.DueDate)
+    case FilterFieldCreated:
+        return c.matchesTime(task.CreatedAt)
+    case FilterFieldUpdated:
+        return c.matchesTime(task.UpdatedAt)
+    }
+    
+    return false
+}
+
+func (c FilterCondition) matchesTime(value time.Time) bool {
+    if c.Op == FilterOpBetween {
+        return !value.Before(c.Time) && value.Before(c.Until)
+    }
+    return compareTimes(value, c.Time, c.Op)
+}
+
+func compareInts(value, target int, op string) bool {
+    switch op {
+    case FilterOpLt:
+        return value < target
+    case FilterOpLte:
+        return value <= target
+    case FilterOpGt:
+        return value > target
+    case FilterOpGte:
+        return value >= target
+    }
+    return value == target
+}
+
+func compareTimes(value, target time.Time, op string) bool {
+    switch op {
+    case FilterOpLt:
+        return value.Before(target)
+    case FilterOpLte:
+        return !value.After(target)
+    case FilterOpGt:
+        return value.After(target)
+    case FilterOpGte:
+        return !value.Before(target)
+    }
+    return value.Equal(target)
+}
+
+func compareTasks(a, b Task, field string) int {
+    switch field {
+    case FilterFieldPriority:
+        return a.Priority - b.Priority
+    case FilterFieldDue:
+        switch {
+        case a.DueDate == nil && b.DueDate == nil:
+            return 0
+        case a.DueDate == nil:
+            return 1
+        case b.DueDate == nil:
+            return -1
+        }
+        return a.DueDate.Compare(*b.DueDate)
+    case FilterFieldCreated:
+        return a.CreatedAt.Compare(b.CreatedAt)
+    case FilterFieldUpdated:
+        return a.UpdatedAt.Compare(b.UpdatedAt)
+    case FilterFieldTitle:
+        return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+    case FilterFieldID:
+        return a.ID - b.ID
+    }
+    return 0
+}
\ No newline at end of file
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// AuthMethodJWT is the Method of callers authenticated by a bearer token.
+const AuthMethodJWT = "jwt"
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+    Subject   string    `json:"subject"`
+    Issuer    string    `json:"issuer,omitempty"`
+    Scopes    []string  `json:"scopes,omitempty"`
+    Method    string    `json:"method"`
+    SessionID string    `json:"session_id,omitempty"`
+    TenantID  string    `json:"tenant_id,omitempty"`
+    KeyID     int       `json:"key_id,omitempty"`
+    // Role, when set, is the most the caller may do on any task, even
+    // their own; it comes from the groups of single sign-on users.
+    Role      Role      `json:"role,omitempty"`
+    ExpiresAt time.Time `json:"expires_at,omitempty"`
+    // External is set for tokens from an issuer other than this server,
+    // whose subjects may coincide with the IDs of local accounts.
+    External  bool      `json:"external,omitempty"`
+}
+
+// Owner is the key the caller's tasks, shares, filters and API keys are
+// stored under. Subjects are only unique per issuer, so for external
+// tokens the (escaped) issuer comes first; local accounts keep their ID,
+// and API keys and certificates already carry a distinct subject.
+func (p *Principal) Owner() string {
+    if !p.External {
+        return p.Subject
+    }
+    return "jwt:" + url.QueryEscape(p.Issuer) + ":" + p.Subject
+}
+
+// HasScope reports whether the principal was granted scope.
+func (p *Principal) HasScope(scope string) bool {
+    for _, granted := range p.Scopes {
+        if granted == scope {
+            return true
+        }
+    }
+    return false
+}
+
+type principalContextKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+    return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the caller stored by the auth middleware,
+// or nil for unauthenticated requests.
+func PrincipalFromContext(ctx context.Context) *Principal {
+    principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+    return principal
+}
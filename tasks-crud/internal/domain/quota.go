@@ -0,0 +1,20 @@
+package domain
+
+import "errors"
+
+// ErrQuotaExceeded is returned when creating something would go over a
+// storage quota.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// QuotaExceededCode is the "code" of quota errors in REST responses (409)
+// and GraphQL errors, which sets them apart from other conflicts.
+const QuotaExceededCode = "QUOTA_EXCEEDED"
+
+// Quotas limit how much may be stored. Zero means unlimited.
+type Quotas struct {
+    // MaxTasksPerUser counts the tasks a user owns.
+    MaxTasksPerUser int
+    // MaxTasksPerTenant counts all tasks of a tenant, or of the whole
+    // instance when multi-tenancy is off.
+    MaxTasksPerTenant int
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+type SavedFilter struct {
+    ID        int       `json:"id"`
+    Name      string    `json:"name"`
+    Query     string    `json:"query"`
+    OwnerID   string    `json:"owner_id,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateSavedFilterRequest struct {
+    Name  string `json:"name"`
+    Query string `json:"query"`
+}
+
+type UpdateSavedFilterRequest struct {
+    Name  *string `json:"name,omitempty"`
+    Query *string `json:"query,omitempty"`
+}
+
+type QueryErrorResponse struct {
+    Error    string `json:"error"`
+    Details  string `json:"details"`
+    Query    string `json:"query"`
+    Position int    `json:"position"`
+}
\ No newline at end of file
@@ -0,0 +1,13 @@
+package domain
+
+type SearchResult struct {
+    Task    Task    `json:"task"`
+    Score   float64 `json:"score"`
+    Snippet string  `json:"snippet"`
+}
+
+type SearchResponse struct {
+    Query   string         `json:"query"`
+    Total   int            `json:"total"`
+    Results []SearchResult `json:"results"`
+}
\ No newline at end of file
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type Role string
+
+const (
+    RoleOwner  Role = "owner"
+    RoleEditor Role = "editor"
+    RoleViewer Role = "viewer"
+)
+
+// ErrForbidden is returned when the caller can see a task but lacks the
+// role an operation needs. Tasks the caller can't see are reported as
+// not found instead, so their existence doesn't leak.
+var ErrForbidden = errors.New("permission denied")
+
+var roleRanks = map[Role]int{
+    RoleViewer: 1,
+    RoleEditor: 2,
+    RoleOwner:  3,
+}
+
+// Valid reports whether r is one of the known roles.
+func (r Role) Valid() bool {
+    return roleRanks[r] > 0
+}
+
+// Allows reports whether r includes everything required may do: owners
+// may also edit, editors may also view.
+func (r Role) Allows(required Role) bool {
+    return roleRanks[r] >= roleRanks[required]
+}
+
+// Limit returns the lower of r and limit. An empty limit leaves r as is,
+// and so does an empty r: no access stays no access.
+func (r Role) Limit(limit Role) Role {
+    if r == "" || limit == "" || roleRanks[r] <= roleRanks[limit] {
+        return r
+    }
+    return limit
+}
+
+// Share grants GranteeID a role on one task of OwnerID (TaskID) or on all
+// tasks of OwnerID in a project (Project). A task's project is its first
+// tag, the same grouping CalDAV uses for calendars.
+type Share struct {
+    ID        int       `json:"id"`
+    OwnerID   string    `json:"owner_id"`
+    GranteeID string    `json:"grantee_id"`
+    TaskID    *int      `json:"task_id,omitempty"`
+    Project   string    `json:"project,omitempty"`
+    Role      Role      `json:"role"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateShareRequest struct {
+    // GranteeID is the subject of the user to share with; Username may be
+    // given instead for local accounts.
+    GranteeID string `json:"grantee_id,omitempty"`
+    Username  string `json:"username,omitempty"`
+    Role      Role   `json:"role"`
+}
+
+// Project returns the project a task belongs to, or "" for untagged tasks.
+func (t Task) Project() string {
+    if len(t.Tags) == 0 {
+        return ""
+    }
+    return t.Tags[0]
+}
+
+// RoleOf returns the role user has on task given the shares granted to
+// user, or "" when the task is not visible to user at all.
+func RoleOf(user string, shares []Share, task Task) Role {
+    if task.OwnerID == user {
+        return RoleOwner
+    }
+    
+    var best Role
+    for _, share := range shares {
+        if share.GranteeID != user || share.OwnerID != task.OwnerID {
+            continue
+        }
+        matches := share.TaskID != nil && *share.TaskID == task.ID ||
+            share.Project != "" && share.Project == task.Project()
+        if matches && roleRanks[share.Role] > roleRanks[best] {
+            best = share.Role
+        }
+    }
+    
+    return best
+}
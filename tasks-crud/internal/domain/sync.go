@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+    SyncOpCreate = "create"
+    SyncOpUpdate = "update"
+    SyncOpDelete = "delete"
+)
+
+const (
+    SyncStatusApplied  = "applied"
+    SyncStatusConflict = "conflict"
+    SyncStatusRejected = "rejected"
+)
+
+// ErrSyncTokenExpired is returned for sync tokens older than the oldest
+// tombstone still kept: the client may have missed deletions, so it has to
+// sync again without a token and replace its copy.
+var ErrSyncTokenExpired = errors.New("sync token expired, do a full resync")
+
+// Tombstone reports a task the caller should drop: it was deleted or, if
+// Revoked is set, it is no longer shared with the caller.
+type Tombstone struct {
+    ID        int       `json:"id"`
+    Revision  int64     `json:"revision"`
+    DeletedAt time.Time `json:"deleted_at"`
+    Revoked   bool      `json:"revoked,omitempty"`
+    OwnerID   string    `json:"-"`
+}
+
+// Revocation records that a share was withdrawn, at the revision it took.
+type Revocation struct {
+    Share     Share
+    Revision  int64
+    RevokedAt time.Time
+}
+
+type SyncResponse struct {
+    Token   string      `json:"token"`
+    Tasks   []Task      `json:"tasks"`
+    Deleted []Tombstone `json:"deleted"`
+}
+
+type SyncChange struct {
+    Op           string  `json:"op"`
+    ID           int     `json:"id,omitempty"`
+    BaseRevision int64   `json:"base_revision,omitempty"`
+    Title        *string `json:"title,omitempty"`
+    Completed    *bool   `json:"completed,omitempty"`
+}
+
+type SyncPushRequest struct {
+    Changes []SyncChange `json:"changes"`
+}
+
+type SyncChangeResult struct {
+    Index   int    `json:"index"`
+    Status  string `json:"status"`
+    Task    *Task  `json:"task,omitempty"`
+    Deleted bool   `json:"deleted,omitempty"`
+    Error   string `json:"error,omitempty"`
+}
+
+type SyncPushResponse struct {
+    Token   string             `json:"token"`
+    Results []SyncChangeResult `json:"results"`
+}
\ No newline at end of file
@@ -2,24 +2,57 @@ package domain
 
 import "time"
 
+const (
+    PriorityNone   = 0
+    PriorityLow    = 1
+    PriorityMedium = 2
+    PriorityHigh   = 3
+)
+
 type Task struct {
-    ID        int       `json:"id"`
-    Title     string    `json:"title"`
-    Completed bool      `json:"completed"`
-    CreatedAt time.Time `json:"created_at"`
-    UpdatedAt time.Time `json:"updated_at"`
+    ID          int        `json:"id"`
+    Title       string     `json:"title"`
+    Completed   bool       `json:"completed"`
+    Priority    int        `json:"priority"`
+    Tags        []string   `json:"tags"`
+    DueDate     *time.Time `json:"due_date"`
+    ParentID    *int       `json:"parent_id"`
+    CreatedAt   time.Time  `json:"created_at"`
+    UpdatedAt   time.Time  `json:"updated_at"`
+    // CompletedAt is when the task was last completed, nil while it's open.
+    CompletedAt *time.Time `json:"completed_at"`
+    Revision    int64      `json:"revision"`
+    OwnerID     string     `json:"owner_id,omitempty"`
 }
 
 type CreateTaskRequest struct {
-    Title string `json:"title" binding:"required"`
+    Title    string     `json:"title" binding:"required"`
+    Priority int        `json:"priority,omitempty"`
+    Tags     []string   `json:"tags,omitempty"`
+    DueDate  *time.Time `json:"due_date,omitempty"`
+    ParentID *int       `json:"parent_id,omitempty"`
 }
 
 type UpdateTaskRequest struct {
-    Title     *string `json:"title,omitempty"`    
-    Completed *bool   `json:"completed,omitempty"`  
+    Title     *string    `json:"title,omitempty"`    
+    Completed *bool      `json:"completed,omitempty"`  
+    Priority  *int       `json:"priority,omitempty"`
+    Tags      []string   `json:"tags,omitempty"`
+    DueDate   *time.Time `json:"due_date,omitempty"`
+    ParentID  *int       `json:"parent_id,omitempty"`
+}
+
+type ReplaceTaskRequest struct {
+    Title     string     `json:"title"`
+    Completed bool       `json:"completed"`
+    Priority  int        `json:"priority"`
+    Tags      []string   `json:"tags"`
+    DueDate   *time.Time `json:"due_date"`
+    ParentID  *int       `json:"parent_id"`
 }
 
 type ErrorResponse struct {
     Error   string `json:"error"`
     Details string `json:"details,omitempty"`
+    Code    string `json:"code,omitempty"`
 }
\ No newline at end of file
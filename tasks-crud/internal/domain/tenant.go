@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+    TenantStatusActive    = "active"
+    TenantStatusSuspended = "suspended"
+)
+
+// Tenant is a team hosted on a shared instance. Each tenant has its own
+// tasks, with their own ID space, and its own shares, filters and feeds.
+type Tenant struct {
+    ID          string     `json:"id"`
+    Name        string     `json:"name"`
+    Status      string     `json:"status"`
+    CreatedAt   time.Time  `json:"created_at"`
+    SuspendedAt *time.Time `json:"suspended_at,omitempty"`
+}
+
+type CreateTenantRequest struct {
+    ID   string `json:"id"`
+    Name string `json:"name"`
+}
+
+type tenantContextKey struct{}
+
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+    return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant resolved for a request, or "" when
+// multi-tenancy is off.
+func TenantFromContext(ctx context.Context) string {
+    tenantID, _ := ctx.Value(tenantContextKey{}).(string)
+    return tenantID
+}
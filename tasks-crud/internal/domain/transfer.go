@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+const (
+    TransferFormatCSV      = "csv"
+    TransferFormatJSON     = "json"
+    TransferFormatMarkdown = "markdown"
+    TransferFormatTodoTxt  = "todotxt"
+    TransferFormatICS      = "ics"
+)
+
+const (
+    ImportStatusCreated  = "created"
+    ImportStatusSkipped  = "skipped"
+    ImportStatusRejected = "rejected"
+)
+
+// ImportRecord is one task read from an import file. Err is set when the
+// row itself could not be parsed. CreatedAt and CompletedAt, when the
+// format has them, replace the times the import itself would give.
+type ImportRecord struct {
+    Row         int
+    Task        CreateTaskRequest
+    Completed   bool
+    CreatedAt   *time.Time
+    CompletedAt *time.Time
+    Err         error
+}
+
+type ImportResult struct {
+    Row    int    `json:"row"`
+    Title  string `json:"title"`
+    Status string `json:"status"`
+    Task   *Task  `json:"task,omitempty"`
+    Error  string `json:"error,omitempty"`
+}
+
+type ImportResponse struct {
+    DryRun   bool           `json:"dry_run"`
+    Created  int            `json:"created"`
+    Skipped  int            `json:"skipped"`
+    Rejected int            `json:"rejected"`
+    Results  []ImportResult `json:"results"`
+}
@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+const (
+    PasswordHashArgon2id = "argon2id"
+    PasswordHashBcrypt   = "bcrypt"
+)
+
+// User is a local account. Its numeric ID, as a string, is the subject of
+// the access tokens it is issued and the owner of its tasks. Usernames are
+// unique within a tenant.
+//
+// Accounts created by single sign-on have a Provider (the issuer) and the
+// subject there as ExternalID, no password and a Role taken from their
+// groups at every login.
+type User struct {
+    ID           int       `json:"id"`
+    Username     string    `json:"username"`
+    TenantID     string    `json:"tenant_id,omitempty"`
+    Email        string    `json:"email,omitempty"`
+    Provider     string    `json:"provider,omitempty"`
+    ExternalID   string    `json:"-"`
+    Role         Role      `json:"role,omitempty"`
+    PasswordHash string    `json:"-"`
+    CreatedAt    time.Time `json:"created_at"`
+    UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Session is one login. Its refresh token is rotated on every use; only
+// the hash of the current one is kept.
+type Session struct {
+    ID          string     `json:"id"`
+    UserID      int        `json:"user_id"`
+    RefreshHash string     `json:"-"`
+    CreatedAt   time.Time  `json:"created_at"`
+    ExpiresAt   time.Time  `json:"expires_at"`
+    RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the session can still be used at now.
+func (s *Session) Active(now time.Time) bool {
+    return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+type RegisterRequest struct {
+    Username string `json:"username"`
+    Password string `json:"password"`
+}
+
+type LoginRequest struct {
+    Username string `json:"username"`
+    Password string `json:"password"`
+}
+
+type RefreshRequest struct {
+    RefreshToken string `json:"refresh_token"`
+}
+
+type ChangePasswordRequest struct {
+    CurrentPassword string `json:"current_password"`
+    NewPassword     string `json:"new_password"`
+}
+
+// TokenResponse follows the shape of an OAuth 2.0 token response
+// (RFC 6749, section 5.1).
+type TokenResponse struct {
+    AccessToken  string `json:"access_token"`
+    TokenType    string `json:"token_type"`
+    ExpiresIn    int    `json:"expires_in"`
+    RefreshToken string `json:"refresh_token"`
+    User         *User  `json:"user"`
+}
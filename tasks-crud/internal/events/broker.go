@@ -0,0 +1,65 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"tasks-crud/internal/domain"
+)
+
+const subscriberBuffer = 64
+
+type Broker struct {
+    subscribers map[int]chan domain.TaskEvent
+    nextID      int
+    mu          sync.Mutex
+}
+
+func NewBroker() *Broker {
+    return &Broker{
+        subscribers: make(map[int]chan domain.TaskEvent),
+    }
+}
+
+func (b *Broker) Subscribe(ctx context.Context) <-chan domain.TaskEvent {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    
+    id := b.nextID
+    b.nextID++
+    
+    ch := make(chan domain.TaskEvent, subscriberBuffer)
+    b.subscribers[id] = ch
+    
+    go func() {
+        <-ctx.Done()
+        
+        b.mu.Lock()
+        defer b.mu.Unlock()
+        
+        if _, exists := b.subscribers[id]; exists {
+            delete(b.subscribers, id)
+            close(ch)
+        }
+    }()
+    
+    return ch
+}
+
+// Publish delivers event to every subscriber without blocking. A subscriber
+// whose buffer is full has fallen behind and would miss events, so it is
+// dropped and its channel closed instead; it has to resync and subscribe
+// again.
+func (b *Broker) Publish(event domain.TaskEvent) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    
+    for id, ch := range b.subscribers {
+        select {
+        case ch <- event:
+        default:
+            delete(b.subscribers, id)
+            close(ch)
+        }
+    }
+}
@@ -0,0 +1,35 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"tasks-crud/internal/domain"
+)
+
+func TestPublishClosesSubscribersThatFallBehind(t *testing.T) {
+    broker := NewBroker()
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    
+    slow := broker.Subscribe(ctx)
+    for i := 0; i <= subscriberBuffer; i++ {
+        broker.Publish(domain.TaskEvent{TaskID: i})
+    }
+    
+    received := 0
+    for range slow {
+        received++
+    }
+    if received != subscriberBuffer {
+        t.Errorf("received %d events before the channel closed, want %d", received, subscriberBuffer)
+    }
+    
+    // The context ending later must not close the channel again.
+    cancel()
+    fast := broker.Subscribe(context.Background())
+    broker.Publish(domain.TaskEvent{TaskID: 1})
+    if event := <-fast; event.TaskID != 1 {
+        t.Errorf("got event for task %d, want 1", event.TaskID)
+    }
+}
@@ -0,0 +1,88 @@
+package gql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/service"
+)
+
+type subtaskLoaderKey struct{}
+
+// subtaskLoader lists the tasks once per request and serves the subtasks of
+// every task in the result from that listing, instead of listing all tasks
+// again for each of them.
+type subtaskLoader struct {
+    once     sync.Once
+    byParent map[int][]domain.Task
+    err      error
+}
+
+func (l *subtaskLoader) load(taskService *service.TaskService, id int) ([]domain.Task, error) {
+    l.once.Do(func() {
+        l.byParent, l.err = taskService.GetSubtasksByParent()
+    })
+    if l.err != nil {
+        return nil, l.err
+    }
+    
+    subtasks := l.byParent[id]
+    if subtasks == nil {
+        subtasks = []domain.Task{}
+    }
+    return subtasks, nil
+}
+
+// subtasksFor returns the request's loader for queries. Mutations change
+// tasks between fields, so they, like subscription events, resolve
+// subtasks directly.
+func subtasksFor(p graphql.ResolveParams) *subtaskLoader {
+    operation, ok := p.Info.Operation.(*ast.OperationDefinition)
+    if !ok || operation.Operation != ast.OperationTypeQuery {
+        return nil
+    }
+    
+    loader, _ := p.Context.Value(subtaskLoaderKey{}).(*subtaskLoader)
+    return loader
+}
+
+// batching is a schema extension that gives each executed request its own
+// subtaskLoader.
+type batching struct{}
+
+func (batching) Init(ctx context.Context, _ *graphql.Params) context.Context {
+    return context.WithValue(ctx, subtaskLoaderKey{}, &subtaskLoader{})
+}
+
+func (batching) Name() string {
+    return "batching"
+}
+
+func (batching) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+    return ctx, func(error) {}
+}
+
+func (batching) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+    return ctx, func([]gqlerrors.FormattedError) {}
+}
+
+func (batching) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+    return ctx, func(*graphql.Result) {}
+}
+
+func (batching) ResolveFieldDidStart(ctx context.Context, _ *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+    return ctx, func(interface{}, error) {}
+}
+
+func (batching) HasResult() bool {
+    return false
+}
+
+func (batching) GetResult(context.Context) interface{} {
+    return nil
+}
@@ -0,0 +1,56 @@
+package gql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/repository"
+	"tasks-crud/internal/service"
+)
+
+type countingRepository struct {
+    repository.TaskRepository
+    lists int
+}
+
+func (r *countingRepository) List(filter domain.TaskFilter) ([]domain.Task, error) {
+    r.lists++
+    return r.TaskRepository.List(filter)
+}
+
+func TestSubtasksAreListedOncePerQuery(t *testing.T) {
+    repo := &countingRepository{TaskRepository: repository.NewEmptyInMemoryTaskRepository()}
+    taskService := service.NewTaskService(repo)
+    for i := 0; i < 3; i++ {
+        parent, err := taskService.CreateTask(domain.CreateTaskRequest{Title: "parent " + string(rune('a'+i))})
+        if err != nil {
+            t.Fatal(err)
+        }
+        if _, err := taskService.CreateTask(domain.CreateTaskRequest{Title: "child " + string(rune('a'+i)), ParentID: &parent.ID}); err != nil {
+            t.Fatal(err)
+        }
+    }
+    
+    schema, err := NewSchema(taskService)
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    repo.lists = 0
+    result := graphql.Do(graphql.Params{
+        Schema:        schema,
+        RequestString: `{ tasks(first: 10) { edges { node { id subtasks { id subtasks { id } } } } } }`,
+        Context:       context.Background(),
+    })
+    if len(result.Errors) > 0 {
+        t.Fatal(result.Errors)
+    }
+    
+    // One listing for the connection, one for every subtasks field.
+    if repo.lists != 2 {
+        t.Errorf("query listed tasks %d times, want 2", repo.lists)
+    }
+}
@@ -0,0 +1,104 @@
+package gql
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+const (
+    defaultPageSize = 50
+    maxPageSize     = 100
+    cursorPrefix    = "task:"
+)
+
+func (b *schemaBuilder) resolveTasks(p graphql.ResolveParams) (interface{}, error) {
+    q, _ := p.Args["query"].(string)
+    tasks, err := b.service.WithContext(p.Context).ListTasks(q)
+    if err != nil {
+        return nil, wrapError(err)
+    }
+    
+    start, end := 0, len(tasks)
+    
+    if after, ok := p.Args["after"].(string); ok {
+        offset, err := decodeCursor(after)
+        if err != nil {
+            return nil, wrapError(err)
+        }
+        start = max(start, offset+1)
+    }
+    
+    if before, ok := p.Args["before"].(string); ok {
+        offset, err := decodeCursor(before)
+        if err != nil {
+            return nil, wrapError(err)
+        }
+        end = min(end, offset)
+    }
+    
+    first, hasFirst := p.Args["first"].(int)
+    last, hasLast := p.Args["last"].(int)
+    if !hasFirst && !hasLast {
+        first, hasFirst = defaultPageSize, true
+    }
+    if (hasFirst && (first < 0 || first > maxPageSize)) || (hasLast && (last < 0 || last > maxPageSize)) {
+        return nil, wrapError(fmt.Errorf("first and last must be between 0 and %d", maxPageSize))
+    }
+    
+    if hasFirst {
+        end = min(end, start+first)
+    }
+    if hasLast {
+        start = max(start, end-last)
+    }
+    if start > end {
+        start = end
+    }
+    
+    edges := make([]map[string]interface{}, 0, end-start)
+    for i := start; i < end; i++ {
+        edges = append(edges, map[string]interface{}{
+            "cursor": encodeCursor(i),
+            "node":   tasks[i],
+        })
+    }
+    
+    pageInfo := map[string]interface{}{
+        "hasNextPage":     end < len(tasks),
+        "hasPreviousPage": start > 0,
+        "startCursor":     nil,
+        "endCursor":       nil,
+    }
+    if len(edges) > 0 {
+        pageInfo["startCursor"] = edges[0]["cursor"]
+        pageInfo["endCursor"] = edges[len(edges)-1]["cursor"]
+    }
+    
+    return map[string]interface{}{
+        "edges":      edges,
+        "pageInfo":   pageInfo,
+        "totalCount": len(tasks),
+    }, nil
+}
+
+func encodeCursor(offset int) string {
+    return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+    data, err := base64.StdEncoding.DecodeString(cursor)
+    if err != nil || !strings.HasPrefix(string(data), cursorPrefix) {
+        return 0, fmt.Errorf("invalid cursor '%s'", cursor)
+    }
+    
+    offset, err := strconv.Atoi(strings.TrimPrefix(string(data), cursorPrefix))
+    if err != nil || offset < 0 {
+        return 0, fmt.Errorf("invalid cursor '%s'", cursor)
+    }
+    
+    return offset, nil
+}
\ No newline at end of file
@@ -0,0 +1,56 @@
+package gql
+
+import (
+	"errors"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/query"
+)
+
+const (
+    CodeBadRequest   = "BAD_REQUEST"
+    CodeNotFound     = "NOT_FOUND"
+    CodeForbidden    = "FORBIDDEN"
+    CodeQuota        = domain.QuotaExceededCode
+    CodeInvalidQuery = "INVALID_QUERY"
+)
+
+type Error struct {
+    err        error
+    extensions map[string]interface{}
+}
+
+func (e *Error) Error() string {
+    return e.err.Error()
+}
+
+func (e *Error) Unwrap() error {
+    return e.err
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+    return e.extensions
+}
+
+func wrapError(err error) error {
+    if err == nil {
+        return nil
+    }
+    
+    var parseErr *query.ParseError
+    switch {
+    case errors.As(err, &parseErr):
+        return &Error{err: err, extensions: map[string]interface{}{
+            "code":     CodeInvalidQuery,
+            "position": parseErr.Pos,
+        }}
+    case errors.Is(err, domain.ErrForbidden):
+        return &Error{err: err, extensions: map[string]interface{}{"code": CodeForbidden}}
+    case errors.Is(err, domain.ErrQuotaExceeded):
+        return &Error{err: err, extensions: map[string]interface{}{"code": CodeQuota}}
+    case errors.Is(err, domain.ErrNotFound):
+        return &Error{err: err, extensions: map[string]interface{}{"code": CodeNotFound}}
+    }
+    
+    return &Error{err: err, extensions: map[string]interface{}{"code": CodeBadRequest}}
+}
\ No newline at end of file
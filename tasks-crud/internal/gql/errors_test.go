@@ -0,0 +1,26 @@
+package gql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"tasks-crud/internal/domain"
+)
+
+func TestErrorCodesComeFromSentinelErrors(t *testing.T) {
+    for _, tc := range []struct {
+        err  error
+        code string
+    }{
+        {fmt.Errorf("task with id 1 %w", domain.ErrNotFound), CodeNotFound},
+        {domain.Internal(fmt.Errorf("failed to get task: %w", domain.ErrNotFound)), CodeNotFound},
+        {errors.New("parent task not found or not yours"), CodeBadRequest},
+        {fmt.Errorf("create: %w", domain.ErrQuotaExceeded), CodeQuota},
+    } {
+        got := wrapError(tc.err).(*Error).Extensions()["code"]
+        if got != tc.code {
+            t.Errorf("%q got code %v, want %s", tc.err, got, tc.code)
+        }
+    }
+}
@@ -0,0 +1,434 @@
+package gql
+
+import (
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/service"
+)
+
+type schemaBuilder struct {
+    service *service.TaskService
+
+    taskType      *graphql.Object
+    taskEventType *graphql.Object
+    eventTypeEnum *graphql.Enum
+}
+
+func NewSchema(taskService *service.TaskService) (graphql.Schema, error) {
+    b := &schemaBuilder{service: taskService}
+
+    b.eventTypeEnum = graphql.NewEnum(graphql.EnumConfig{
+        Name: "TaskEventType",
+        Values: graphql.EnumValueConfigMap{
+            "CREATED": &graphql.EnumValueConfig{Value: domain.TaskEventCreated},
+            "UPDATED": &graphql.EnumValueConfig{Value: domain.TaskEventUpdated},
+            "DELETED": &graphql.EnumValueConfig{Value: domain.TaskEventDeleted},
+        },
+    })
+
+    b.taskType = graphql.NewObject(graphql.ObjectConfig{
+        Name: "Task",
+        Fields: graphql.FieldsThunk(func() graphql.Fields {
+            return b.taskFields()
+        }),
+    })
+
+    b.taskEventType = graphql.NewObject(graphql.ObjectConfig{
+        Name: "TaskEvent",
+        Fields: graphql.Fields{
+            "type": &graphql.Field{
+                Type:    graphql.NewNonNull(b.eventTypeEnum),
+                Resolve: eventField(func(e domain.TaskEvent) interface{} { return e.Type }),
+            },
+            "taskId": &graphql.Field{
+                Type:    graphql.NewNonNull(graphql.Int),
+                Resolve: eventField(func(e domain.TaskEvent) interface{} { return e.TaskID }),
+            },
+            "revision": &graphql.Field{
+                Type:    graphql.NewNonNull(graphql.Int),
+                Resolve: eventField(func(e domain.TaskEvent) interface{} { return e.Revision }),
+            },
+            "at": &graphql.Field{
+                Type:    graphql.NewNonNull(graphql.DateTime),
+                Resolve: eventField(func(e domain.TaskEvent) interface{} { return e.At }),
+            },
+            "task": &graphql.Field{
+                Type:    b.taskType,
+                Resolve: eventField(func(e domain.TaskEvent) interface{} { return e.Task }),
+            },
+        },
+    })
+
+    return graphql.NewSchema(graphql.SchemaConfig{
+        Query:        b.queryType(),
+        Mutation:     b.mutationType(),
+        Subscription: b.subscriptionType(),
+        Extensions:   []graphql.Extension{batching{}},
+    })
+}
+
+func (b *schemaBuilder) taskFields() graphql.Fields {
+    return graphql.Fields{
+        "id": &graphql.Field{
+            Type:    graphql.NewNonNull(graphql.Int),
+            Resolve: taskField(func(t domain.Task) interface{} { return t.ID }),
+        },
+        "title": &graphql.Field{
+            Type:    graphql.NewNonNull(graphql.String),
+            Resolve: taskField(func(t domain.Task) interface{} { return t.Title }),
+        },
+        "completed": &graphql.Field{
+            Type:    graphql.NewNonNull(graphql.Boolean),
+            Resolve: taskField(func(t domain.Task) interface{} { return t.Completed }),
+        },
+        "priority": &graphql.Field{
+            Type:    graphql.NewNonNull(graphql.Int),
+            Resolve: taskField(func(t domain.Task) interface{} { return t.Priority }),
+        },
+        "tags": &graphql.Field{
+            Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+            Resolve: taskField(func(t domain.Task) interface{} {
+                if t.Tags == nil {
+                    return []string{}
+                }
+                return t.Tags
+            }),
+        },
+        "dueDate": &graphql.Field{
+            Type:    graphql.DateTime,
+            Resolve: taskField(func(t domain.Task) interface{} { return t.DueDate }),
+        },
+        "createdAt": &graphql.Field{
+            Type:    graphql.NewNonNull(graphql.DateTime),
+            Resolve: taskField(func(t domain.Task) interface{} { return t.CreatedAt }),
+        },
+        "updatedAt": &graphql.Field{
+            Type:    graphql.NewNonNull(graphql.DateTime),
+            Resolve: taskField(func(t domain.Task) interface{} { return t.UpdatedAt }),
+        },
+        "revision": &graphql.Field{
+            Type:    graphql.NewNonNull(graphql.Int),
+            Resolve: taskField(func(t domain.Task) interface{} { return t.Revision }),
+        },
+        "parentId": &graphql.Field{
+            Type:    graphql.Int,
+            Resolve: taskField(func(t domain.Task) interface{} { return t.ParentID }),
+        },
+        "parent": &graphql.Field{
+            Type: b.taskType,
+            Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                task, ok := asTask(p.Source)
+                if !ok || task.ParentID == nil {
+                    return nil, nil
+                }
+                parent, err := b.service.WithContext(p.Context).GetTaskByID(*task.ParentID)
+                if err != nil {
+                    return nil, nil
+                }
+                return parent, nil
+            },
+        },
+        "subtasks": &graphql.Field{
+            Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(b.taskType))),
+            Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                task, ok := asTask(p.Source)
+                if !ok {
+                    return nil, nil
+                }
+                if loader := subtasksFor(p); loader != nil {
+                    subtasks, err := loader.load(b.service.WithContext(p.Context), task.ID)
+                    return subtasks, wrapError(err)
+                }
+                subtasks, err := b.service.WithContext(p.Context).GetSubtasks(task.ID)
+                return subtasks, wrapError(err)
+            },
+        },
+        "history": &graphql.Field{
+            Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(b.taskEventType))),
+            Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                task, ok := asTask(p.Source)
+                if !ok {
+                    return nil, nil
+                }
+                history, err := b.service.WithContext(p.Context).GetTaskHistory(task.ID)
+                return history, wrapError(err)
+            },
+        },
+    }
+}
+
+func (b *schemaBuilder) queryType() *graphql.Object {
+    pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "PageInfo",
+        Fields: graphql.Fields{
+            "hasNextPage":     &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+            "hasPreviousPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+            "startCursor":     &graphql.Field{Type: graphql.String},
+            "endCursor":       &graphql.Field{Type: graphql.String},
+        },
+    })
+
+    edgeType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "TaskEdge",
+        Fields: graphql.Fields{
+            "cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+            "node":   &graphql.Field{Type: graphql.NewNonNull(b.taskType)},
+        },
+    })
+
+    connectionType := graphql.NewObject(graphql.ObjectConfig{
+        Name: "TaskConnection",
+        Fields: graphql.Fields{
+            "edges":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType)))},
+            "pageInfo":   &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+            "totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+        },
+    })
+
+    return graphql.NewObject(graphql.ObjectConfig{
+        Name: "Query",
+        Fields: graphql.Fields{
+            "task": &graphql.Field{
+                Type: b.taskType,
+                Args: graphql.FieldConfigArgument{
+                    "id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    task, err := b.service.WithContext(p.Context).GetTaskByID(p.Args["id"].(int))
+                    if err != nil {
+                        return nil, wrapError(err)
+                    }
+                    return task, nil
+                },
+            },
+            "tasks": &graphql.Field{
+                Type: graphql.NewNonNull(connectionType),
+                Args: graphql.FieldConfigArgument{
+                    "query":  &graphql.ArgumentConfig{Type: graphql.String, Description: "Filter in the task query language"},
+                    "first":  &graphql.ArgumentConfig{Type: graphql.Int},
+                    "after":  &graphql.ArgumentConfig{Type: graphql.String},
+                    "last":   &graphql.ArgumentConfig{Type: graphql.Int},
+                    "before": &graphql.ArgumentConfig{Type: graphql.String},
+                },
+                Resolve: b.resolveTasks,
+            },
+        },
+    })
+}
+
+func (b *schemaBuilder) mutationType() *graphql.Object {
+    createInput := graphql.NewInputObject(graphql.InputObjectConfig{
+        Name: "CreateTaskInput",
+        Fields: graphql.InputObjectConfigFieldMap{
+            "title":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+            "priority": &graphql.InputObjectFieldConfig{Type: graphql.Int},
+            "tags":     &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+            "dueDate":  &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+            "parentId": &graphql.InputObjectFieldConfig{Type: graphql.Int},
+        },
+    })
+
+    updateInput := graphql.NewInputObject(graphql.InputObjectConfig{
+        Name: "UpdateTaskInput",
+        Fields: graphql.InputObjectConfigFieldMap{
+            "title":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+            "completed": &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+            "priority":  &graphql.InputObjectFieldConfig{Type: graphql.Int},
+            "tags":      &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+            "dueDate":   &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+            "parentId":  &graphql.InputObjectFieldConfig{Type: graphql.Int},
+        },
+    })
+
+    return graphql.NewObject(graphql.ObjectConfig{
+        Name: "Mutation",
+        Fields: graphql.Fields{
+            "createTask": &graphql.Field{
+                Type: graphql.NewNonNull(b.taskType),
+                Args: graphql.FieldConfigArgument{
+                    "input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(createInput)},
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    input := p.Args["input"].(map[string]interface{})
+                    req := domain.CreateTaskRequest{
+                        Title:    input["title"].(string),
+                        Tags:     stringList(input["tags"]),
+                        DueDate:  timeArg(input["dueDate"]),
+                        ParentID: intArg(input["parentId"]),
+                    }
+                    if priority := intArg(input["priority"]); priority != nil {
+                        req.Priority = *priority
+                    }
+                    task, err := b.service.WithContext(p.Context).CreateTask(req)
+                    if err != nil {
+                        return nil, wrapError(err)
+                    }
+                    return task, nil
+                },
+            },
+            "updateTask": &graphql.Field{
+                Type: graphql.NewNonNull(b.taskType),
+                Args: graphql.FieldConfigArgument{
+                    "id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+                    "input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(updateInput)},
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    input := p.Args["input"].(map[string]interface{})
+                    req := domain.UpdateTaskRequest{
+                        Title:     stringArg(input["title"]),
+                        Completed: boolArg(input["completed"]),
+                        Priority:  intArg(input["priority"]),
+                        Tags:      stringList(input["tags"]),
+                        DueDate:   timeArg(input["dueDate"]),
+                        ParentID:  intArg(input["parentId"]),
+                    }
+                    task, err := b.service.WithContext(p.Context).UpdateTask(p.Args["id"].(int), req)
+                    if err != nil {
+                        return nil, wrapError(err)
+                    }
+                    return task, nil
+                },
+            },
+            "deleteTask": &graphql.Field{
+                Type: graphql.NewNonNull(graphql.Boolean),
+                Args: graphql.FieldConfigArgument{
+                    "id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+                },
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    if err := b.service.WithContext(p.Context).DeleteTask(p.Args["id"].(int)); err != nil {
+                        return nil, wrapError(err)
+                    }
+                    return true, nil
+                },
+            },
+        },
+    })
+}
+
+func (b *schemaBuilder) subscriptionType() *graphql.Object {
+    return graphql.NewObject(graphql.ObjectConfig{
+        Name: "Subscription",
+        Fields: graphql.Fields{
+            "taskChanged": &graphql.Field{
+                Type: graphql.NewNonNull(b.taskEventType),
+                Args: graphql.FieldConfigArgument{
+                    "types": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(b.eventTypeEnum))},
+                },
+                Subscribe: b.subscribeTaskChanged,
+                Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                    return p.Source, nil
+                },
+            },
+        },
+    })
+}
+
+func (b *schemaBuilder) subscribeTaskChanged(p graphql.ResolveParams) (interface{}, error) {
+    events, err := b.service.WithContext(p.Context).WatchTasks(p.Context)
+    if err != nil {
+        return nil, wrapError(err)
+    }
+
+    types := make(map[string]bool)
+    for _, value := range stringList(p.Args["types"]) {
+        types[value] = true
+    }
+
+    out := make(chan interface{})
+    go func() {
+        defer close(out)
+        for event := range events {
+            if len(types) > 0 && !types[event.Type] {
+                continue
+            }
+            select {
+            case out <- event:
+            case <-p.Context.Done():
+                return
+            }
+        }
+    }()
+
+    return out, nil
+}
+
+func taskField(get func(domain.Task) interface{}) graphql.FieldResolveFn {
+    return func(p graphql.ResolveParams) (interface{}, error) {
+        task, ok := asTask(p.Source)
+        if !ok {
+            return nil, nil
+        }
+        return get(task), nil
+    }
+}
+
+func eventField(get func(domain.TaskEvent) interface{}) graphql.FieldResolveFn {
+    return func(p graphql.ResolveParams) (interface{}, error) {
+        event, ok := p.Source.(domain.TaskEvent)
+        if !ok {
+            return nil, nil
+        }
+        return get(event), nil
+    }
+}
+
+func asTask(source interface{}) (domain.Task, bool) {
+    switch task := source.(type) {
+    case domain.Task:
+        return task, true
+    case *domain.Task:
+        if task != nil {
+            return *task, true
+        }
+    }
+    return domain.Task{}, false
+}
+
+func stringArg(value interface{}) *string {
+    if s, ok := value.(string); ok {
+        return &s
+    }
+    return nil
+}
+
+func boolArg(value interface{}) *bool {
+    if v, ok := value.(bool); ok {
+        return &v
+    }
+    return nil
+}
+
+func intArg(value interface{}) *int {
+    if v, ok := value.(int); ok {
+        return &v
+    }
+    return nil
+}
+
+func timeArg(value interface{}) *time.Time {
+    switch v := value.(type) {
+    case time.Time:
+        return &v
+    case *time.Time:
+        return v
+    }
+    return nil
+}
+
+func stringList(value interface{}) []string {
+    items, ok := value.([]interface{})
+    if !ok {
+        return nil
+    }
+
+    list := make([]string, 0, len(items))
+    for _, item := range items {
+        if s, ok := item.(string); ok {
+            list = append(list, s)
+        }
+    }
+    return list
+}
\ No newline at end of file
@@ -0,0 +1,116 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/middleware"
+)
+
+// reflectionPrefix is left open so that grpcurl can list services without
+// a token; calling them still requires one.
+const reflectionPrefix = "/grpc.reflection."
+
+// readMethods can be called with API keys that only have tasks:read.
+var readMethods = map[string]bool{
+    "GetTask":    true,
+    "ListTasks":  true,
+    "WatchTasks": true,
+}
+
+// WithAuth returns server options that require credentials in the
+// "authorization" metadata of every call and put the caller into the
+// context, like middleware.RequireAuth does for HTTP.
+func WithAuth(auth middleware.Authenticator) []grpc.ServerOption {
+    return []grpc.ServerOption{
+        grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+            ctx, err := authenticate(ctx, auth, info.FullMethod)
+            if err != nil {
+                return nil, err
+            }
+            return handler(ctx, req)
+        }),
+        grpc.ChainStreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+            ctx, err := authenticate(stream.Context(), auth, info.FullMethod)
+            if err != nil {
+                return err
+            }
+            return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+        }),
+    }
+}
+
+func authenticate(ctx context.Context, auth middleware.Authenticator, method string) (context.Context, error) {
+    if strings.HasPrefix(method, reflectionPrefix) {
+        return ctx, nil
+    }
+    
+    md, _ := metadata.FromIncomingContext(ctx)
+    values := md.Get("authorization")
+    if len(values) == 0 {
+        cert := verifiedCertificate(ctx)
+        certAuth, ok := auth.(middleware.CertificateAuthenticator)
+        if cert == nil || !ok {
+            return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+        }
+        principal, err := certAuth.AuthenticateCertificate(cert)
+        if err != nil {
+            return nil, status.Error(codes.Unauthenticated, err.Error())
+        }
+        return domain.WithPrincipal(ctx, principal), nil
+    }
+    
+    scheme, credentials, err := middleware.ParseAuthorization(values[0])
+    if err != nil {
+        return nil, status.Error(codes.Unauthenticated, err.Error())
+    }
+    
+    principal, err := auth.Authenticate(scheme, credentials)
+    if err != nil {
+        return nil, status.Error(codes.Unauthenticated, err.Error())
+    }
+    
+    if principal.Method == domain.AuthMethodAPIKey {
+        scope := domain.ScopeTasksWrite
+        if readMethods[method[strings.LastIndex(method, "/")+1:]] {
+            scope = domain.ScopeTasksRead
+        }
+        if !principal.HasScope(scope) {
+            return nil, status.Errorf(codes.PermissionDenied, "api key lacks scope %s", scope)
+        }
+    }
+    
+    return domain.WithPrincipal(ctx, principal), nil
+}
+
+// verifiedCertificate returns the client certificate verified during the
+// TLS handshake of the call, if any.
+func verifiedCertificate(ctx context.Context) *x509.Certificate {
+    p, ok := peer.FromContext(ctx)
+    if !ok {
+        return nil
+    }
+    info, ok := p.AuthInfo.(credentials.TLSInfo)
+    if !ok || len(info.State.VerifiedChains) == 0 {
+        return nil
+    }
+    return info.State.VerifiedChains[0][0]
+}
+
+type authenticatedStream struct {
+    grpc.ServerStream
+    ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+    return s.ctx
+}
@@ -0,0 +1,57 @@
+package grpcserver
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	tasksv1 "tasks-crud/api/tasks/v1"
+	"tasks-crud/internal/domain"
+)
+
+func toProtoTask(task *domain.Task) *tasksv1.Task {
+    if task == nil {
+        return nil
+    }
+    
+    msg := &tasksv1.Task{
+        Id:        int64(task.ID),
+        Title:     task.Title,
+        Completed: task.Completed,
+        Priority:  tasksv1.Priority(task.Priority),
+        Tags:      task.Tags,
+        CreatedAt: timestamppb.New(task.CreatedAt),
+        UpdatedAt: timestamppb.New(task.UpdatedAt),
+        Revision:  task.Revision,
+    }
+    if task.DueDate != nil {
+        msg.DueDate = timestamppb.New(*task.DueDate)
+    }
+    if task.ParentID != nil {
+        parentID := int64(*task.ParentID)
+        msg.ParentId = &parentID
+    }
+    
+    return msg
+}
+
+func toProtoEvent(event domain.TaskEvent) *tasksv1.TaskEvent {
+    return &tasksv1.TaskEvent{
+        Type:     toProtoEventType(event.Type),
+        TaskId:   int64(event.TaskID),
+        Revision: event.Revision,
+        Task:     toProtoTask(event.Task),
+        At:       timestamppb.New(event.At),
+    }
+}
+
+func toProtoEventType(eventType string) tasksv1.TaskEvent_Type {
+    switch eventType {
+    case domain.TaskEventCreated:
+        return tasksv1.TaskEvent_TYPE_CREATED
+    case domain.TaskEventUpdated:
+        return tasksv1.TaskEvent_TYPE_UPDATED
+    case domain.TaskEventDeleted:
+        return tasksv1.TaskEvent_TYPE_DELETED
+    default:
+        return tasksv1.TaskEvent_TYPE_UNSPECIFIED
+    }
+}
@@ -0,0 +1,48 @@
+package grpcserver
+
+import (
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/query"
+)
+
+// statusError maps service errors onto gRPC status codes the same way the
+// REST handlers map them onto HTTP statuses.
+func statusError(err error) error {
+    if err == nil {
+        return nil
+    }
+    
+    var parseErr *query.ParseError
+    switch {
+    case errors.As(err, &parseErr):
+        st := status.New(codes.InvalidArgument, err.Error())
+        detailed, detailsErr := st.WithDetails(&errdetails.BadRequest{
+            FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+                Field:       "query",
+                Description: err.Error(),
+            }},
+        })
+        if detailsErr != nil {
+            return st.Err()
+        }
+        return detailed.Err()
+    case errors.Is(err, domain.ErrForbidden):
+        return status.Error(codes.PermissionDenied, err.Error())
+    case errors.Is(err, domain.ErrQuotaExceeded):
+        return status.Error(codes.ResourceExhausted, err.Error())
+    case errors.Is(err, domain.ErrNotFound):
+        return status.Error(codes.NotFound, err.Error())
+    case errors.Is(err, domain.ErrAlreadyExists):
+        return status.Error(codes.AlreadyExists, err.Error())
+    case errors.Is(err, domain.ErrInternal):
+        return status.Error(codes.Internal, err.Error())
+    }
+    
+    return status.Error(codes.InvalidArgument, err.Error())
+}
@@ -0,0 +1,43 @@
+package grpcserver
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/repository"
+	"tasks-crud/internal/service"
+)
+
+func TestStatusErrorUsesErrorTypesNotMessages(t *testing.T) {
+    taskService := service.NewTaskService(repository.NewEmptyInMemoryTaskRepository())
+    if _, err := taskService.CreateTask(domain.CreateTaskRequest{Title: "not found"}); err != nil {
+        t.Fatal(err)
+    }
+    
+    _, duplicate := taskService.CreateTask(domain.CreateTaskRequest{Title: "not found"})
+    _, missing := taskService.GetTaskByID(42)
+    _, invalid := taskService.CreateTask(domain.CreateTaskRequest{Title: ""})
+    
+    tests := []struct {
+        name string
+        err  error
+        want codes.Code
+    }{
+        {"duplicate title mentioning not found", duplicate, codes.AlreadyExists},
+        {"missing task", missing, codes.NotFound},
+        {"validation", invalid, codes.InvalidArgument},
+        {"forbidden", domain.ErrForbidden, codes.PermissionDenied},
+        {"internal", domain.Internal(errors.New("failed to get tasks: disk full")), codes.Internal},
+        {"internal wrapping not found", domain.Internal(fmt.Errorf("failed to update task: %w", missing)), codes.NotFound},
+    }
+    for _, tt := range tests {
+        if got := status.Code(statusError(tt.err)); got != tt.want {
+            t.Errorf("%s: statusError(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+        }
+    }
+}
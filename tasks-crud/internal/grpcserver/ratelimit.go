@@ -0,0 +1,65 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/middleware"
+)
+
+// WithRateLimit returns server options that share limiter with the REST
+// API, so a client has one budget whichever protocol it uses. Pass them
+// after WithAuth and WithTenancy so that callers are told apart by
+// credentials.
+func WithRateLimit(limiter *middleware.RateLimiter) []grpc.ServerOption {
+    return []grpc.ServerOption{
+        grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+            if err := takeToken(ctx, limiter, info.FullMethod); err != nil {
+                return nil, err
+            }
+            return handler(ctx, req)
+        }),
+        grpc.ChainStreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+            if err := takeToken(stream.Context(), limiter, info.FullMethod); err != nil {
+                return err
+            }
+            return handler(srv, stream)
+        }),
+    }
+}
+
+func takeToken(ctx context.Context, limiter *middleware.RateLimiter, method string) error {
+    if strings.HasPrefix(method, reflectionPrefix) {
+        return nil
+    }
+    
+    client := ""
+    if principal := domain.PrincipalFromContext(ctx); principal != nil {
+        client = middleware.PrincipalClient(principal, domain.TenantFromContext(ctx))
+    } else if p, ok := peer.FromContext(ctx); ok {
+        host, _, err := net.SplitHostPort(p.Addr.String())
+        if err != nil {
+            host = p.Addr.String()
+        }
+        client = "ip:" + host
+    }
+    
+    write := !readMethods[method[strings.LastIndex(method, "/")+1:]]
+    decision := limiter.Allow(client, write)
+    if decision.Allowed {
+        return nil
+    }
+    
+    grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(decision.RetryAfter.Seconds()))))
+    return status.Error(codes.ResourceExhausted, fmt.Sprintf("rate limit of %d requests per %s exceeded", decision.Limit.Requests, decision.Limit.Window))
+}
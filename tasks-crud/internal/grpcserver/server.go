@@ -0,0 +1,197 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tasksv1 "tasks-crud/api/tasks/v1"
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/service"
+)
+
+const (
+    defaultPageSize = 50
+    maxPageSize     = 100
+    pageTokenPrefix = "offset:"
+)
+
+type Server struct {
+    tasksv1.UnimplementedTaskServiceServer
+    service *service.TaskService
+}
+
+func NewServer(service *service.TaskService) *Server {
+    return &Server{
+        service: service,
+    }
+}
+
+// Register creates a gRPC server with the task service registered on it.
+func Register(service *service.TaskService, opts ...grpc.ServerOption) *grpc.Server {
+    server := grpc.NewServer(opts...)
+    tasksv1.RegisterTaskServiceServer(server, NewServer(service))
+    return server
+}
+
+func (s *Server) CreateTask(ctx context.Context, req *tasksv1.CreateTaskRequest) (*tasksv1.Task, error) {
+    create := domain.CreateTaskRequest{
+        Title:    req.GetTitle(),
+        Priority: int(req.GetPriority()),
+        Tags:     req.GetTags(),
+        ParentID: optionalID(req.ParentId),
+    }
+    if req.DueDate != nil {
+        dueDate := req.DueDate.AsTime()
+        create.DueDate = &dueDate
+    }
+    
+    task, err := s.service.WithContext(ctx).CreateTask(create)
+    if err != nil {
+        return nil, statusError(err)
+    }
+    
+    return toProtoTask(task), nil
+}
+
+func (s *Server) GetTask(ctx context.Context, req *tasksv1.GetTaskRequest) (*tasksv1.Task, error) {
+    task, err := s.service.WithContext(ctx).GetTaskByID(int(req.GetId()))
+    if err != nil {
+        return nil, statusError(err)
+    }
+    
+    return toProtoTask(task), nil
+}
+
+func (s *Server) ListTasks(ctx context.Context, req *tasksv1.ListTasksRequest) (*tasksv1.ListTasksResponse, error) {
+    pageSize := int(req.GetPageSize())
+    if pageSize == 0 {
+        pageSize = defaultPageSize
+    }
+    if pageSize < 0 || pageSize > maxPageSize {
+        return nil, statusError(fmt.Errorf("page_size must be between 1 and %d", maxPageSize))
+    }
+    
+    offset, err := decodePageToken(req.GetPageToken())
+    if err != nil {
+        return nil, statusError(err)
+    }
+    
+    tasks, err := s.service.WithContext(ctx).ListTasks(req.GetQuery())
+    if err != nil {
+        return nil, statusError(err)
+    }
+    
+    start := min(offset, len(tasks))
+    end := min(start+pageSize, len(tasks))
+    
+    resp := &tasksv1.ListTasksResponse{
+        Tasks:     make([]*tasksv1.Task, 0, end-start),
+        TotalSize: int32(len(tasks)),
+    }
+    for i := start; i < end; i++ {
+        resp.Tasks = append(resp.Tasks, toProtoTask(&tasks[i]))
+    }
+    if end < len(tasks) {
+        resp.NextPageToken = encodePageToken(end)
+    }
+    
+    return resp, nil
+}
+
+func (s *Server) UpdateTask(ctx context.Context, req *tasksv1.UpdateTaskRequest) (*tasksv1.Task, error) {
+    update := domain.UpdateTaskRequest{
+        Title:     req.Title,
+        Completed: req.Completed,
+        ParentID:  optionalID(req.ParentId),
+    }
+    if req.Priority != nil {
+        priority := int(req.GetPriority())
+        update.Priority = &priority
+    }
+    if req.GetUpdateTags() {
+        update.Tags = append([]string{}, req.GetTags()...)
+    }
+    if req.DueDate != nil {
+        dueDate := req.DueDate.AsTime()
+        update.DueDate = &dueDate
+    }
+    
+    task, err := s.service.WithContext(ctx).UpdateTask(int(req.GetId()), update)
+    if err != nil {
+        return nil, statusError(err)
+    }
+    
+    return toProtoTask(task), nil
+}
+
+func (s *Server) DeleteTask(ctx context.Context, req *tasksv1.DeleteTaskRequest) (*tasksv1.DeleteTaskResponse, error) {
+    if err := s.service.WithContext(ctx).DeleteTask(int(req.GetId())); err != nil {
+        return nil, statusError(err)
+    }
+    
+    return &tasksv1.DeleteTaskResponse{}, nil
+}
+
+func (s *Server) WatchTasks(req *tasksv1.WatchTasksRequest, stream grpc.ServerStreamingServer[tasksv1.TaskEvent]) error {
+    wanted := make(map[tasksv1.TaskEvent_Type]bool, len(req.GetTypes()))
+    for _, eventType := range req.GetTypes() {
+        wanted[eventType] = true
+    }
+    
+    events, err := s.service.WithContext(stream.Context()).WatchTasks(stream.Context())
+    if err != nil {
+        return statusError(err)
+    }
+    
+    for event := range events {
+        msg := toProtoEvent(event)
+        if len(wanted) > 0 && !wanted[msg.Type] {
+            continue
+        }
+        if err := stream.Send(msg); err != nil {
+            return err
+        }
+    }
+    
+    if err := stream.Context().Err(); err != nil {
+        return err
+    }
+    return status.Error(codes.Aborted, "event stream fell behind, resync and watch again")
+}
+
+func optionalID(id *int64) *int {
+    if id == nil {
+        return nil
+    }
+    value := int(*id)
+    return &value
+}
+
+func encodePageToken(offset int) string {
+    return base64.StdEncoding.EncodeToString([]byte(pageTokenPrefix + strconv.Itoa(offset)))
+}
+
+func decodePageToken(token string) (int, error) {
+    if token == "" {
+        return 0, nil
+    }
+    
+    data, err := base64.StdEncoding.DecodeString(token)
+    if err != nil || !strings.HasPrefix(string(data), pageTokenPrefix) {
+        return 0, fmt.Errorf("invalid page token '%s'", token)
+    }
+    
+    offset, err := strconv.Atoi(strings.TrimPrefix(string(data), pageTokenPrefix))
+    if err != nil || offset < 0 {
+        return 0, fmt.Errorf("invalid page token '%s'", token)
+    }
+    
+    return offset, nil
+}
@@ -0,0 +1,72 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/middleware"
+)
+
+// WithTenancy returns server options that resolve the tenant of every call
+// from the caller's token claim, the resolver's header (as lowercase
+// metadata) or the subdomain in :authority. Pass them after WithAuth so
+// that the claim is known.
+func WithTenancy(resolver *middleware.TenantResolver) []grpc.ServerOption {
+    return []grpc.ServerOption{
+        grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+            ctx, err := resolveTenant(ctx, resolver, info.FullMethod)
+            if err != nil {
+                return nil, err
+            }
+            return handler(ctx, req)
+        }),
+        grpc.ChainStreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+            ctx, err := resolveTenant(stream.Context(), resolver, info.FullMethod)
+            if err != nil {
+                return err
+            }
+            return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+        }),
+    }
+}
+
+func resolveTenant(ctx context.Context, resolver *middleware.TenantResolver, method string) (context.Context, error) {
+    if strings.HasPrefix(method, reflectionPrefix) {
+        return ctx, nil
+    }
+    
+    header := resolver.Header
+    if header == "" {
+        header = middleware.DefaultTenantHeader
+    }
+    
+    md, _ := metadata.FromIncomingContext(ctx)
+    var requested string
+    if values := md.Get(strings.ToLower(header)); len(values) > 0 {
+        requested = strings.TrimSpace(values[0])
+    } else if values := md.Get(":authority"); len(values) > 0 {
+        requested = resolver.FromHost(values[0])
+    }
+    
+    ctx, err := resolver.Resolve(ctx, requested)
+    if err != nil {
+        switch {
+        case errors.Is(err, middleware.ErrTenantRequired):
+            return nil, status.Error(codes.InvalidArgument, err.Error())
+        case errors.Is(err, middleware.ErrTenantMismatch), errors.Is(err, middleware.ErrTenantInactive):
+            return nil, status.Error(codes.PermissionDenied, err.Error())
+        case errors.Is(err, domain.ErrNotFound):
+            return nil, status.Error(codes.NotFound, err.Error())
+        }
+        return nil, status.Error(codes.Internal, err.Error())
+    }
+    
+    return ctx, nil
+}
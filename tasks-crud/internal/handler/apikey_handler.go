@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/service"
+)
+
+type APIKeyHandler struct {
+    service *service.APIKeyService
+}
+
+func NewAPIKeyHandler(service *service.APIKeyService) *APIKeyHandler {
+    return &APIKeyHandler{
+        service: service,
+    }
+}
+
+func (h *APIKeyHandler) GetAllKeys(w http.ResponseWriter, r *http.Request) {
+    keys, err := h.service.WithContext(r.Context()).GetAllKeys()
+    if err != nil {
+        sendError(w, http.StatusInternalServerError, "Failed to get API keys", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, keys)
+}
+
+// CreateKey returns the new key in the "key" field. It is not shown again.
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+    var req domain.CreateAPIKeyRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
+        return
+    }
+    defer r.Body.Close()
+    
+    key, err := h.service.WithContext(r.Context()).CreateKey(req)
+    if err != nil {
+        if errors.Is(err, domain.ErrInternal) {
+            sendError(w, http.StatusInternalServerError, "Failed to create API key", nil)
+            return
+        }
+        sendError(w, http.StatusBadRequest, "Failed to create API key", err)
+        return
+    }
+    
+    w.Header().Set("Cache-Control", "no-store")
+    sendJSON(w, http.StatusCreated, key)
+}
+
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Invalid API key ID", err)
+        return
+    }
+    
+    if err := h.service.WithContext(r.Context()).RevokeKey(id); err != nil {
+        sendError(w, http.StatusNotFound, "API key not found", err)
+        return
+    }
+    
+    w.WriteHeader(http.StatusNoContent)
+}
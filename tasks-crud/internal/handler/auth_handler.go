@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/service"
+)
+
+type AuthHandler struct {
+    service *service.AuthService
+}
+
+func NewAuthHandler(service *service.AuthService) *AuthHandler {
+    return &AuthHandler{
+        service: service,
+    }
+}
+
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+    var req domain.RegisterRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
+        return
+    }
+    defer r.Body.Close()
+    
+    tokens, err := h.service.WithContext(r.Context()).Register(req)
+    if err != nil {
+        sendAuthError(w, "Failed to register", err)
+        return
+    }
+    
+    sendTokens(w, http.StatusCreated, tokens)
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+    var req domain.LoginRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
+        return
+    }
+    defer r.Body.Close()
+    
+    tokens, err := h.service.WithContext(r.Context()).Login(req)
+    if err != nil {
+        sendAuthError(w, "Failed to log in", err)
+        return
+    }
+    
+    sendTokens(w, http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+    var req domain.RefreshRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
+        return
+    }
+    defer r.Body.Close()
+    
+    tokens, err := h.service.WithContext(r.Context()).Refresh(req)
+    if err != nil {
+        sendAuthError(w, "Failed to refresh token", err)
+        return
+    }
+    
+    sendTokens(w, http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+    if err := h.service.WithContext(r.Context()).Logout(domain.PrincipalFromContext(r.Context())); err != nil {
+        sendAuthError(w, "Failed to log out", err)
+        return
+    }
+    
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+    var req domain.ChangePasswordRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
+        return
+    }
+    defer r.Body.Close()
+    
+    if err := h.service.WithContext(r.Context()).ChangePassword(domain.PrincipalFromContext(r.Context()), req); err != nil {
+        sendAuthError(w, "Failed to change password", err)
+        return
+    }
+    
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+    user, err := h.service.WithContext(r.Context()).CurrentUser(domain.PrincipalFromContext(r.Context()))
+    if err != nil {
+        sendAuthError(w, "Failed to get user", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, user)
+}
+
+// sendTokens forbids caching, as RFC 6749 requires for token responses.
+func sendTokens(w http.ResponseWriter, statusCode int, tokens *domain.TokenResponse) {
+    w.Header().Set("Cache-Control", "no-store")
+    sendJSON(w, statusCode, tokens)
+}
+
+func sendAuthError(w http.ResponseWriter, message string, err error) {
+    errMsg := err.Error()
+    switch {
+    case strings.Contains(errMsg, "already exists"):
+        sendError(w, http.StatusConflict, message, err)
+    case strings.Contains(errMsg, "invalid username or password"),
+        strings.Contains(errMsg, "invalid refresh token"):
+        sendError(w, http.StatusUnauthorized, message, err)
+    case strings.Contains(errMsg, "invalid current password"):
+        sendError(w, http.StatusForbidden, message, err)
+    case strings.Contains(errMsg, "not found"), strings.Contains(errMsg, "not issued by this server"):
+        sendError(w, http.StatusNotFound, message, err)
+    case strings.Contains(errMsg, "failed to"):
+        sendError(w, http.StatusInternalServerError, message, nil)
+    default:
+        sendError(w, http.StatusBadRequest, message, err)
+    }
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/service"
+)
+
+type BulkHandler struct {
+    service *service.TaskService
+}
+
+func NewBulkHandler(service *service.TaskService) *BulkHandler {
+    return &BulkHandler{
+        service: service,
+    }
+}
+
+func (h *BulkHandler) Execute(w http.ResponseWriter, r *http.Request) {
+    var req domain.BulkRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
+        return
+    }
+    defer r.Body.Close()
+    
+    resp, err := h.service.WithContext(r.Context()).ExecuteBulk(req)
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Failed to execute bulk operations", err)
+        return
+    }
+    
+    if resp.Atomic && resp.Failed > 0 {
+        sendJSON(w, http.StatusBadRequest, resp)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, resp)
+}
\ No newline at end of file
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"tasks-crud/internal/service"
+)
+
+const eventsKeepAlive = 30 * time.Second
+
+type EventsHandler struct {
+    service *service.TaskService
+}
+
+func NewEventsHandler(service *service.TaskService) *EventsHandler {
+    return &EventsHandler{
+        service: service,
+    }
+}
+
+// Stream sends task change events as Server-Sent Events until the client
+// disconnects. Each event is named after its type and carries the
+// domain.TaskEvent as JSON. A client too slow to keep up gets a final
+// "resync" event and the stream ends.
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+    flusher, ok := w.(http.Flusher)
+    if !ok {
+        sendError(w, http.StatusInternalServerError, "Streaming is not supported", nil)
+        return
+    }
+    
+    events, err := h.service.WithContext(r.Context()).WatchTasks(r.Context())
+    if err != nil {
+        sendError(w, http.StatusInternalServerError, "Failed to watch tasks", err)
+        return
+    }
+    
+    http.NewResponseController(w).SetWriteDeadline(time.Time{})
+    
+    w.Header().Set("Content-Type", "text/event-stream")
+    w.Header().Set("Cache-Control", "no-cache")
+    w.Header().Set("Connection", "keep-alive")
+    w.WriteHeader(http.StatusOK)
+    flusher.Flush()
+    
+    keepAlive := time.NewTicker(eventsKeepAlive)
+    defer keepAlive.Stop()
+    
+    for {
+        select {
+        case event, ok := <-events:
+            if !ok {
+                // The stream fell behind and was dropped; the client has
+                // missed events and must resync before reconnecting.
+                if r.Context().Err() == nil {
+                    fmt.Fprint(w, "event: resync\ndata: {}\n\n")
+                    flusher.Flush()
+                }
+                return
+            }
+            data, err := json.Marshal(event)
+            if err != nil {
+                continue
+            }
+            fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Revision, event.Type, data)
+            flusher.Flush()
+        case <-keepAlive.C:
+            fmt.Fprint(w, ": keep-alive\n\n")
+            flusher.Flush()
+        }
+    }
+}
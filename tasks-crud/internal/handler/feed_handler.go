@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/service"
+	"tasks-crud/internal/transfer"
+)
+
+const calendarPath = "/api/v1/tasks.ics"
+
+type FeedHandler struct {
+    service *service.FeedService
+}
+
+func NewFeedHandler(service *service.FeedService) *FeedHandler {
+    return &FeedHandler{
+        service: service,
+    }
+}
+
+func (h *FeedHandler) GetAllFeeds(w http.ResponseWriter, r *http.Request) {
+    feeds, err := h.service.WithContext(r.Context()).GetAllFeeds()
+    if err != nil {
+        sendError(w, http.StatusInternalServerError, "Failed to get feeds", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, feeds)
+}
+
+// CreateFeed returns the feed together with its secret URL. The URL is not
+// shown again; to change it, delete the feed and create a new one.
+func (h *FeedHandler) CreateFeed(w http.ResponseWriter, r *http.Request) {
+    var req domain.CreateCalendarFeedRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
+        return
+    }
+    defer r.Body.Close()
+    
+    feed, token, err := h.service.WithContext(r.Context()).CreateFeed(req)
+    if err != nil {
+        sendFilterError(w, "Failed to create feed", req.Query, err)
+        return
+    }
+    
+    feed.URL = feedURL(r, token)
+    sendJSON(w, http.StatusCreated, feed)
+}
+
+func (h *FeedHandler) DeleteFeed(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Invalid feed ID", err)
+        return
+    }
+    
+    if err := h.service.WithContext(r.Context()).DeleteFeed(id); err != nil {
+        sendError(w, http.StatusNotFound, "Feed not found", err)
+        return
+    }
+    
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// Calendar serves GET /tasks.ics?token=... as a VCALENDAR of VTODOs. An
+// unknown or missing token is a plain 404.
+func (h *FeedHandler) Calendar(w http.ResponseWriter, r *http.Request) {
+    feed, tasks, err := h.service.WithContext(r.Context()).OpenFeed(r.URL.Query().Get("token"))
+    if err != nil {
+        if errors.Is(err, domain.ErrNotFound) {
+            sendError(w, http.StatusNotFound, "Feed not found", nil)
+            return
+        }
+        sendError(w, http.StatusInternalServerError, "Failed to build feed", err)
+        return
+    }
+    
+    w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+    w.Header().Set("Cache-Control", "private, max-age=300")
+    w.WriteHeader(http.StatusOK)
+    
+    encoder := transfer.NewCalendarEncoder(w, feed.Name)
+    for _, task := range tasks {
+        if err := encoder.Encode(task); err != nil {
+            log.Printf("feed %d: failed to write task %d: %v", feed.ID, task.ID, err)
+            return
+        }
+    }
+    
+    if err := encoder.Close(); err != nil {
+        log.Printf("feed %d: failed to finish: %v", feed.ID, err)
+    }
+}
+
+// feedURL builds the secret URL of a feed. Calendar apps can't send
+// headers, so the tenant, if any, goes into the query.
+func feedURL(r *http.Request, token string) string {
+    scheme := "http"
+    if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+        scheme = "https"
+    }
+    
+    query := url.Values{"token": {token}}
+    if tenantID := domain.TenantFromContext(r.Context()); tenantID != "" {
+        query.Set("tenant", tenantID)
+    }
+    
+    u := url.URL{
+        Scheme:   scheme,
+        Host:     r.Host,
+        Path:     calendarPath,
+        RawQuery: query.Encode(),
+    }
+    return u.String()
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/query"
+	"tasks-crud/internal/service"
+)
+
+type FilterHandler struct {
+    service *service.FilterService
+}
+
+func NewFilterHandler(service *service.FilterService) *FilterHandler {
+    return &FilterHandler{
+        service: service,
+    }
+}
+
+func (h *FilterHandler) GetAllFilters(w http.ResponseWriter, r *http.Request) {
+    filters, err := h.service.WithContext(r.Context()).GetAllFilters()
+    if err != nil {
+        sendError(w, http.StatusInternalServerError, "Failed to get filters", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, filters)
+}
+
+func (h *FilterHandler) GetFilterByID(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Invalid filter ID", err)
+        return
+    }
+    
+    filter, err := h.service.WithContext(r.Context()).GetFilterByID(id)
+    if err != nil {
+        sendError(w, http.StatusNotFound, "Filter not found", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, filter)
+}
+
+func (h *FilterHandler) CreateFilter(w http.ResponseWriter, r *http.Request) {
+    var req domain.CreateSavedFilterRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
+        return
+    }
+    defer r.Body.Close()
+    
+    filter, err := h.service.WithContext(r.Context()).CreateFilter(req)
+    if err != nil {
+        sendFilterError(w, "Failed to create filter", req.Query, err)
+        return
+    }
+    
+    sendJSON(w, http.StatusCreated, filter)
+}
+
+func (h *FilterHandler) UpdateFilter(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Invalid filter ID", err)
+        return
+    }
+    
+    var req domain.UpdateSavedFilterRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
+        return
+    }
+    defer r.Body.Close()
+    
+    filter, err := h.service.WithContext(r.Context()).UpdateFilter(id, req)
+    if err != nil {
+        q := ""
+        if req.Query != nil {
+            q = *req.Query
+        }
+        sendFilterError(w, "Failed to update filter", q, err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, filter)
+}
+
+func (h *FilterHandler) DeleteFilter(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Invalid filter ID", err)
+        return
+    }
+    
+    if err := h.service.WithContext(r.Context()).DeleteFilter(id); err != nil {
+        sendError(w, http.StatusNotFound, "Filter not found", err)
+        return
+    }
+    
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *FilterHandler) GetFilterTasks(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Invalid filter ID", err)
+        return
+    }
+    
+    tasks, err := h.service.WithContext(r.Context()).RunFilter(id)
+    if err != nil {
+        sendFilterError(w, "Failed to run filter", "", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, tasks)
+}
+
+func sendFilterError(w http.ResponseWriter, message, q string, err error) {
+    var parseErr *query.ParseError
+    switch {
+    case errors.As(err, &parseErr):
+        sendQueryError(w, q, parseErr)
+    case errors.Is(err, domain.ErrNotFound):
+        sendError(w, http.StatusNotFound, "Filter not found", err)
+    case errors.Is(err, domain.ErrInternal):
+        sendError(w, http.StatusInternalServerError, message, nil)
+    default:
+        sendError(w, http.StatusBadRequest, message, err)
+    }
+}
+
+func sendQueryError(w http.ResponseWriter, q string, err *query.ParseError) {
+    sendJSON(w, http.StatusBadRequest, domain.QueryErrorResponse{
+        Error:    "Invalid query",
+        Details:  err.Message,
+        Query:    q,
+        Position: err.Pos,
+    })
+}
\ No newline at end of file
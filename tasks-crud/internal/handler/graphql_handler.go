@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"tasks-crud/internal/domain"
+)
+
+type graphQLRequest struct {
+    Query         string                 `json:"query"`
+    OperationName string                 `json:"operationName"`
+    Variables     map[string]interface{} `json:"variables"`
+}
+
+type GraphQLHandler struct {
+    schema graphql.Schema
+}
+
+func NewGraphQLHandler(schema graphql.Schema) *GraphQLHandler {
+    return &GraphQLHandler{
+        schema: schema,
+    }
+}
+
+func (h *GraphQLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    var req graphQLRequest
+    switch r.Method {
+    case http.MethodGet:
+        req.Query = r.URL.Query().Get("query")
+        req.OperationName = r.URL.Query().Get("operationName")
+        if variables := r.URL.Query().Get("variables"); variables != "" {
+            if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+                sendError(w, http.StatusBadRequest, "Invalid variables", err)
+                return
+            }
+        }
+    case http.MethodPost:
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            sendBodyError(w, "Invalid JSON", err)
+            return
+        }
+        defer r.Body.Close()
+    default:
+        sendError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+        return
+    }
+    
+    if req.Query == "" {
+        sendError(w, http.StatusBadRequest, "Query is required", fmt.Errorf("missing 'query'"))
+        return
+    }
+    
+    params := graphql.Params{
+        Schema:         h.schema,
+        RequestString:  req.Query,
+        VariableValues: req.Variables,
+        OperationName:  req.OperationName,
+        Context:        r.Context(),
+    }
+    
+    operation := operationType(req.Query, req.OperationName)
+    if scope := operationScope(operation); !keyHasScope(r, scope) {
+        sendError(w, http.StatusForbidden, "Forbidden", fmt.Errorf("api key lacks scope %s", scope))
+        return
+    }
+    
+    switch operation {
+    case ast.OperationTypeSubscription:
+        h.subscribe(w, r, params)
+    case ast.OperationTypeMutation:
+        if r.Method != http.MethodPost {
+            sendError(w, http.StatusMethodNotAllowed, "Mutations require POST", nil)
+            return
+        }
+        sendJSON(w, http.StatusOK, graphql.Do(params))
+    default:
+        sendJSON(w, http.StatusOK, graphql.Do(params))
+    }
+}
+
+func (h *GraphQLHandler) subscribe(w http.ResponseWriter, r *http.Request, params graphql.Params) {
+    flusher, ok := w.(http.Flusher)
+    if !ok {
+        sendError(w, http.StatusInternalServerError, "Streaming is not supported", nil)
+        return
+    }
+    
+    http.NewResponseController(w).SetWriteDeadline(time.Time{})
+    
+    w.Header().Set("Content-Type", "text/event-stream")
+    w.Header().Set("Cache-Control", "no-cache")
+    w.Header().Set("Connection", "keep-alive")
+    w.WriteHeader(http.StatusOK)
+    flusher.Flush()
+    
+    for result := range graphql.Subscribe(params) {
+        data, err := json.Marshal(result)
+        if err != nil {
+            continue
+        }
+        if r.Context().Err() != nil {
+            continue
+        }
+        fmt.Fprintf(w, "event: next\ndata: %s\n\n", data)
+        flusher.Flush()
+    }
+    
+    if r.Context().Err() == nil {
+        fmt.Fprint(w, "event: complete\ndata:\n\n")
+        flusher.Flush()
+    }
+}
+
+// operationScope returns the scope an API key needs for a GraphQL
+// operation: mutations need tasks:write, queries and subscriptions only
+// read.
+func operationScope(operation string) string {
+    if operation == ast.OperationTypeMutation {
+        return domain.ScopeTasksWrite
+    }
+    return domain.ScopeTasksRead
+}
+
+// keyHasScope reports whether the request may use scope. Only API keys
+// are limited to scopes; middleware.APIKeyScopes leaves /graphql to this
+// handler.
+func keyHasScope(r *http.Request, scope string) bool {
+    principal := domain.PrincipalFromContext(r.Context())
+    return principal == nil || principal.Method != domain.AuthMethodAPIKey || principal.HasScope(scope)
+}
+
+func operationType(query, operationName string) string {
+    document, err := parser.Parse(parser.ParseParams{
+        Source: source.NewSource(&source.Source{Body: []byte(query)}),
+    })
+    if err != nil {
+        return ast.OperationTypeQuery
+    }
+    
+    for _, definition := range document.Definitions {
+        operation, ok := definition.(*ast.OperationDefinition)
+        if !ok {
+            continue
+        }
+        if operationName == "" || (operation.Name != nil && operation.Name.Value == operationName) {
+            return operation.Operation
+        }
+    }
+    
+    return ast.OperationTypeQuery
+}
\ No newline at end of file
@@ -0,0 +1,42 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/gql"
+	"tasks-crud/internal/handler"
+	"tasks-crud/internal/middleware"
+	"tasks-crud/internal/repository"
+	"tasks-crud/internal/service"
+)
+
+func TestGraphQLScopesOfReadOnlyKey(t *testing.T) {
+    schema, err := gql.NewSchema(service.NewTaskService(repository.NewEmptyInMemoryTaskRepository()))
+    if err != nil {
+        t.Fatal(err)
+    }
+    key := &domain.Principal{Subject: "key:1", Method: domain.AuthMethodAPIKey, Scopes: []string{domain.ScopeTasksRead}}
+    var h http.Handler = middleware.APIKeyScopes(nil, "/graphql")(handler.NewGraphQLHandler(schema))
+    
+    for _, tc := range []struct {
+        body   string
+        status int
+    }{
+        {`{"query": "{ tasks { totalCount } }"}`, http.StatusOK},
+        {`{"query": "query Count { tasks { totalCount } }", "operationName": "Count"}`, http.StatusOK},
+        {`{"query": "mutation { createTask(input: {title: \"x\"}) { id } }"}`, http.StatusForbidden},
+        {`{"query": "query Count { tasks { totalCount } } mutation Add { createTask(input: {title: \"x\"}) { id } }", "operationName": "Add"}`, http.StatusForbidden},
+    } {
+        r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(tc.body))
+        r = r.WithContext(domain.WithPrincipal(r.Context(), key))
+        w := httptest.NewRecorder()
+        h.ServeHTTP(w, r)
+        if w.Code != tc.status {
+            t.Errorf("%s: got %d, want %d: %s", tc.body, w.Code, tc.status, w.Body)
+        }
+    }
+}
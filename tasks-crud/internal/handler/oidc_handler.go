@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/middleware"
+	"tasks-crud/internal/service"
+)
+
+type OIDCHandler struct {
+    service *service.OIDCService
+}
+
+func NewOIDCHandler(service *service.OIDCService) *OIDCHandler {
+    return &OIDCHandler{
+        service: service,
+    }
+}
+
+// oidcStateCookie binds a login's state to the browser that started it,
+// so a callback URL of someone else's login can't sign this browser in.
+const oidcStateCookie = "oidc_state"
+
+// Login redirects the browser to the provider. An optional login_hint is
+// passed on.
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+    authURL, state, err := h.service.BeginLogin(r.Context(), r.URL.Query().Get("login_hint"), middleware.RateLimitClient(r))
+    if err != nil {
+        sendOIDCError(w, "Failed to start login", err)
+        return
+    }
+    
+    // Lax, not Strict: the callback is a cross-site navigation from the
+    // provider.
+    http.SetCookie(w, &http.Cookie{
+        Name:     oidcStateCookie,
+        Value:    state,
+        Path:     "/api/v1/auth/oidc/",
+        MaxAge:   int(service.OIDCLoginTTL.Seconds()),
+        HttpOnly: true,
+        Secure:   r.TLS != nil,
+        SameSite: http.SameSiteLaxMode,
+    })
+    w.Header().Set("Cache-Control", "no-store")
+    http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback is where the provider sends the browser back. It answers with
+// the same token response as /auth/login.
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+    browserState := ""
+    if cookie, err := r.Cookie(oidcStateCookie); err == nil {
+        browserState = cookie.Value
+        http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: "/api/v1/auth/oidc/", MaxAge: -1})
+    }
+    
+    query := r.URL.Query()
+    if code := query.Get("error"); code != "" {
+        sendError(w, http.StatusUnauthorized, "Login was refused by the provider", fmt.Errorf("%s: %s", code, query.Get("error_description")))
+        return
+    }
+    
+    tokens, err := h.service.CompleteLogin(r.Context(), query.Get("state"), browserState, query.Get("code"))
+    if err != nil {
+        sendOIDCError(w, "Failed to log in", err)
+        return
+    }
+    
+    sendTokens(w, http.StatusOK, tokens)
+}
+
+func sendOIDCError(w http.ResponseWriter, message string, err error) {
+    errMsg := err.Error()
+    switch {
+    case errors.Is(err, domain.ErrForbidden):
+        sendError(w, http.StatusForbidden, message, err)
+    case strings.Contains(errMsg, "invalid id token"):
+        sendError(w, http.StatusUnauthorized, message, err)
+    case strings.Contains(errMsg, "failed to discover provider"), strings.Contains(errMsg, "failed to redeem code"):
+        sendError(w, http.StatusBadGateway, message, err)
+    case strings.Contains(errMsg, "too many logins"):
+        sendError(w, http.StatusServiceUnavailable, message, err)
+    case strings.Contains(errMsg, "failed to"):
+        sendError(w, http.StatusInternalServerError, message, nil)
+    default:
+        sendError(w, http.StatusBadRequest, message, err)
+    }
+}
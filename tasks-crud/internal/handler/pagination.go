@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"tasks-crud/internal/domain"
+)
+
+const maxPageLimit = 1000
+
+// PaginateTasks applies the optional limit and offset query parameters to
+// tasks. The total count is reported in X-Total-Count and the next page, if
+// any, in a Link header with rel="next"; without limit all tasks are returned.
+func PaginateTasks(w http.ResponseWriter, r *http.Request, tasks []domain.Task) ([]domain.Task, error) {
+    query := r.URL.Query()
+    
+    offset, err := pageParam(query, "offset", 0)
+    if err != nil {
+        return nil, err
+    }
+    limit, err := pageParam(query, "limit", 0)
+    if err != nil {
+        return nil, err
+    }
+    if limit > maxPageLimit {
+        return nil, fmt.Errorf("limit must not exceed %d", maxPageLimit)
+    }
+    
+    w.Header().Set("X-Total-Count", strconv.Itoa(len(tasks)))
+    
+    start := min(offset, len(tasks))
+    end := len(tasks)
+    if limit > 0 {
+        end = min(start+limit, len(tasks))
+    }
+    
+    if end < len(tasks) {
+        next := *r.URL
+        nextQuery := next.Query()
+        nextQuery.Set("offset", strconv.Itoa(end))
+        nextQuery.Set("limit", strconv.Itoa(limit))
+        next.RawQuery = nextQuery.Encode()
+        w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+    }
+    
+    return tasks[start:end], nil
+}
+
+func pageParam(query url.Values, name string, defaultValue int) (int, error) {
+    raw := query.Get(name)
+    if raw == "" {
+        return defaultValue, nil
+    }
+    
+    value, err := strconv.Atoi(raw)
+    if err != nil || value < 0 {
+        return 0, fmt.Errorf("%s must be a non-negative integer", name)
+    }
+    
+    return value, nil
+}
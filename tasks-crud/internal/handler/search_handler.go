@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"tasks-crud/internal/service"
+)
+
+type SearchHandler struct {
+    service *service.TaskService
+}
+
+func NewSearchHandler(service *service.TaskService) *SearchHandler {
+    return &SearchHandler{
+        service: service,
+    }
+}
+
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+    limit := 0
+    if value := r.URL.Query().Get("limit"); value != "" {
+        parsed, err := strconv.Atoi(value)
+        if err != nil {
+            sendError(w, http.StatusBadRequest, "Invalid limit", err)
+            return
+        }
+        limit = parsed
+    }
+    
+    resp, err := h.service.WithContext(r.Context()).SearchTasks(r.URL.Query().Get("q"), limit)
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Failed to search tasks", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, resp)
+}
\ No newline at end of file
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/service"
+)
+
+type ShareHandler struct {
+    service *service.ShareService
+}
+
+func NewShareHandler(service *service.ShareService) *ShareHandler {
+    return &ShareHandler{
+        service: service,
+    }
+}
+
+func (h *ShareHandler) GetTaskShares(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Invalid task ID", err)
+        return
+    }
+    
+    shares, err := h.service.WithContext(r.Context()).GetTaskShares(id)
+    if err != nil {
+        sendShareError(w, "Failed to get shares", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, shares)
+}
+
+func (h *ShareHandler) ShareTask(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Invalid task ID", err)
+        return
+    }
+    
+    var req domain.CreateShareRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
+        return
+    }
+    defer r.Body.Close()
+    
+    share, err := h.service.WithContext(r.Context()).ShareTask(id, req)
+    if err != nil {
+        sendShareError(w, "Failed to share task", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, share)
+}
+
+func (h *ShareHandler) UnshareTask(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Invalid task ID", err)
+        return
+    }
+    
+    if err := h.service.WithContext(r.Context()).UnshareTask(id, vars["grantee"]); err != nil {
+        sendShareError(w, "Failed to unshare task", err)
+        return
+    }
+    
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ShareHandler) GetProjectShares(w http.ResponseWriter, r *http.Request) {
+    shares, err := h.service.WithContext(r.Context()).GetProjectShares(mux.Vars(r)["project"])
+    if err != nil {
+        sendShareError(w, "Failed to get shares", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, shares)
+}
+
+func (h *ShareHandler) ShareProject(w http.ResponseWriter, r *http.Request) {
+    var req domain.CreateShareRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
+        return
+    }
+    defer r.Body.Close()
+    
+    share, err := h.service.WithContext(r.Context()).ShareProject(mux.Vars(r)["project"], req)
+    if err != nil {
+        sendShareError(w, "Failed to share project", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, share)
+}
+
+func (h *ShareHandler) UnshareProject(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    if err := h.service.WithContext(r.Context()).UnshareProject(vars["project"], vars["grantee"]); err != nil {
+        sendShareError(w, "Failed to unshare project", err)
+        return
+    }
+    
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ShareHandler) GetSharedWithMe(w http.ResponseWriter, r *http.Request) {
+    shares, err := h.service.WithContext(r.Context()).GetSharedWithMe()
+    if err != nil {
+        sendShareError(w, "Failed to get shares", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, shares)
+}
+
+func sendShareError(w http.ResponseWriter, message string, err error) {
+    switch {
+    case errors.Is(err, domain.ErrForbidden):
+        sendError(w, http.StatusForbidden, message, err)
+    case errors.Is(err, domain.ErrNotFound):
+        sendError(w, http.StatusNotFound, message, err)
+    case errors.Is(err, domain.ErrInternal):
+        sendError(w, http.StatusInternalServerError, message, nil)
+    default:
+        sendError(w, http.StatusBadRequest, message, err)
+    }
+}
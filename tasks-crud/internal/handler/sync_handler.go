@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/service"
+)
+
+type SyncHandler struct {
+    service *service.TaskService
+}
+
+func NewSyncHandler(service *service.TaskService) *SyncHandler {
+    return &SyncHandler{
+        service: service,
+    }
+}
+
+func (h *SyncHandler) GetChanges(w http.ResponseWriter, r *http.Request) {
+    changes, err := h.service.WithContext(r.Context()).GetChangesSince(r.URL.Query().Get("since"))
+    if errors.Is(err, domain.ErrSyncTokenExpired) {
+        sendError(w, http.StatusGone, "Sync token expired, sync again without a token", err)
+        return
+    }
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Invalid sync token", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, changes)
+}
+
+func (h *SyncHandler) PushChanges(w http.ResponseWriter, r *http.Request) {
+    var req domain.SyncPushRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
+        return
+    }
+    defer r.Body.Close()
+    
+    resp, err := h.service.WithContext(r.Context()).ApplySyncChanges(req)
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Failed to apply changes", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, resp)
+}
\ No newline at end of file
@@ -2,12 +2,16 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"tasks-crud/internal/domain"
+	"tasks-crud/internal/patch"
+	"tasks-crud/internal/query"
 	"tasks-crud/internal/service"
 )
 
@@ -54,6 +58,8 @@ func (h *TaskHandler) handleTaskByID(w http.ResponseWriter, r *http.Request, pat
         h.getTaskByID(w, r, id)
     case "PUT":
         h.updateTask(w, r, id)
+    case "PATCH":
+        h.patchTask(w, r, id)
     case "DELETE":
         h.deleteTask(w, r, id)
     default:
@@ -62,17 +68,47 @@ func (h *TaskHandler) handleTaskByID(w http.ResponseWriter, r *http.Request, pat
 }
 
 func (h *TaskHandler) getAllTasks(w http.ResponseWriter, r *http.Request) {
-    tasks, err := h.service.GetAllTasks()
+    if q := r.URL.Query().Get("q"); q != "" {
+        h.listTasks(w, r, q)
+        return
+    }
+    
+    tasks, err := h.service.WithContext(r.Context()).GetAllTasks()
     if err != nil {
         sendError(w, http.StatusInternalServerError, "Failed to get tasks", err)
         return
     }
     
-    sendJSON(w, http.StatusOK, tasks)
+    h.sendTaskPage(w, r, tasks)
+}
+
+func (h *TaskHandler) listTasks(w http.ResponseWriter, r *http.Request, q string) {
+    tasks, err := h.service.WithContext(r.Context()).ListTasks(q)
+    if err != nil {
+        var parseErr *query.ParseError
+        if errors.As(err, &parseErr) {
+            sendQueryError(w, q, parseErr)
+            return
+        }
+        sendError(w, http.StatusInternalServerError, "Failed to list tasks", err)
+        return
+    }
+    
+    h.sendTaskPage(w, r, tasks)
+}
+
+func (h *TaskHandler) sendTaskPage(w http.ResponseWriter, r *http.Request, tasks []domain.Task) {
+    page, err := PaginateTasks(w, r, tasks)
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Invalid pagination", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, page)
 }
 
 func (h *TaskHandler) getTaskByID(w http.ResponseWriter, r *http.Request, id int) {
-    task, err := h.service.GetTaskByID(id)
+    task, err := h.service.WithContext(r.Context()).GetTaskByID(id)
     if err != nil {
         sendError(w, http.StatusNotFound, "Task not found", err)
         return
@@ -84,15 +120,26 @@ func (h *TaskHandler) getTaskByID(w http.ResponseWriter, r *http.Request, id int
 func (h *TaskHandler) createTask(w http.ResponseWriter, r *http.Request) {
     var req domain.CreateTaskRequest
     
-    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        sendError(w, http.StatusBadRequest, "Invalid JSON", err)
+    decoder := json.NewDecoder(r.Body)
+    decoder.DisallowUnknownFields()
+    if err := decoder.Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
         return
     }
     defer r.Body.Close()
     
-    task, err := h.service.CreateTask(req)
+    task, err := h.service.WithContext(r.Context()).CreateTask(req)
     if err != nil {
-        sendError(w, http.StatusBadRequest, "Failed to create task", err)
+        switch {
+        case errors.Is(err, domain.ErrForbidden):
+            sendError(w, http.StatusForbidden, "Failed to create task", err)
+        case errors.Is(err, domain.ErrQuotaExceeded):
+            sendQuotaError(w, "Failed to create task", err)
+        case errors.Is(err, domain.ErrInternal):
+            sendError(w, http.StatusInternalServerError, "Failed to create task", nil)
+        default:
+            sendError(w, http.StatusBadRequest, "Failed to create task", err)
+        }
         return
     }
     
@@ -100,18 +147,25 @@ func (h *TaskHandler) createTask(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *TaskHandler) updateTask(w http.ResponseWriter, r *http.Request, id int) {
-    var req domain.UpdateTaskRequest
-    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        sendError(w, http.StatusBadRequest, "Invalid JSON", err)
+    var req domain.ReplaceTaskRequest
+    decoder := json.NewDecoder(r.Body)
+    decoder.DisallowUnknownFields()
+    if err := decoder.Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
         return
     }
     defer r.Body.Close()
     
-    task, err := h.service.UpdateTask(id, req)
+    task, err := h.service.WithContext(r.Context()).ReplaceTask(id, req)
     if err != nil {
-        if strings.Contains(err.Error(), "not found") {
+        switch {
+        case errors.Is(err, domain.ErrForbidden):
+            sendError(w, http.StatusForbidden, "Forbidden", err)
+        case errors.Is(err, domain.ErrNotFound):
             sendError(w, http.StatusNotFound, "Task not found", err)
-        } else {
+        case errors.Is(err, domain.ErrInternal):
+            sendError(w, http.StatusInternalServerError, "Failed to update task", nil)
+        default:
             sendError(w, http.StatusBadRequest, "Failed to update task", err)
         }
         return
@@ -120,8 +174,53 @@ func (h *TaskHandler) updateTask(w http.ResponseWriter, r *http.Request, id int)
     sendJSON(w, http.StatusOK, task)
 }
 
+func (h *TaskHandler) patchTask(w http.ResponseWriter, r *http.Request, id int) {
+    body, err := io.ReadAll(r.Body)
+    if err != nil {
+        sendBodyError(w, "Failed to read request body", err)
+        return
+    }
+    defer r.Body.Close()
+    
+    var task *domain.Task
+    switch mediaType(r) {
+    case "application/merge-patch+json":
+        task, err = h.service.WithContext(r.Context()).MergePatchTask(id, body)
+    case "application/json-patch+json":
+        task, err = h.service.WithContext(r.Context()).JSONPatchTask(id, body)
+    default:
+        w.Header().Set("Accept-Patch", "application/merge-patch+json, application/json-patch+json")
+        sendError(w, http.StatusUnsupportedMediaType, "Unsupported patch format", fmt.Errorf("unsupported content type '%s'", r.Header.Get("Content-Type")))
+        return
+    }
+    
+    if err != nil {
+        switch {
+        case errors.Is(err, patch.ErrTestFailed):
+            sendError(w, http.StatusConflict, "Patch test failed", err)
+        case errors.Is(err, patch.ErrMissingPath):
+            sendError(w, http.StatusUnprocessableEntity, "Failed to apply patch", err)
+        case errors.Is(err, domain.ErrForbidden):
+            sendError(w, http.StatusForbidden, "Forbidden", err)
+        case errors.Is(err, domain.ErrNotFound):
+            sendError(w, http.StatusNotFound, "Task not found", err)
+        case errors.Is(err, domain.ErrInternal):
+            sendError(w, http.StatusInternalServerError, "Failed to patch task", nil)
+        default:
+            sendError(w, http.StatusBadRequest, "Failed to patch task", err)
+        }
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, task)
+}
+
 func (h *TaskHandler) deleteTask(w http.ResponseWriter, r *http.Request, id int) {
-    if err := h.service.DeleteTask(id); err != nil {
+    if err := h.service.WithContext(r.Context()).DeleteTask(id); err != nil {
+        if errors.Is(err, domain.ErrForbidden) {
+            sendError(w, http.StatusForbidden, "Forbidden", err)
+            return
+        }
         sendError(w, http.StatusNotFound, "Task not found", err)
         return
     }
@@ -129,6 +228,11 @@ func (h *TaskHandler) deleteTask(w http.ResponseWriter, r *http.Request, id int)
     w.WriteHeader(http.StatusNoContent)
 }
 
+func mediaType(r *http.Request) string {
+    contentType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+    return strings.ToLower(strings.TrimSpace(contentType))
+}
+
 func sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(statusCode)
@@ -152,4 +256,26 @@ func sendError(w http.ResponseWriter, statusCode int, message string, err error)
     }
     
     json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+}
+
+// sendQuotaError answers 409 with the code QUOTA_EXCEEDED, so that clients
+// can tell a full quota from other conflicts.
+func sendQuotaError(w http.ResponseWriter, message string, err error) {
+    sendJSON(w, http.StatusConflict, map[string]interface{}{
+        "error":   message,
+        "status":  http.StatusConflict,
+        "code":    domain.QuotaExceededCode,
+        "details": err.Error(),
+    })
+}
+
+// sendBodyError reports a request body that couldn't be read or decoded.
+// Bodies cut off by middleware.MaxBodySize get 413.
+func sendBodyError(w http.ResponseWriter, message string, err error) {
+    var maxBytesErr *http.MaxBytesError
+    if errors.As(err, &maxBytesErr) {
+        sendError(w, http.StatusRequestEntityTooLarge, "Request body is too large", err)
+        return
+    }
+    sendError(w, http.StatusBadRequest, message, err)
+}
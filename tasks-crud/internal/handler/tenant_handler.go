@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/service"
+)
+
+type TenantHandler struct {
+    service *service.TenantService
+}
+
+func NewTenantHandler(service *service.TenantService) *TenantHandler {
+    return &TenantHandler{
+        service: service,
+    }
+}
+
+func (h *TenantHandler) GetAllTenants(w http.ResponseWriter, r *http.Request) {
+    tenants, err := h.service.GetAllTenants()
+    if err != nil {
+        sendError(w, http.StatusInternalServerError, "Failed to get tenants", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, tenants)
+}
+
+func (h *TenantHandler) GetTenant(w http.ResponseWriter, r *http.Request) {
+    tenant, err := h.service.GetTenant(mux.Vars(r)["id"])
+    if err != nil {
+        sendTenantError(w, "Failed to get tenant", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, tenant)
+}
+
+func (h *TenantHandler) CreateTenant(w http.ResponseWriter, r *http.Request) {
+    var req domain.CreateTenantRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        sendBodyError(w, "Invalid JSON", err)
+        return
+    }
+    defer r.Body.Close()
+    
+    tenant, err := h.service.CreateTenant(req)
+    if err != nil {
+        sendTenantError(w, "Failed to create tenant", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusCreated, tenant)
+}
+
+func (h *TenantHandler) SuspendTenant(w http.ResponseWriter, r *http.Request) {
+    tenant, err := h.service.SuspendTenant(mux.Vars(r)["id"])
+    if err != nil {
+        sendTenantError(w, "Failed to suspend tenant", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, tenant)
+}
+
+func (h *TenantHandler) ResumeTenant(w http.ResponseWriter, r *http.Request) {
+    tenant, err := h.service.ResumeTenant(mux.Vars(r)["id"])
+    if err != nil {
+        sendTenantError(w, "Failed to resume tenant", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, tenant)
+}
+
+// DeleteTenant removes the tenant with all its tasks, shares, filters,
+// feeds, accounts and API keys.
+func (h *TenantHandler) DeleteTenant(w http.ResponseWriter, r *http.Request) {
+    if err := h.service.DeleteTenant(mux.Vars(r)["id"]); err != nil {
+        sendTenantError(w, "Failed to delete tenant", err)
+        return
+    }
+    
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func sendTenantError(w http.ResponseWriter, message string, err error) {
+    switch {
+    case errors.Is(err, domain.ErrNotFound):
+        sendError(w, http.StatusNotFound, message, err)
+    case errors.Is(err, domain.ErrAlreadyExists):
+        sendError(w, http.StatusConflict, message, err)
+    case errors.Is(err, domain.ErrInternal):
+        sendError(w, http.StatusInternalServerError, message, nil)
+    default:
+        sendError(w, http.StatusBadRequest, message, err)
+    }
+}
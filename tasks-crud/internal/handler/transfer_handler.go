@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/query"
+	"tasks-crud/internal/service"
+	"tasks-crud/internal/transfer"
+)
+
+const (
+    maxImportBodySize = 10 << 20
+    exportFlushEvery  = 100
+)
+
+type TransferHandler struct {
+    service *service.TaskService
+}
+
+func NewTransferHandler(service *service.TaskService) *TransferHandler {
+    return &TransferHandler{
+        service: service,
+    }
+}
+
+// Export streams the tasks matching ?q= in the format given by ?format=
+// (json by default).
+func (h *TransferHandler) Export(w http.ResponseWriter, r *http.Request) {
+    format := r.URL.Query().Get("format")
+    if format == "" {
+        format = domain.TransferFormatJSON
+    }
+    
+    q := r.URL.Query().Get("q")
+    tasks, err := h.service.WithContext(r.Context()).ListTasks(q)
+    if err != nil {
+        var parseErr *query.ParseError
+        if errors.As(err, &parseErr) {
+            sendQueryError(w, q, parseErr)
+            return
+        }
+        sendError(w, http.StatusInternalServerError, "Failed to list tasks", err)
+        return
+    }
+    
+    encoder, err := transfer.NewEncoder(format, w)
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Invalid format", err)
+        return
+    }
+    
+    contentType, extension := transfer.ContentType(format)
+    w.Header().Set("Content-Type", contentType)
+    w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="tasks-%s.%s"`, time.Now().Format("20060102"), extension))
+    w.Header().Set("X-Total-Count", strconv.Itoa(len(tasks)))
+    w.WriteHeader(http.StatusOK)
+    
+    flusher, _ := w.(http.Flusher)
+    for i, task := range tasks {
+        if err := encoder.Encode(task); err != nil {
+            log.Printf("export: failed to write task %d: %v", task.ID, err)
+            return
+        }
+        if flusher != nil && (i+1)%exportFlushEvery == 0 {
+            flusher.Flush()
+        }
+    }
+    
+    if err := encoder.Close(); err != nil {
+        log.Printf("export: failed to finish: %v", err)
+    }
+}
+
+// Import creates tasks from the request body. The format comes from
+// ?format= or the Content-Type; with ?dry_run=true nothing is saved and the
+// response reports what would happen.
+func (h *TransferHandler) Import(w http.ResponseWriter, r *http.Request) {
+    format := r.URL.Query().Get("format")
+    if format == "" {
+        format = transfer.FormatFromContentType(r.Header.Get("Content-Type"))
+    }
+    if format == "" {
+        sendError(w, http.StatusUnsupportedMediaType, "Unsupported import format", fmt.Errorf("pass ?format=csv|json|markdown|todotxt|ics or a matching Content-Type"))
+        return
+    }
+    
+    dryRun := false
+    if value := r.URL.Query().Get("dry_run"); value != "" {
+        parsed, err := strconv.ParseBool(value)
+        if err != nil {
+            sendError(w, http.StatusBadRequest, "Invalid dry_run", err)
+            return
+        }
+        dryRun = parsed
+    }
+    
+    body := http.MaxBytesReader(w, r.Body, maxImportBodySize)
+    defer body.Close()
+    
+    records, err := transfer.Decode(format, body)
+    if err != nil {
+        var maxBytesErr *http.MaxBytesError
+        if errors.As(err, &maxBytesErr) {
+            sendError(w, http.StatusRequestEntityTooLarge, "Import file is too large", err)
+            return
+        }
+        sendError(w, http.StatusBadRequest, "Failed to parse import file", err)
+        return
+    }
+    
+    resp, err := h.service.WithContext(r.Context()).ImportTasks(records, dryRun)
+    if err != nil {
+        sendError(w, http.StatusBadRequest, "Failed to import tasks", err)
+        return
+    }
+    
+    sendJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,398 @@
+// Package ical reads and writes the subset of iCalendar (RFC 5545) needed
+// to exchange to-dos: content lines with parameters, line folding, text
+// escaping, nested components and DATE / DATE-TIME values.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+    dateLayout     = "20060102"
+    dateTimeLayout = "20060102T150405"
+    maxLineOctets  = 75
+)
+
+// Property is a single content line, e.g. DUE;VALUE=DATE:20261101.
+// Value is kept raw; use Text for TEXT values.
+type Property struct {
+    Name   string
+    Params map[string]string
+    Value  string
+}
+
+// Text returns the value with TEXT escaping removed.
+func (p *Property) Text() string {
+    return Unescape(p.Value)
+}
+
+// Time parses a DATE or DATE-TIME value. Floating times and unknown TZIDs
+// are read as UTC.
+func (p *Property) Time() (time.Time, error) {
+    return ParseTime(p.Value, p.Params["TZID"])
+}
+
+// Component is a BEGIN:name ... END:name block. Line is the 1-based line
+// of its BEGIN in the parsed input.
+type Component struct {
+    Name       string
+    Properties []*Property
+    Components []*Component
+    Line       int
+}
+
+func NewComponent(name string) *Component {
+    return &Component{Name: name}
+}
+
+// Get returns the first property with the given name, or nil.
+func (c *Component) Get(name string) *Property {
+    for _, prop := range c.Properties {
+        if strings.EqualFold(prop.Name, name) {
+            return prop
+        }
+    }
+    return nil
+}
+
+// GetAll returns every property with the given name.
+func (c *Component) GetAll(name string) []*Property {
+    var props []*Property
+    for _, prop := range c.Properties {
+        if strings.EqualFold(prop.Name, name) {
+            props = append(props, prop)
+        }
+    }
+    return props
+}
+
+// Add appends a property with a raw value.
+func (c *Component) Add(name, value string, params map[string]string) {
+    c.Properties = append(c.Properties, &Property{Name: name, Params: params, Value: value})
+}
+
+// AddText appends a property with a TEXT value, escaping it.
+func (c *Component) AddText(name, value string) {
+    c.Add(name, Escape(value), nil)
+}
+
+// AddTime appends a UTC DATE-TIME property.
+func (c *Component) AddTime(name string, t time.Time) {
+    c.Add(name, FormatDateTime(t), nil)
+}
+
+// AddDate appends a DATE property.
+func (c *Component) AddDate(name string, t time.Time) {
+    c.Add(name, t.Format(dateLayout), map[string]string{"VALUE": "DATE"})
+}
+
+// Find returns every component named name at any depth below c.
+func (c *Component) Find(name string) []*Component {
+    var found []*Component
+    for _, child := range c.Components {
+        if strings.EqualFold(child.Name, name) {
+            found = append(found, child)
+        }
+        found = append(found, child.Find(name)...)
+    }
+    return found
+}
+
+// Writer writes folded CRLF content lines. Begin and End may be called
+// directly to stream a component whose children are written one by one.
+type Writer struct {
+    w *bufio.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+    return &Writer{w: bufio.NewWriter(w)}
+}
+
+func (w *Writer) Begin(name string) error {
+    return w.line("BEGIN:" + name)
+}
+
+func (w *Writer) End(name string) error {
+    return w.line("END:" + name)
+}
+
+func (w *Writer) Property(prop *Property) error {
+    var b strings.Builder
+    b.WriteString(prop.Name)
+    for _, name := range sortedKeys(prop.Params) {
+        b.WriteString(";" + name + "=" + quoteParam(prop.Params[name]))
+    }
+    b.WriteString(":" + prop.Value)
+    return w.line(b.String())
+}
+
+// Component writes c and all of its children.
+func (w *Writer) Component(c *Component) error {
+    if err := w.Begin(c.Name); err != nil {
+        return err
+    }
+    for _, prop := range c.Properties {
+        if err := w.Property(prop); err != nil {
+            return err
+        }
+    }
+    for _, child := range c.Components {
+        if err := w.Component(child); err != nil {
+            return err
+        }
+    }
+    return w.End(c.Name)
+}
+
+func (w *Writer) Flush() error {
+    return w.w.Flush()
+}
+
+// line folds s at 75 octets without splitting UTF-8 sequences.
+func (w *Writer) line(s string) error {
+    limit := maxLineOctets
+    for len(s) > limit {
+        cut := limit
+        for cut > 0 && !utf8.RuneStart(s[cut]) {
+            cut--
+        }
+        if _, err := w.w.WriteString(s[:cut] + "\r\n "); err != nil {
+            return err
+        }
+        s = s[cut:]
+        limit = maxLineOctets - 1
+    }
+    _, err := w.w.WriteString(s + "\r\n")
+    return err
+}
+
+// Encode writes c as a complete iCalendar stream.
+func Encode(w io.Writer, c *Component) error {
+    writer := NewWriter(w)
+    if err := writer.Component(c); err != nil {
+        return err
+    }
+    return writer.Flush()
+}
+
+// Parse reads every top-level component from r.
+func Parse(r io.Reader) ([]*Component, error) {
+    lines, err := unfold(r)
+    if err != nil {
+        return nil, err
+    }
+    
+    var roots []*Component
+    var stack []*Component
+    for _, line := range lines {
+        prop, err := parseLine(line.text)
+        if err != nil {
+            return nil, fmt.Errorf("line %d: %w", line.number, err)
+        }
+    
+        switch strings.ToUpper(prop.Name) {
+        case "BEGIN":
+            component := &Component{Name: strings.ToUpper(prop.Value), Line: line.number}
+            if len(stack) > 0 {
+                parent := stack[len(stack)-1]
+                parent.Components = append(parent.Components, component)
+            } else {
+                roots = append(roots, component)
+            }
+            stack = append(stack, component)
+        case "END":
+            if len(stack) == 0 || !strings.EqualFold(stack[len(stack)-1].Name, prop.Value) {
+                return nil, fmt.Errorf("line %d: unexpected END:%s", line.number, prop.Value)
+            }
+            stack = stack[:len(stack)-1]
+        default:
+            if len(stack) == 0 {
+                return nil, fmt.Errorf("line %d: property %s outside of a component", line.number, prop.Name)
+            }
+            current := stack[len(stack)-1]
+            current.Properties = append(current.Properties, prop)
+        }
+    }
+    
+    if len(stack) > 0 {
+        return nil, fmt.Errorf("component %s starting at line %d is not closed", stack[len(stack)-1].Name, stack[len(stack)-1].Line)
+    }
+    
+    return roots, nil
+}
+
+type contentLine struct {
+    number int
+    text   string
+}
+
+func unfold(r io.Reader) ([]contentLine, error) {
+    scanner := bufio.NewScanner(r)
+    scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+    
+    var lines []contentLine
+    number := 0
+    for scanner.Scan() {
+        number++
+        text := strings.TrimSuffix(scanner.Text(), "\r")
+        if number == 1 {
+            text = strings.TrimPrefix(text, "\ufeff")
+        }
+    
+        if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(lines) > 0 {
+            lines[len(lines)-1].text += text[1:]
+            continue
+        }
+        if strings.TrimSpace(text) == "" {
+            continue
+        }
+        lines = append(lines, contentLine{number: number, text: text})
+    }
+    if err := scanner.Err(); err != nil {
+        return nil, err
+    }
+    
+    return lines, nil
+}
+
+// parseLine splits name *(";" param) ":" value, honouring quoted
+// parameter values that may contain ':', ';' and ','.
+func parseLine(line string) (*Property, error) {
+    i := strings.IndexAny(line, ";:")
+    if i <= 0 {
+        return nil, fmt.Errorf("malformed content line %q", line)
+    }
+    
+    prop := &Property{Name: strings.ToUpper(line[:i])}
+    rest := line[i:]
+    for rest[0] == ';' {
+        rest = rest[1:]
+        eq := strings.IndexByte(rest, '=')
+        if eq <= 0 {
+            return nil, fmt.Errorf("malformed parameter in %s", prop.Name)
+        }
+        name := strings.ToUpper(rest[:eq])
+        rest = rest[eq+1:]
+    
+        var value string
+        if strings.HasPrefix(rest, `"`) {
+            end := strings.IndexByte(rest[1:], '"')
+            if end < 0 {
+                return nil, fmt.Errorf("unterminated quoted parameter %s", name)
+            }
+            value = rest[1 : end+1]
+            rest = rest[end+2:]
+        } else {
+            end := strings.IndexAny(rest, ";:")
+            if end < 0 {
+                return nil, fmt.Errorf("property %s has no value", prop.Name)
+            }
+            value = rest[:end]
+            rest = rest[end:]
+        }
+    
+        if prop.Params == nil {
+            prop.Params = make(map[string]string)
+        }
+        prop.Params[name] = value
+    
+        if rest == "" {
+            return nil, fmt.Errorf("property %s has no value", prop.Name)
+        }
+    }
+    
+    if rest[0] != ':' {
+        return nil, fmt.Errorf("malformed content line %q", line)
+    }
+    prop.Value = rest[1:]
+    
+    return prop, nil
+}
+
+// Escape applies TEXT escaping: backslash, semicolon, comma and newline.
+func Escape(s string) string {
+    return textEscaper.Replace(s)
+}
+
+func Unescape(s string) string {
+    return textUnescaper.Replace(s)
+}
+
+var (
+    textEscaper   = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+    textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+// SplitList splits a comma-separated TEXT list such as CATEGORIES,
+// leaving escaped commas inside items.
+func SplitList(value string) []string {
+    var items []string
+    var b strings.Builder
+    for i := 0; i < len(value); i++ {
+        switch {
+        case value[i] == '\\' && i+1 < len(value):
+            b.WriteByte(value[i])
+            b.WriteByte(value[i+1])
+            i++
+        case value[i] == ',':
+            items = append(items, Unescape(b.String()))
+            b.Reset()
+        default:
+            b.WriteByte(value[i])
+        }
+    }
+    return append(items, Unescape(b.String()))
+}
+
+// FormatDateTime formats t as a UTC DATE-TIME, e.g. 20261101T090000Z.
+func FormatDateTime(t time.Time) string {
+    return t.UTC().Format(dateTimeLayout) + "Z"
+}
+
+// ParseTime parses a DATE (20261101) or DATE-TIME (20261101T090000,
+// optionally with a Z suffix or a TZID).
+func ParseTime(value, tzid string) (time.Time, error) {
+    value = strings.TrimSpace(value)
+    
+    if len(value) == len(dateLayout) {
+        return time.Parse(dateLayout, value)
+    }
+    
+    if strings.HasSuffix(value, "Z") {
+        return time.Parse(dateTimeLayout, strings.TrimSuffix(value, "Z"))
+    }
+    
+    location := time.UTC
+    if tzid != "" {
+        if loaded, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+            location = loaded
+        }
+    }
+    t, err := time.ParseInLocation(dateTimeLayout, value, location)
+    if err != nil {
+        return time.Time{}, fmt.Errorf("invalid date-time '%s'", value)
+    }
+    return t.UTC(), nil
+}
+
+func quoteParam(value string) string {
+    if strings.ContainsAny(value, ";:,") {
+        return `"` + value + `"`
+    }
+    return value
+}
+
+func sortedKeys(params map[string]string) []string {
+    keys := make([]string, 0, len(params))
+    for key := range params {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// AdminToken guards operator endpoints with a static bearer token, which
+// is separate from user credentials. An empty token disables them.
+func AdminToken(token string) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            if token == "" {
+                writeError(w, http.StatusNotFound, "Not found", fmt.Errorf("admin endpoints are disabled"))
+                return
+            }
+            
+            scheme, credentials, err := ParseAuthorization(r.Header.Get("Authorization"))
+            if err != nil {
+                unauthorized(w, err)
+                return
+            }
+            
+            if !strings.EqualFold(scheme, "bearer") || subtle.ConstantTimeCompare([]byte(credentials), []byte(token)) != 1 {
+                unauthorized(w, fmt.Errorf("invalid admin token"))
+                return
+            }
+            
+            next.ServeHTTP(w, r)
+        })
+    }
+}
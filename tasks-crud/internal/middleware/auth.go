@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"tasks-crud/internal/domain"
+)
+
+const (
+    AuthRealm     = "tasks-crud"
+    AuthMethodJWT = domain.AuthMethodJWT
+)
+
+var errMissingToken = errors.New("missing bearer token")
+
+// Authenticator turns the scheme and credentials of an Authorization
+// header into the caller they belong to.
+type Authenticator interface {
+    Authenticate(scheme, credentials string) (*domain.Principal, error)
+}
+
+// JWTVerifier checks the signature and the standard claims of access
+// tokens. Only HS256, RS256 and EdDSA are accepted, and the algorithm must
+// match the type of the key that verifies it.
+type JWTVerifier struct {
+    Keys     KeySet
+    Issuer   string
+    Audience string
+    Skew     time.Duration
+    // Sessions, when set, is asked about tokens carrying a sid claim so
+    // that logging out invalidates access tokens before they expire.
+    Sessions SessionChecker
+    // LocalKeys, when set, verifies the tokens this server issues itself,
+    // under LocalIssuer: a token claiming that issuer must be signed with
+    // one of them, and tokens of any other issuer are marked External.
+    LocalKeys   KeySet
+    LocalIssuer string
+}
+
+type SessionChecker interface {
+    SessionActive(issuer, id string) bool
+}
+
+type accessClaims struct {
+    jwt.RegisteredClaims
+    Scope     string   `json:"scope,omitempty"`
+    Scp       []string `json:"scp,omitempty"`
+    SessionID string   `json:"sid,omitempty"`
+    TenantID  string   `json:"tenant,omitempty"`
+    Role      string   `json:"role,omitempty"`
+}
+
+// Verify parses token and returns the principal it names. Tokens must
+// have exp and sub; iss and aud are checked when the verifier sets them
+// (iss only on tokens not issued by this server).
+func (v *JWTVerifier) Verify(token string) (*domain.Principal, error) {
+    options := []jwt.ParserOption{
+        jwt.WithValidMethods([]string{"HS256", "RS256", "EdDSA"}),
+        jwt.WithExpirationRequired(),
+        jwt.WithLeeway(v.Skew),
+        jwt.WithIssuedAt(),
+    }
+    if v.Audience != "" {
+        options = append(options, jwt.WithAudience(v.Audience))
+    }
+    
+    var claims accessClaims
+    _, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+        kid, _ := t.Header["kid"].(string)
+        if v.local(claims.Issuer) {
+            return v.LocalKeys.Key(kid, t.Method.Alg())
+        }
+        return v.Keys.Key(kid, t.Method.Alg())
+    }, options...)
+    if err != nil {
+        return nil, err
+    }
+    
+    if claims.Subject == "" {
+        return nil, fmt.Errorf("token has no subject")
+    }
+    
+    // The server's own tokens have an issuer of their own.
+    if v.Issuer != "" && !v.local(claims.Issuer) && claims.Issuer != v.Issuer {
+        return nil, fmt.Errorf("token has invalid issuer")
+    }
+    
+    if claims.SessionID != "" && v.Sessions != nil && !v.Sessions.SessionActive(claims.Issuer, claims.SessionID) {
+        return nil, fmt.Errorf("session has been revoked")
+    }
+    
+    // A role claim this server doesn't know can't be enforced, so the
+    // token is refused rather than given full access.
+    role := domain.Role(claims.Role)
+    if role != "" && !role.Valid() {
+        return nil, fmt.Errorf("unknown role '%s'", claims.Role)
+    }
+    
+    scopes := claims.Scp
+    if claims.Scope != "" {
+        scopes = append(scopes, strings.Fields(claims.Scope)...)
+    }
+    
+    return &domain.Principal{
+        Subject:   claims.Subject,
+        Issuer:    claims.Issuer,
+        Scopes:    scopes,
+        Method:    AuthMethodJWT,
+        SessionID: claims.SessionID,
+        TenantID:  claims.TenantID,
+        Role:      role,
+        ExpiresAt: claims.ExpiresAt.Time,
+        External:  v.LocalKeys != nil && !v.local(claims.Issuer),
+    }, nil
+}
+
+func (v *JWTVerifier) local(issuer string) bool {
+    return v.LocalKeys != nil && issuer == v.LocalIssuer
+}
+
+// Authenticate accepts bearer tokens only.
+func (v *JWTVerifier) Authenticate(scheme, token string) (*domain.Principal, error) {
+    if !strings.EqualFold(scheme, "bearer") {
+        return nil, fmt.Errorf("authorization scheme must be Bearer")
+    }
+    return v.Verify(token)
+}
+
+// RequireAuth requires valid credentials on every request except those
+// whose path starts with one of the public prefixes, and stores the
+// caller in the request context (see domain.PrincipalFromContext).
+// Failures get 401 with a WWW-Authenticate challenge (RFC 6750). A
+// verified client certificate stands in for a missing Authorization
+// header when auth is a CertificateAuthenticator.
+func RequireAuth(auth Authenticator, public ...string) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            for _, prefix := range public {
+                if strings.HasPrefix(r.URL.Path, prefix) {
+                    next.ServeHTTP(w, r)
+                    return
+                }
+            }
+            
+            var principal *domain.Principal
+            scheme, credentials, err := ParseAuthorization(r.Header.Get("Authorization"))
+            if err == nil {
+                principal, err = auth.Authenticate(scheme, credentials)
+            } else if certAuth, ok := auth.(CertificateAuthenticator); ok && errors.Is(err, errMissingToken) && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+                principal, err = certAuth.AuthenticateCertificate(r.TLS.VerifiedChains[0][0])
+            }
+            if err != nil {
+                unauthorized(w, err)
+                return
+            }
+            
+            next.ServeHTTP(w, r.WithContext(domain.WithPrincipal(r.Context(), principal)))
+        })
+    }
+}
+
+// ParseAuthorization splits an Authorization header (or the gRPC
+// "authorization" metadata) into scheme and credentials.
+func ParseAuthorization(header string) (string, string, error) {
+    if header == "" {
+        return "", "", errMissingToken
+    }
+    
+    scheme, credentials, found := strings.Cut(strings.TrimSpace(header), " ")
+    credentials = strings.TrimSpace(credentials)
+    if !found || credentials == "" {
+        return "", "", fmt.Errorf("malformed authorization header")
+    }
+    
+    return scheme, credentials, nil
+}
+
+// unauthorized writes a 401. A missing token gets a bare challenge, as
+// RFC 6750 asks; an invalid one gets error="invalid_token".
+func unauthorized(w http.ResponseWriter, err error) {
+    challenge := fmt.Sprintf(`Bearer realm="%s"`, AuthRealm)
+    if !errors.Is(err, errMissingToken) {
+        challenge += fmt.Sprintf(`, error="invalid_token", error_description="%s"`, strings.ReplaceAll(err.Error(), `"`, `'`))
+    }
+    w.Header().Set("WWW-Authenticate", challenge)
+    writeError(w, http.StatusUnauthorized, "Unauthorized", err)
+}
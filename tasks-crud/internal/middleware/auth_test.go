@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"tasks-crud/internal/domain"
+)
+
+func signHS256(t *testing.T, secret, issuer, subject string) string {
+    token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+        Issuer:    issuer,
+        Subject:   subject,
+        ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+    }).SignedString([]byte(secret))
+    if err != nil {
+        t.Fatal(err)
+    }
+    return token
+}
+
+func localVerifier() *JWTVerifier {
+    return &JWTVerifier{
+        Keys:        SecretKey("external-secret"),
+        LocalKeys:   SecretKey("local-secret"),
+        LocalIssuer: AuthRealm,
+    }
+}
+
+// Subjects are only unique per issuer: user 1 of another issuer must not
+// own what local user 1 owns.
+func TestExternalSubjectsAreNamespacedByIssuer(t *testing.T) {
+    v := localVerifier()
+    
+    local, err := v.Verify(signHS256(t, "local-secret", AuthRealm, "1"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    external, err := v.Verify(signHS256(t, "external-secret", "https://idp.example.com", "1"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    if local.External || local.Owner() != "1" {
+        t.Fatalf("local principal: external %v, owner %q", local.External, local.Owner())
+    }
+    if !external.External || external.Owner() == local.Owner() {
+        t.Fatalf("external principal: external %v, owner %q", external.External, external.Owner())
+    }
+    if PrincipalClient(external, "") == PrincipalClient(local, "") {
+        t.Fatal("external and local principals share a rate limit key")
+    }
+}
+
+func TestOwnerEscapesIssuer(t *testing.T) {
+    a := &domain.Principal{Issuer: "a:b", Subject: "c", External: true}
+    b := &domain.Principal{Issuer: "a", Subject: "b:c", External: true}
+    if a.Owner() == b.Owner() {
+        t.Fatalf("issuer %q and %q give the same owner %q", a.Issuer, b.Issuer, a.Owner())
+    }
+}
+
+func TestLocalIssuerRequiresLocalKey(t *testing.T) {
+    v := localVerifier()
+    if _, err := v.Verify(signHS256(t, "external-secret", AuthRealm, "1")); err == nil {
+        t.Fatal("token claiming the local issuer was accepted with another key")
+    }
+}
+
+func TestIssuerCheckSkipsLocalTokens(t *testing.T) {
+    v := localVerifier()
+    v.Issuer = "https://idp.example.com"
+    
+    if _, err := v.Verify(signHS256(t, "local-secret", AuthRealm, "1")); err != nil {
+        t.Fatalf("local token: %v", err)
+    }
+    if _, err := v.Verify(signHS256(t, "external-secret", "https://other.example.com", "1")); err == nil {
+        t.Fatal("token of an unexpected issuer was accepted")
+    }
+}
+
+type refresherFunc func(req domain.RefreshRequest) (*domain.TokenResponse, error)
+
+func (f refresherFunc) Refresh(req domain.RefreshRequest) (*domain.TokenResponse, error) {
+    return f(req)
+}
+
+func TestWebSession(t *testing.T) {
+    v := localVerifier()
+    valid := signHS256(t, "local-secret", AuthRealm, "1")
+    refresher := refresherFunc(func(req domain.RefreshRequest) (*domain.TokenResponse, error) {
+        if req.RefreshToken != "good" {
+            return nil, http.ErrNoCookie
+        }
+        return &domain.TokenResponse{AccessToken: valid, RefreshToken: "next"}, nil
+    })
+    
+    var gotAuth string
+    handler := WebSession(v, refresher, "/login", func(r *http.Request) bool {
+        return !strings.HasPrefix(r.URL.Path, "/api/")
+    })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        gotAuth = r.Header.Get("Authorization")
+    }))
+    
+    serve := func(method, path string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+        gotAuth = ""
+        req := httptest.NewRequest(method, path, nil)
+        for _, cookie := range cookies {
+            req.AddCookie(cookie)
+        }
+        rec := httptest.NewRecorder()
+        handler.ServeHTTP(rec, req)
+        return rec
+    }
+    
+    if rec := serve(http.MethodGet, "/", &http.Cookie{Name: SessionCookieName, Value: valid}); rec.Code != http.StatusOK || gotAuth != "Bearer "+valid {
+        t.Fatalf("valid session: got %d with Authorization %q", rec.Code, gotAuth)
+    }
+    
+    if serve(http.MethodGet, "/api/v1/tasks", &http.Cookie{Name: SessionCookieName, Value: valid}); gotAuth != "" {
+        t.Fatal("session cookie was accepted by the API")
+    }
+    
+    rec := serve(http.MethodGet, "/tasks/1/edit")
+    if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/login?return=%2Ftasks%2F1%2Fedit" {
+        t.Fatalf("no session: got %d to %q, want a redirect to the login page", rec.Code, rec.Header().Get("Location"))
+    }
+    if rec := serve(http.MethodPost, "/tasks"); rec.Code != http.StatusUnauthorized {
+        t.Fatalf("no session on POST: got %d, want 401", rec.Code)
+    }
+    
+    rec = serve(http.MethodGet, "/", &http.Cookie{Name: SessionCookieName, Value: "expired"}, &http.Cookie{Name: RefreshCookieName, Value: "good"})
+    if rec.Code != http.StatusOK || gotAuth != "Bearer "+valid {
+        t.Fatalf("expired session: got %d with Authorization %q", rec.Code, gotAuth)
+    }
+    if cookies := rec.Result().Cookies(); len(cookies) != 2 || cookies[1].Value != "next" || !cookies[1].HttpOnly {
+        t.Fatalf("refreshed session cookies: %v", cookies)
+    }
+}
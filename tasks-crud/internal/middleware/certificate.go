@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"tasks-crud/internal/domain"
+)
+
+// AuthMethodCertificate is the Principal.Method of requests authenticated
+// by a TLS client certificate.
+const AuthMethodCertificate = "client_certificate"
+
+// CertificateAuthenticator is implemented by authenticators that also
+// accept client certificates verified during the TLS handshake.
+type CertificateAuthenticator interface {
+    AuthenticateCertificate(cert *x509.Certificate) (*domain.Principal, error)
+}
+
+// ClientCertificates turns verified client certificates into principals.
+// The subject is "cert:" followed by the common name, or the first URI or
+// email SAN when there is none, so certificates can't pass for accounts.
+// The first organization, if any, is the tenant.
+type ClientCertificates struct{}
+
+func (ClientCertificates) AuthenticateCertificate(cert *x509.Certificate) (*domain.Principal, error) {
+    identity := cert.Subject.CommonName
+    if identity == "" && len(cert.URIs) > 0 {
+        identity = cert.URIs[0].String()
+    }
+    if identity == "" && len(cert.EmailAddresses) > 0 {
+        identity = cert.EmailAddresses[0]
+    }
+    if identity == "" {
+        return nil, fmt.Errorf("client certificate has no common name, URI or email")
+    }
+    
+    principal := &domain.Principal{
+        Subject:   "cert:" + identity,
+        Issuer:    cert.Issuer.String(),
+        Method:    AuthMethodCertificate,
+        ExpiresAt: cert.NotAfter,
+    }
+    if len(cert.Subject.Organization) > 0 {
+        principal.TenantID = cert.Subject.Organization[0]
+    }
+    
+    return principal, nil
+}
+
+// AuthenticateCertificate delegates to Certificates; without it client
+// certificates don't authenticate anyone.
+func (c *Credentials) AuthenticateCertificate(cert *x509.Certificate) (*domain.Principal, error) {
+    if c.Certificates == nil {
+        return nil, errMissingToken
+    }
+    return c.Certificates.AuthenticateCertificate(cert)
+}
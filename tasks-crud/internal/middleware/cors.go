@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig lists what browsers on other origins may do. An origin of
+// "*" allows any origin, but never with credentials.
+type CORSConfig struct {
+    Origins        []string
+    Methods        []string
+    Headers        []string
+    ExposedHeaders []string
+    Credentials    bool
+    MaxAge         time.Duration
+}
+
+// Validate rejects "*" together with credentials: that would let every
+// site on the web make requests with the user's cookies and read the
+// answers.
+func (c CORSConfig) Validate() error {
+    for _, origin := range c.Origins {
+        if origin == "*" && c.Credentials {
+            return fmt.Errorf("the origin \"*\" can't be combined with credentials; list the allowed origins")
+        }
+    }
+    return nil
+}
+
+// CORS answers preflight requests and adds Access-Control-* headers to
+// requests from allowed origins. It must wrap the router rather than be
+// registered on it: preflights carry no credentials and match no route.
+// With no origins configured it does nothing, and requests from other
+// origins are left to the browser to block.
+func CORS(config CORSConfig) func(http.Handler) http.Handler {
+    anyOrigin := false
+    origins := make(map[string]bool, len(config.Origins))
+    for _, origin := range config.Origins {
+        if origin == "*" {
+            anyOrigin = true
+        }
+        origins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+    }
+    
+    methods := strings.Join(config.Methods, ", ")
+    headers := strings.Join(config.Headers, ", ")
+    exposed := strings.Join(config.ExposedHeaders, ", ")
+    maxAge := strconv.Itoa(int(config.MaxAge.Seconds()))
+    
+    return func(next http.Handler) http.Handler {
+        if len(origins) == 0 {
+            return next
+        }
+        
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            w.Header().Add("Vary", "Origin")
+            origin := r.Header.Get("Origin")
+            preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+            if preflight {
+                w.Header().Add("Vary", "Access-Control-Request-Method")
+                w.Header().Add("Vary", "Access-Control-Request-Headers")
+            }
+            
+            if origin == "" || !(anyOrigin || origins[strings.ToLower(origin)]) {
+                if preflight {
+                    w.WriteHeader(http.StatusNoContent)
+                    return
+                }
+                next.ServeHTTP(w, r)
+                return
+            }
+            
+            // The wildcard is never turned into the request's origin, and
+            // browsers don't send credentials to it (see Validate).
+            if anyOrigin {
+                w.Header().Set("Access-Control-Allow-Origin", "*")
+            } else {
+                w.Header().Set("Access-Control-Allow-Origin", origin)
+                if config.Credentials {
+                    w.Header().Set("Access-Control-Allow-Credentials", "true")
+                }
+            }
+            
+            if !preflight {
+                if exposed != "" {
+                    w.Header().Set("Access-Control-Expose-Headers", exposed)
+                }
+                next.ServeHTTP(w, r)
+                return
+            }
+            
+            w.Header().Set("Access-Control-Allow-Methods", methods)
+            if headers != "" {
+                w.Header().Set("Access-Control-Allow-Headers", headers)
+            }
+            if config.MaxAge > 0 {
+                w.Header().Set("Access-Control-Max-Age", maxAge)
+            }
+            w.WriteHeader(http.StatusNoContent)
+        })
+    }
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSRejectsWildcardWithCredentials(t *testing.T) {
+    if err := (CORSConfig{Origins: []string{"*"}, Credentials: true}).Validate(); err == nil {
+        t.Fatal("\"*\" with credentials was accepted")
+    }
+    if err := (CORSConfig{Origins: []string{"https://app.example.com"}, Credentials: true}).Validate(); err != nil {
+        t.Fatalf("a listed origin with credentials was rejected: %v", err)
+    }
+}
+
+func TestCORSNeverEchoesTheWildcard(t *testing.T) {
+    ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+    
+    for _, config := range []CORSConfig{
+        {Origins: []string{"*"}},
+        // Validate refuses this at startup; the handler must stay safe
+        // even if it is skipped.
+        {Origins: []string{"*"}, Credentials: true},
+    } {
+        req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+        req.Header.Set("Origin", "https://evil.example")
+        rec := httptest.NewRecorder()
+        CORS(config)(ok).ServeHTTP(rec, req)
+        
+        if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+            t.Errorf("credentials %v: Access-Control-Allow-Origin is %q, want \"*\"", config.Credentials, got)
+        }
+        if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+            t.Errorf("credentials %v: Access-Control-Allow-Credentials is %q for the wildcard", config.Credentials, got)
+        }
+    }
+    
+    config := CORSConfig{Origins: []string{"https://app.example.com"}, Credentials: true}
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+    req.Header.Set("Origin", "https://app.example.com")
+    rec := httptest.NewRecorder()
+    CORS(config)(ok).ServeHTTP(rec, req)
+    if rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || rec.Header().Get("Access-Control-Allow-Credentials") != "true" {
+        t.Fatalf("listed origin got %q", rec.Header())
+    }
+}
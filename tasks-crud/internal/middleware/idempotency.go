@@ -0,0 +1,299 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"tasks-crud/internal/domain"
+)
+
+const (
+    IdempotencyKeyHeader     = "Idempotency-Key"
+    IdempotentReplayedHeader = "Idempotent-Replayed"
+    maxIdempotencyKeyLength  = 255
+    idempotencySweepInterval = time.Minute
+    // maxIdempotencyBody bounds the requests buffered for fingerprinting and
+    // the responses kept for replay. It matches the largest body any route
+    // accepts (imports), as routes exempt from MaxBodySize are buffered too.
+    maxIdempotencyBody    = 10 << 20
+    maxIdempotencyEntries = 10000
+)
+
+var errIdempotencyStoreFull = errors.New("too many requests in progress")
+
+type idempotencyEntry struct {
+    fingerprint string
+    done        chan struct{}
+    status      int
+    header      http.Header
+    body        []byte
+    expiresAt   time.Time
+}
+
+// IdempotencyStore keeps the responses of POST requests with an
+// Idempotency-Key for ttl. It holds at most maxEntries keys; when full, the
+// stored response closest to expiry is dropped to make room.
+type IdempotencyStore struct {
+    ttl        time.Duration
+    maxEntries int
+    entries    map[string]*idempotencyEntry
+    lastSweep  time.Time
+    mu         sync.Mutex
+}
+
+func NewIdempotencyStore(ttl time.Duration) *IdempotencyStore {
+    return &IdempotencyStore{
+        ttl:        ttl,
+        maxEntries: maxIdempotencyEntries,
+        entries:    make(map[string]*idempotencyEntry),
+        lastSweep:  time.Now(),
+    }
+}
+
+func Idempotency(store *IdempotencyStore) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            key := r.Header.Get(IdempotencyKeyHeader)
+            if r.Method != http.MethodPost || key == "" {
+                next.ServeHTTP(w, r)
+                return
+            }
+            
+            if len(key) > maxIdempotencyKeyLength {
+                writeError(w, http.StatusBadRequest, "Invalid Idempotency-Key", fmt.Errorf("key is longer than %d characters", maxIdempotencyKeyLength))
+                return
+            }
+            
+            body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotencyBody))
+            if err != nil {
+                var maxBytesErr *http.MaxBytesError
+                if errors.As(err, &maxBytesErr) {
+                    writeError(w, http.StatusRequestEntityTooLarge, "Request body is too large", err)
+                    return
+                }
+                writeError(w, http.StatusBadRequest, "Failed to read request body", err)
+                return
+            }
+            r.Body.Close()
+            r.Body = io.NopCloser(bytes.NewReader(body))
+            
+            fingerprint := requestFingerprint(r, body)
+            
+            // Keys are per tenant and caller, so one client can't replay
+            // another's response.
+            storeKey := key
+            if principal := domain.PrincipalFromContext(r.Context()); principal != nil {
+                storeKey = principal.Method + ":" + principal.Owner() + ":" + key
+            }
+            if tenantID := domain.TenantFromContext(r.Context()); tenantID != "" {
+                storeKey = tenantID + "/" + storeKey
+            }
+            
+            entry, owner, err := store.acquire(storeKey, fingerprint)
+            if err != nil {
+                w.Header().Set("Retry-After", "1")
+                writeError(w, http.StatusServiceUnavailable, "Idempotency store is full", err)
+                return
+            }
+            if !owner {
+                select {
+                case <-entry.done:
+                case <-r.Context().Done():
+                    return
+                }
+                
+                if entry.fingerprint != fingerprint {
+                    writeError(w, http.StatusUnprocessableEntity, "Idempotency-Key reused", fmt.Errorf("key '%s' was already used with a different request", key))
+                    return
+                }
+                
+                if entry.status == 0 {
+                    writeError(w, http.StatusConflict, "Original request failed", fmt.Errorf("request with key '%s' did not complete, retry it", key))
+                    return
+                }
+                
+                replay(w, entry)
+                return
+            }
+            
+            recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+            completed := false
+            defer func() {
+                if !completed {
+                    store.abandon(storeKey, entry)
+                }
+            }()
+            
+            next.ServeHTTP(recorder, r)
+            
+            store.complete(storeKey, entry, recorder)
+            completed = true
+        })
+    }
+}
+
+func (s *IdempotencyStore) acquire(key, fingerprint string) (*idempotencyEntry, bool, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    
+    now := time.Now()
+    if now.Sub(s.lastSweep) >= idempotencySweepInterval {
+        s.sweep(now)
+    }
+    
+    if entry, exists := s.entries[key]; exists && (entry.expiresAt.IsZero() || now.Before(entry.expiresAt)) {
+        if entry.fingerprint != fingerprint {
+            return &idempotencyEntry{fingerprint: entry.fingerprint, done: closedChannel()}, false, nil
+        }
+        return entry, false, nil
+    }
+    
+    if len(s.entries) >= s.maxEntries {
+        s.sweep(now)
+    }
+    if len(s.entries) >= s.maxEntries && !s.evict() {
+        return nil, false, errIdempotencyStoreFull
+    }
+    
+    entry := &idempotencyEntry{
+        fingerprint: fingerprint,
+        done:        make(chan struct{}),
+    }
+    s.entries[key] = entry
+    
+    return entry, true, nil
+}
+
+// evict drops the stored response that expires first. Requests still in
+// progress are kept, so it fails if every entry is one.
+func (s *IdempotencyStore) evict() bool {
+    oldestKey := ""
+    var oldest *idempotencyEntry
+    for key, entry := range s.entries {
+        if entry.expiresAt.IsZero() {
+            continue
+        }
+        if oldest == nil || entry.expiresAt.Before(oldest.expiresAt) {
+            oldestKey, oldest = key, entry
+        }
+    }
+    if oldest == nil {
+        return false
+    }
+    
+    delete(s.entries, oldestKey)
+    return true
+}
+
+// complete stores the response for replay. Server errors aren't stored,
+// so the request can be retried, and neither are responses too large to
+// keep, whose keys then act as if never used.
+func (s *IdempotencyStore) complete(key string, entry *idempotencyEntry, recorder *responseRecorder) {
+    if recorder.status >= http.StatusInternalServerError || recorder.overflow {
+        s.abandon(key, entry)
+        return
+    }
+    
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    
+    entry.status = recorder.status
+    entry.header = recorder.Header().Clone()
+    entry.body = recorder.body.Bytes()
+    entry.expiresAt = time.Now().Add(s.ttl)
+    close(entry.done)
+}
+
+func (s *IdempotencyStore) abandon(key string, entry *idempotencyEntry) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    
+    if s.entries[key] == entry {
+        delete(s.entries, key)
+    }
+    close(entry.done)
+}
+
+func (s *IdempotencyStore) sweep(now time.Time) {
+    for key, entry := range s.entries {
+        if !entry.expiresAt.IsZero() && now.After(entry.expiresAt) {
+            delete(s.entries, key)
+        }
+    }
+    s.lastSweep = now
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+    hash := sha256.New()
+    hash.Write([]byte(r.Method))
+    hash.Write([]byte{0})
+    hash.Write([]byte(r.URL.RequestURI()))
+    hash.Write([]byte{0})
+    hash.Write(body)
+    return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replay(w http.ResponseWriter, entry *idempotencyEntry) {
+    for name, values := range entry.header {
+        w.Header()[name] = values
+    }
+    w.Header().Set(IdempotentReplayedHeader, "true")
+    w.WriteHeader(entry.status)
+    w.Write(entry.body)
+}
+
+func closedChannel() chan struct{} {
+    ch := make(chan struct{})
+    close(ch)
+    return ch
+}
+
+type responseRecorder struct {
+    http.ResponseWriter
+    status      int
+    wroteHeader bool
+    body        bytes.Buffer
+    // overflow is set once the body outgrows maxIdempotencyBody and is no
+    // longer recorded.
+    overflow    bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+    if r.wroteHeader {
+        return
+    }
+    r.status = status
+    r.wroteHeader = true
+    r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+    if !r.wroteHeader {
+        r.WriteHeader(http.StatusOK)
+    }
+    if !r.overflow && r.body.Len()+len(data) > maxIdempotencyBody {
+        r.overflow = true
+        r.body.Reset()
+    }
+    if !r.overflow {
+        r.body.Write(data)
+    }
+    return r.ResponseWriter.Write(data)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string, err error) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(statusCode)
+    json.NewEncoder(w).Encode(domain.ErrorResponse{
+        Error:   message,
+        Details: err.Error(),
+    })
+}
\ No newline at end of file
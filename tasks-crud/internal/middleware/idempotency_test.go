@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func echoHandler() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        body, _ := io.ReadAll(r.Body)
+        w.WriteHeader(http.StatusCreated)
+        w.Write(body)
+    })
+}
+
+func TestIdempotencyRejectsOversizedBody(t *testing.T) {
+    handler := MaxBodySize(16)(Idempotency(NewIdempotencyStore(time.Hour))(echoHandler()))
+    
+    // No Content-Length, so only reading the body finds out.
+    req := httptest.NewRequest(http.MethodPost, "/tasks", io.NopCloser(strings.NewReader(strings.Repeat("a", 64))))
+    req.ContentLength = -1
+    req.Header.Set(IdempotencyKeyHeader, "k")
+    rec := httptest.NewRecorder()
+    handler.ServeHTTP(rec, req)
+    
+    if rec.Code != http.StatusRequestEntityTooLarge {
+        t.Fatalf("got %d, want 413", rec.Code)
+    }
+}
+
+func TestIdempotencyStoreIsBounded(t *testing.T) {
+    store := NewIdempotencyStore(time.Hour)
+    store.maxEntries = 3
+    handler := Idempotency(store)(echoHandler())
+    
+    post := func(key string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(key))
+        req.Header.Set(IdempotencyKeyHeader, key)
+        rec := httptest.NewRecorder()
+        handler.ServeHTTP(rec, req)
+        return rec
+    }
+    
+    for i := 0; i < 10; i++ {
+        if rec := post(fmt.Sprintf("key-%d", i)); rec.Code != http.StatusCreated {
+            t.Fatalf("request %d: got %d", i, rec.Code)
+        }
+    }
+    if len(store.entries) != 3 {
+        t.Fatalf("store holds %d entries, want 3", len(store.entries))
+    }
+    
+    // The newest keys are still replayed.
+    if rec := post("key-9"); rec.Header().Get(IdempotentReplayedHeader) != "true" {
+        t.Fatal("latest response was not replayed")
+    }
+}
+
+func TestIdempotencyStoreFullOfRequestsInProgress(t *testing.T) {
+    store := NewIdempotencyStore(time.Hour)
+    store.maxEntries = 1
+    if _, owner, err := store.acquire("a", "x"); err != nil || !owner {
+        t.Fatalf("first acquire: %v", err)
+    }
+    if _, _, err := store.acquire("b", "y"); err != errIdempotencyStoreFull {
+        t.Fatalf("got %v, want errIdempotencyStoreFull", err)
+    }
+}
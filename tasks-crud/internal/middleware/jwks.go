@@ -0,0 +1,322 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+    maxJWKSSize        = 1 << 20
+    minJWKSRefreshWait = 30 * time.Second
+)
+
+// KeySet finds the key that verifies a token signed with alg. kid is
+// empty when the token header has none.
+type KeySet interface {
+    Key(kid, alg string) (interface{}, error)
+}
+
+// SecretKey is a shared HS256 secret.
+type SecretKey []byte
+
+func (k SecretKey) Key(kid, alg string) (interface{}, error) {
+    if alg != "HS256" {
+        return nil, fmt.Errorf("no key for algorithm %s", alg)
+    }
+    return []byte(k), nil
+}
+
+// KeySets tries each key set in turn.
+type KeySets []KeySet
+
+func (sets KeySets) Key(kid, alg string) (interface{}, error) {
+    err := fmt.Errorf("no key for algorithm %s", alg)
+    for _, set := range sets {
+        key, keyErr := set.Key(kid, alg)
+        if keyErr == nil {
+            return key, nil
+        }
+        err = keyErr
+    }
+    return nil, err
+}
+
+type jwk struct {
+    Kty string `json:"kty"`
+    Kid string `json:"kid"`
+    Alg string `json:"alg"`
+    Use string `json:"use"`
+    Crv string `json:"crv"`
+    N   string `json:"n"`
+    E   string `json:"e"`
+    X   string `json:"x"`
+    K   string `json:"k"`
+}
+
+type jwkKey struct {
+    kid string
+    alg string
+    key interface{}
+}
+
+// JWKS is a JSON Web Key Set read from a local file or an http(s) URL.
+// Keys are cached for ttl; a token with an unknown kid triggers an early
+// reload, at most once every 30 seconds, so that rotated keys are picked
+// up without waiting for the cache to expire. When a reload fails the
+// cached keys stay in use and the next attempt waits 30 seconds.
+type JWKS struct {
+    source    string
+    ttl       time.Duration
+    client    *http.Client
+    keys      []jwkKey
+    loadedAt  time.Time
+    lastFetch time.Time
+    retryAt   time.Time
+    loadErr   error
+    // loading is closed when the reload in progress finishes; nil when
+    // none is.
+    loading chan struct{}
+    mu      sync.Mutex
+}
+
+func NewJWKS(source string, ttl time.Duration) *JWKS {
+    return &JWKS{
+        source: source,
+        ttl:    ttl,
+        client: &http.Client{Timeout: 10 * time.Second},
+    }
+}
+
+func (s *JWKS) Key(kid, alg string) (interface{}, error) {
+    keys, err := s.cached()
+    if err != nil {
+        return nil, err
+    }
+    
+    if key, ok := findKey(keys, kid, alg); ok {
+        return key, nil
+    }
+    
+    if kid != "" {
+        reloaded, err := s.reload(true)
+        if err != nil {
+            return nil, err
+        }
+        if key, ok := findKey(reloaded, kid, alg); ok {
+            return key, nil
+        }
+        return nil, fmt.Errorf("unknown key id '%s'", kid)
+    }
+    return nil, fmt.Errorf("no key for algorithm %s", alg)
+}
+
+// cached returns the cached keys, reloading them first once they have
+// expired.
+func (s *JWKS) cached() ([]jwkKey, error) {
+    s.mu.Lock()
+    now := time.Now()
+    keys := s.keys
+    expired := keys == nil || now.Sub(s.loadedAt) >= s.ttl
+    if expired && now.Before(s.retryAt) {
+        // The last reload failed not long ago.
+        expired = false
+    }
+    err := s.loadErr
+    s.mu.Unlock()
+    
+    if !expired {
+        if keys == nil {
+            return nil, err
+        }
+        return keys, nil
+    }
+    
+    reloaded, err := s.reload(false)
+    if err != nil && reloaded == nil {
+        return nil, err
+    }
+    return reloaded, nil
+}
+
+// reload fetches the key set again and returns the keys in use afterwards.
+// The fetch runs without holding s.mu; callers arriving while one is in
+// progress wait for its result instead of starting another. With early,
+// nothing is fetched if the last fetch was less than 30 seconds ago.
+func (s *JWKS) reload(early bool) ([]jwkKey, error) {
+    s.mu.Lock()
+    if loading := s.loading; loading != nil {
+        s.mu.Unlock()
+        <-loading
+        
+        s.mu.Lock()
+        defer s.mu.Unlock()
+        return s.keys, s.loadErr
+    }
+    if early && time.Since(s.lastFetch) < minJWKSRefreshWait {
+        defer s.mu.Unlock()
+        return s.keys, nil
+    }
+    
+    loading := make(chan struct{})
+    s.loading = loading
+    s.lastFetch = time.Now()
+    s.mu.Unlock()
+    
+    keys, err := s.load()
+    
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    
+    now := time.Now()
+    if err == nil {
+        s.keys = keys
+        s.loadedAt = now
+    } else {
+        s.retryAt = now.Add(minJWKSRefreshWait)
+    }
+    s.loadErr = err
+    s.loading = nil
+    close(loading)
+    
+    return s.keys, err
+}
+
+func (s *JWKS) load() ([]jwkKey, error) {
+    data, err := s.fetch()
+    if err != nil {
+        return nil, fmt.Errorf("failed to load JWKS: %w", err)
+    }
+    
+    keys, err := parseJWKS(data)
+    if err != nil {
+        return nil, fmt.Errorf("failed to load JWKS: %w", err)
+    }
+    return keys, nil
+}
+
+// findKey returns the key with the given kid, or the only key usable with
+// alg when the token carries no kid.
+func findKey(keys []jwkKey, kid, alg string) (interface{}, bool) {
+    var match interface{}
+    matches := 0
+    for _, key := range keys {
+        if key.alg != alg {
+            continue
+        }
+        if kid != "" {
+            if key.kid == kid {
+                return key.key, true
+            }
+            continue
+        }
+        match = key.key
+        matches++
+    }
+    return match, matches == 1
+}
+
+func (s *JWKS) fetch() ([]byte, error) {
+    if !strings.HasPrefix(s.source, "http://") && !strings.HasPrefix(s.source, "https://") {
+        return os.ReadFile(s.source)
+    }
+    
+    resp, err := s.client.Get(s.source)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+    
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("%s returned %s", s.source, resp.Status)
+    }
+    return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
+
+// parseJWKS reads RSA (RS256), OKP Ed25519 (EdDSA) and oct (HS256) keys.
+// Keys of other types or meant for encryption are skipped, and so are
+// invalid keys (after logging them), so that one bad key doesn't disable
+// all the others.
+func parseJWKS(data []byte) ([]jwkKey, error) {
+    var set struct {
+        Keys []jwk `json:"keys"`
+    }
+    if err := json.Unmarshal(data, &set); err != nil {
+        return nil, fmt.Errorf("invalid JSON: %w", err)
+    }
+    
+    keys := make([]jwkKey, 0, len(set.Keys))
+    for i, raw := range set.Keys {
+        if raw.Use != "" && raw.Use != "sig" {
+            continue
+        }
+        
+        key, alg, err := raw.publicKey()
+        if err != nil {
+            log.Printf("JWKS: skipping key %d (kid '%s'): %v", i, raw.Kid, err)
+            continue
+        }
+        if key == nil {
+            continue
+        }
+        if raw.Alg != "" && raw.Alg != alg {
+            continue
+        }
+        keys = append(keys, jwkKey{kid: raw.Kid, alg: alg, key: key})
+    }
+    
+    return keys, nil
+}
+
+func (k jwk) publicKey() (interface{}, string, error) {
+    switch k.Kty {
+    case "RSA":
+        n, err := decodeKeyPart(k.N)
+        if err != nil {
+            return nil, "", fmt.Errorf("invalid modulus: %w", err)
+        }
+        e, err := decodeKeyPart(k.E)
+        if err != nil {
+            return nil, "", fmt.Errorf("invalid exponent: %w", err)
+        }
+        exponent := new(big.Int).SetBytes(e)
+        if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+            return nil, "", fmt.Errorf("invalid exponent")
+        }
+        modulus := new(big.Int).SetBytes(n)
+        if modulus.BitLen() < 2048 {
+            return nil, "", fmt.Errorf("RSA keys must be at least 2048 bits")
+        }
+        return &rsa.PublicKey{N: modulus, E: int(exponent.Int64())}, "RS256", nil
+    case "OKP":
+        if k.Crv != "Ed25519" {
+            return nil, "", nil
+        }
+        x, err := decodeKeyPart(k.X)
+        if err != nil || len(x) != ed25519.PublicKeySize {
+            return nil, "", fmt.Errorf("invalid Ed25519 key")
+        }
+        return ed25519.PublicKey(x), "EdDSA", nil
+    case "oct":
+        secret, err := decodeKeyPart(k.K)
+        if err != nil || len(secret) == 0 {
+            return nil, "", fmt.Errorf("invalid symmetric key")
+        }
+        return secret, "HS256", nil
+    }
+    return nil, "", nil
+}
+
+func decodeKeyPart(value string) ([]byte, error) {
+    return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func ed25519JWK(t *testing.T, kid string) map[string]string {
+    public, _, err := ed25519.GenerateKey(rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return map[string]string{"kty": "OKP", "crv": "Ed25519", "kid": kid, "x": base64.RawURLEncoding.EncodeToString(public)}
+}
+
+func jwksServer(t *testing.T, keys ...map[string]string) (*httptest.Server, *atomic.Int32, *atomic.Bool) {
+    body, err := json.Marshal(map[string]interface{}{"keys": keys})
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    var fetches atomic.Int32
+    var failing atomic.Bool
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        fetches.Add(1)
+        if failing.Load() {
+            http.Error(w, "unavailable", http.StatusServiceUnavailable)
+            return
+        }
+        w.Write(body)
+    }))
+    t.Cleanup(server.Close)
+    return server, &fetches, &failing
+}
+
+func TestJWKSSkipsInvalidKeys(t *testing.T) {
+    weak, err := rsa.GenerateKey(rand.Reader, 1024)
+    if err != nil {
+        t.Fatal(err)
+    }
+    weakJWK := map[string]string{
+        "kty": "RSA",
+        "kid": "weak",
+        "n":   base64.RawURLEncoding.EncodeToString(weak.N.Bytes()),
+        "e":   "AQAB",
+    }
+    server, _, _ := jwksServer(t, weakJWK, ed25519JWK(t, "good"))
+    
+    keys := NewJWKS(server.URL, time.Hour)
+    if _, err := keys.Key("good", "EdDSA"); err != nil {
+        t.Fatalf("valid key next to a weak one: %v", err)
+    }
+    if _, err := keys.Key("weak", "RS256"); err == nil {
+        t.Fatal("1024-bit RSA key was accepted")
+    }
+}
+
+func TestJWKSKeepsCachedKeysAndBacksOffWhenReloadFails(t *testing.T) {
+    server, fetches, failing := jwksServer(t, ed25519JWK(t, "k1"))
+    
+    keys := NewJWKS(server.URL, time.Millisecond)
+    if _, err := keys.Key("k1", "EdDSA"); err != nil {
+        t.Fatal(err)
+    }
+    
+    failing.Store(true)
+    time.Sleep(2 * time.Millisecond)
+    for i := 0; i < 5; i++ {
+        if _, err := keys.Key("k1", "EdDSA"); err != nil {
+            t.Fatalf("cached key not used while the JWKS is down: %v", err)
+        }
+    }
+    
+    if got := fetches.Load(); got != 2 {
+        t.Fatalf("JWKS fetched %d times, want 2 (the failed reload is not retried at once)", got)
+    }
+}
+
+func TestJWKSFetchesOnceForConcurrentCallers(t *testing.T) {
+    server, fetches, _ := jwksServer(t, ed25519JWK(t, "k1"))
+    
+    keys := NewJWKS(server.URL, time.Hour)
+    var wg sync.WaitGroup
+    for i := 0; i < 10; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            if _, err := keys.Key("k1", "EdDSA"); err != nil {
+                t.Error(err)
+            }
+        }()
+    }
+    wg.Wait()
+    
+    if got := fetches.Load(); got != 1 {
+        t.Fatalf("JWKS fetched %d times, want 1", got)
+    }
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tasks-crud/internal/domain"
+)
+
+const rateLimitSweepInterval = time.Minute
+
+// RateLimit allows Requests per Window with bursts of up to Requests.
+// Zero Requests means no limit.
+type RateLimit struct {
+    Requests int
+    Window   time.Duration
+}
+
+// RateDecision is the outcome of taking a token from a bucket.
+type RateDecision struct {
+    Allowed    bool
+    Limit      RateLimit
+    Remaining  int
+    // Reset is how long until the bucket is full again.
+    Reset      time.Duration
+    // RetryAfter is how long until the next request is allowed.
+    RetryAfter time.Duration
+}
+
+// RateLimiter keeps a token bucket per client and route class. Reads and
+// writes (as told by KeyScopeForMethod) have separate buckets and limits.
+type RateLimiter struct {
+    reads     RateLimit
+    writes    RateLimit
+    buckets   map[string]*tokenBucket
+    lastSweep time.Time
+    mu        sync.Mutex
+}
+
+type tokenBucket struct {
+    tokens  float64
+    updated time.Time
+    limit   RateLimit
+}
+
+func NewRateLimiter(reads, writes RateLimit) *RateLimiter {
+    return &RateLimiter{
+        reads:     reads,
+        writes:    writes,
+        buckets:   make(map[string]*tokenBucket),
+        lastSweep: time.Now(),
+    }
+}
+
+// Allow takes a token from the bucket of client for reads or writes.
+func (l *RateLimiter) Allow(client string, write bool) RateDecision {
+    limit, class := l.reads, "read"
+    if write {
+        limit, class = l.writes, "write"
+    }
+    if limit.Requests <= 0 || limit.Window <= 0 {
+        return RateDecision{Allowed: true}
+    }
+    
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    
+    now := time.Now()
+    l.sweep(now)
+    
+    key := class + "|" + client
+    b, exists := l.buckets[key]
+    if !exists {
+        b = &tokenBucket{tokens: float64(limit.Requests), updated: now, limit: limit}
+        l.buckets[key] = b
+    }
+    
+    capacity := float64(limit.Requests)
+    rate := capacity / limit.Window.Seconds()
+    b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+    b.updated = now
+    
+    decision := RateDecision{Limit: limit}
+    if b.tokens >= 1 {
+        b.tokens--
+        decision.Allowed = true
+    } else {
+        decision.RetryAfter = seconds((1 - b.tokens) / rate)
+    }
+    decision.Remaining = int(b.tokens)
+    decision.Reset = seconds((capacity - b.tokens) / rate)
+    
+    return decision
+}
+
+// sweep drops buckets that have refilled completely; they are the same as
+// new ones. Callers hold l.mu.
+func (l *RateLimiter) sweep(now time.Time) {
+    if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+        return
+    }
+    l.lastSweep = now
+    
+    for key, b := range l.buckets {
+        if now.Sub(b.updated) >= b.limit.Window {
+            delete(l.buckets, key)
+        }
+    }
+}
+
+// RateLimitClient identifies the caller of a request for rate limiting:
+// the API key, else the user (within their tenant), else the remote IP.
+// X-Forwarded-For is not trusted.
+func RateLimitClient(r *http.Request) string {
+    if principal := domain.PrincipalFromContext(r.Context()); principal != nil {
+        return PrincipalClient(principal, domain.TenantFromContext(r.Context()))
+    }
+    
+    host, _, err := net.SplitHostPort(r.RemoteAddr)
+    if err != nil {
+        host = r.RemoteAddr
+    }
+    return "ip:" + host
+}
+
+// PrincipalClient is the rate limiting key of an authenticated caller:
+// the API key, else the user the caller's tasks belong to (see
+// domain.Principal.Owner).
+func PrincipalClient(principal *domain.Principal, tenantID string) string {
+    if principal.Method == domain.AuthMethodAPIKey && principal.KeyID != 0 {
+        return "key:" + strconv.Itoa(principal.KeyID)
+    }
+    return "user:" + tenantID + ":" + principal.Owner()
+}
+
+// RateLimited rejects requests over the limit with 429 and a Retry-After
+// header. Every limited response carries the RateLimit-Policy and
+// RateLimit headers of draft-ietf-httpapi-ratelimit-headers. Paths under
+// the exempt prefixes are not limited. It must run after RequireAuth so
+// that callers are told apart by credentials rather than by IP.
+func RateLimited(limiter *RateLimiter, exempt ...string) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            for _, prefix := range exempt {
+                if strings.HasPrefix(r.URL.Path, prefix) {
+                    next.ServeHTTP(w, r)
+                    return
+                }
+            }
+            
+            write := KeyScopeForMethod(r.Method) == domain.ScopeTasksWrite
+            decision := limiter.Allow(RateLimitClient(r), write)
+            if decision.Limit.Requests > 0 {
+                w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", decision.Limit.Requests, int(decision.Limit.Window.Seconds())))
+                w.Header().Set("RateLimit", fmt.Sprintf("limit=%d, remaining=%d, reset=%d", decision.Limit.Requests, decision.Remaining, int(decision.Reset.Seconds())))
+            }
+            
+            if !decision.Allowed {
+                w.Header().Set("Retry-After", strconv.Itoa(int(decision.RetryAfter.Seconds())))
+                writeError(w, http.StatusTooManyRequests, "Too many requests", fmt.Errorf("rate limit of %d requests per %s exceeded", decision.Limit.Requests, decision.Limit.Window))
+                return
+            }
+            
+            next.ServeHTTP(w, r)
+        })
+    }
+}
+
+// seconds rounds up to whole seconds, as the headers carry.
+func seconds(s float64) time.Duration {
+    return time.Duration(math.Ceil(s)) * time.Second
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+
+	"tasks-crud/internal/domain"
+)
+
+const (
+    SessionCookieName = "session"
+    RefreshCookieName = "session_refresh"
+)
+
+// SessionRefresher trades a refresh token for new tokens; see
+// service.AuthService.Refresh.
+type SessionRefresher interface {
+    Refresh(req domain.RefreshRequest) (*domain.TokenResponse, error)
+}
+
+// WebSession signs browsers in to the web UI, which can't send bearer
+// tokens. On requests for which page reports true and that carry no
+// Authorization header, the access token in the session cookie (renewed
+// with the refresh cookie once it has expired) is passed on as a bearer
+// token, so it must be registered before RequireAuth. Only UI pages, which
+// are CSRF-protected, accept the cookie; the API never does. Without a
+// valid session, page loads are redirected to login and other requests get
+// 401.
+func WebSession(tokens Authenticator, refresher SessionRefresher, login string, page func(r *http.Request) bool) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            if !page(r) || r.Header.Get("Authorization") != "" {
+                next.ServeHTTP(w, r)
+                return
+            }
+            
+            token := ""
+            if cookie, err := r.Cookie(SessionCookieName); err == nil && cookie.Value != "" {
+                if _, err := tokens.Authenticate("Bearer", cookie.Value); err == nil {
+                    token = cookie.Value
+                }
+            }
+            if token == "" {
+                token = refreshSession(w, r, refresher)
+            }
+            
+            if token == "" {
+                ClearSession(w, r)
+                if r.Method == http.MethodGet || r.Method == http.MethodHead {
+                    http.Redirect(w, r, login+"?return="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+                    return
+                }
+                writeError(w, http.StatusUnauthorized, "Authentication required", errMissingToken)
+                return
+            }
+            
+            r.Header.Set("Authorization", "Bearer "+token)
+            next.ServeHTTP(w, r)
+        })
+    }
+}
+
+// refreshSession renews the session with the refresh cookie and returns
+// the new access token, or "" if that fails.
+func refreshSession(w http.ResponseWriter, r *http.Request, refresher SessionRefresher) string {
+    cookie, err := r.Cookie(RefreshCookieName)
+    if err != nil || cookie.Value == "" {
+        return ""
+    }
+    
+    tokens, err := refresher.Refresh(domain.RefreshRequest{RefreshToken: cookie.Value})
+    if err != nil {
+        return ""
+    }
+    
+    SetSession(w, r, tokens)
+    return tokens.AccessToken
+}
+
+// SetSession stores tokens in the session cookies. Neither is readable
+// from scripts, and both end with the browser session.
+func SetSession(w http.ResponseWriter, r *http.Request, tokens *domain.TokenResponse) {
+    setSessionCookie(w, r, SessionCookieName, tokens.AccessToken)
+    setSessionCookie(w, r, RefreshCookieName, tokens.RefreshToken)
+}
+
+// ClearSession removes the session cookies.
+func ClearSession(w http.ResponseWriter, r *http.Request) {
+    for _, name := range []string{SessionCookieName, RefreshCookieName} {
+        if _, err := r.Cookie(name); err == nil {
+            http.SetCookie(w, &http.Cookie{Name: name, Path: "/", MaxAge: -1})
+        }
+    }
+}
+
+func setSessionCookie(w http.ResponseWriter, r *http.Request, name, value string) {
+    http.SetCookie(w, &http.Cookie{
+        Name:     name,
+        Value:    value,
+        Path:     "/",
+        HttpOnly: true,
+        Secure:   r.TLS != nil,
+        SameSite: http.SameSiteLaxMode,
+    })
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/repository"
+)
+
+const DefaultTenantHeader = "X-Tenant-ID"
+
+var (
+    ErrTenantRequired = errors.New("tenant is required")
+    ErrTenantMismatch = errors.New("credentials belong to another tenant")
+    ErrTenantInactive = errors.New("tenant is suspended")
+)
+
+// TenantResolver finds the tenant of a request and puts it, with its
+// stores, into the request context. The tenant comes from the caller's
+// token claim, the Header, a subdomain of Domain or the "tenant" query
+// parameter, in that order of precedence; a request naming a tenant other
+// than the one its credentials belong to is refused.
+type TenantResolver struct {
+    Tenants repository.TenantRepository
+    Header  string
+    // Domain is the base domain tenants are subdomains of, e.g.
+    // "tasks.example.com" for "acme.tasks.example.com". Empty disables
+    // subdomain lookup.
+    Domain string
+}
+
+// Requested returns the tenant a request names without credentials.
+func (t *TenantResolver) Requested(r *http.Request) string {
+    header := t.Header
+    if header == "" {
+        header = DefaultTenantHeader
+    }
+    if tenantID := strings.TrimSpace(r.Header.Get(header)); tenantID != "" {
+        return tenantID
+    }
+    if tenantID := t.FromHost(r.Host); tenantID != "" {
+        return tenantID
+    }
+    return r.URL.Query().Get("tenant")
+}
+
+// FromHost returns the subdomain label of host below Domain.
+func (t *TenantResolver) FromHost(host string) string {
+    if t.Domain == "" {
+        return ""
+    }
+    if h, _, err := net.SplitHostPort(host); err == nil {
+        host = h
+    }
+    
+    label, found := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(t.Domain))
+    if !found || strings.Contains(label, ".") {
+        return ""
+    }
+    return label
+}
+
+// Resolve checks requested against the principal's tenant and returns ctx
+// with the tenant and its stores. Errors are ErrTenantRequired,
+// ErrTenantMismatch, ErrTenantInactive or one wrapping domain.ErrNotFound.
+func (t *TenantResolver) Resolve(ctx context.Context, requested string) (context.Context, error) {
+    tenantID := requested
+    if principal := domain.PrincipalFromContext(ctx); principal != nil {
+        if principal.TenantID == "" || requested != "" && requested != principal.TenantID {
+            return nil, ErrTenantMismatch
+        }
+        tenantID = principal.TenantID
+    }
+    
+    if tenantID == "" {
+        return nil, ErrTenantRequired
+    }
+    
+    tenant, err := t.Tenants.GetByID(tenantID)
+    if err != nil {
+        return nil, err
+    }
+    if tenant.Status != domain.TenantStatusActive {
+        return nil, fmt.Errorf("%w: %s", ErrTenantInactive, tenant.ID)
+    }
+    
+    data, err := t.Tenants.Data(tenant.ID)
+    if err != nil {
+        return nil, err
+    }
+    
+    return repository.WithTenantData(domain.WithTenant(ctx, tenant.ID), data), nil
+}
+
+// Tenancy resolves the tenant of every request except those whose path
+// starts with one of the exempt prefixes. It must run after RequireAuth
+// so that the token claim is known.
+func Tenancy(t *TenantResolver, exempt ...string) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            for _, prefix := range exempt {
+                if strings.HasPrefix(r.URL.Path, prefix) {
+                    next.ServeHTTP(w, r)
+                    return
+                }
+            }
+            
+            ctx, err := t.Resolve(r.Context(), t.Requested(r))
+            if err != nil {
+                writeError(w, TenantErrorStatus(err), "Tenant not available", err)
+                return
+            }
+            
+            next.ServeHTTP(w, r.WithContext(ctx))
+        })
+    }
+}
+
+// TenantErrorStatus maps an error of TenantResolver.Resolve to an HTTP
+// status.
+func TenantErrorStatus(err error) int {
+    switch {
+    case errors.Is(err, ErrTenantRequired):
+        return http.StatusBadRequest
+    case errors.Is(err, ErrTenantMismatch), errors.Is(err, ErrTenantInactive):
+        return http.StatusForbidden
+    case errors.Is(err, domain.ErrNotFound):
+        return http.StatusNotFound
+    default:
+        return http.StatusInternalServerError
+    }
+}
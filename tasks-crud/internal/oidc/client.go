@@ -0,0 +1,297 @@
+// Package oidc implements the relying party side of OpenID Connect login:
+// discovery, the authorization code flow with PKCE (RFC 7636) and ID token
+// validation. Tokens are verified against the provider's JWKS.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"tasks-crud/internal/middleware"
+)
+
+const (
+    discoveryPath   = "/.well-known/openid-configuration"
+    maxResponseSize = 1 << 20
+    jwksCacheTTL    = time.Hour
+    clockSkew       = time.Minute
+)
+
+type Config struct {
+    Issuer       string
+    ClientID     string
+    ClientSecret string
+    RedirectURL  string
+    Scopes       []string
+}
+
+// Metadata is the part of the discovery document the client uses.
+type Metadata struct {
+    Issuer                string   `json:"issuer"`
+    AuthorizationEndpoint string   `json:"authorization_endpoint"`
+    TokenEndpoint         string   `json:"token_endpoint"`
+    JWKSURI               string   `json:"jwks_uri"`
+    CodeChallengeMethods  []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+// IDToken holds the validated claims of an ID token.
+type IDToken struct {
+    Issuer    string
+    Subject   string
+    ExpiresAt time.Time
+    Claims    jwt.MapClaims
+}
+
+// String returns a string claim, or "" if it is missing.
+func (t *IDToken) String(name string) string {
+    value, _ := t.Claims[name].(string)
+    return value
+}
+
+// Strings returns a claim that is a list of strings, or a single string
+// (some providers send one group that way).
+func (t *IDToken) Strings(name string) []string {
+    switch value := t.Claims[name].(type) {
+    case string:
+        return []string{value}
+    case []interface{}:
+        values := make([]string, 0, len(value))
+        for _, item := range value {
+            if s, ok := item.(string); ok {
+                values = append(values, s)
+            }
+        }
+        return values
+    }
+    return nil
+}
+
+type Client struct {
+    config   Config
+    http     *http.Client
+    metadata *Metadata
+    keys     *middleware.JWKS
+    mu       sync.Mutex
+}
+
+func NewClient(config Config) *Client {
+    config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+    return &Client{
+        config: config,
+        http:   &http.Client{Timeout: 10 * time.Second},
+    }
+}
+
+// Discover fetches the provider's discovery document. It is cached after
+// the first success, so a provider that is down at startup doesn't keep
+// the server from starting.
+func (c *Client) Discover(ctx context.Context) (*Metadata, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    
+    if c.metadata != nil {
+        return c.metadata, nil
+    }
+    
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.Issuer+discoveryPath, nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to discover provider: %w", err)
+    }
+    
+    var metadata Metadata
+    if err := c.do(req, &metadata); err != nil {
+        return nil, fmt.Errorf("failed to discover provider: %w", err)
+    }
+    
+    if strings.TrimSuffix(metadata.Issuer, "/") != c.config.Issuer {
+        return nil, fmt.Errorf("failed to discover provider: issuer is %q, expected %q", metadata.Issuer, c.config.Issuer)
+    }
+    if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+        return nil, fmt.Errorf("failed to discover provider: discovery document is incomplete")
+    }
+    if len(metadata.CodeChallengeMethods) > 0 && !contains(metadata.CodeChallengeMethods, "S256") {
+        return nil, fmt.Errorf("failed to discover provider: PKCE with S256 is not supported")
+    }
+    
+    c.metadata = &metadata
+    c.keys = middleware.NewJWKS(metadata.JWKSURI, jwksCacheTTL)
+    return c.metadata, nil
+}
+
+// AuthCodeURL returns the provider URL to send the browser to. verifier is
+// the PKCE code verifier; only its S256 challenge is sent.
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, verifier, loginHint string) (string, error) {
+    metadata, err := c.Discover(ctx)
+    if err != nil {
+        return "", err
+    }
+    
+    query := url.Values{
+        "response_type":         {"code"},
+        "client_id":             {c.config.ClientID},
+        "redirect_uri":          {c.config.RedirectURL},
+        "scope":                 {strings.Join(c.config.Scopes, " ")},
+        "state":                 {state},
+        "nonce":                 {nonce},
+        "code_challenge":        {Challenge(verifier)},
+        "code_challenge_method": {"S256"},
+    }
+    if loginHint != "" {
+        query.Set("login_hint", loginHint)
+    }
+    
+    separator := "?"
+    if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+        separator = "&"
+    }
+    return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the validated ID
+// token. nonce must be the one sent with the authorization request.
+func (c *Client) Exchange(ctx context.Context, code, verifier, nonce string) (*IDToken, error) {
+    metadata, err := c.Discover(ctx)
+    if err != nil {
+        return nil, err
+    }
+    
+    form := url.Values{
+        "grant_type":    {"authorization_code"},
+        "code":          {code},
+        "redirect_uri":  {c.config.RedirectURL},
+        "code_verifier": {verifier},
+        "client_id":     {c.config.ClientID},
+    }
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+    if err != nil {
+        return nil, fmt.Errorf("failed to redeem code: %w", err)
+    }
+    req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+    if c.config.ClientSecret != "" {
+        req.SetBasicAuth(url.QueryEscape(c.config.ClientID), url.QueryEscape(c.config.ClientSecret))
+    }
+    
+    var tokens struct {
+        IDToken string `json:"id_token"`
+    }
+    if err := c.do(req, &tokens); err != nil {
+        return nil, fmt.Errorf("failed to redeem code: %w", err)
+    }
+    if tokens.IDToken == "" {
+        return nil, fmt.Errorf("invalid token response: no id_token")
+    }
+    
+    return c.Verify(tokens.IDToken, nonce)
+}
+
+// Verify validates an ID token (OpenID Connect Core, section 3.1.3.7):
+// signature, issuer, audience, authorized party, expiry and nonce.
+func (c *Client) Verify(token, nonce string) (*IDToken, error) {
+    if c.keys == nil {
+        return nil, fmt.Errorf("invalid id token: provider not discovered")
+    }
+    
+    claims := jwt.MapClaims{}
+    _, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+        kid, _ := t.Header["kid"].(string)
+        return c.keys.Key(kid, t.Method.Alg())
+    },
+        jwt.WithValidMethods([]string{"RS256", "EdDSA"}),
+        jwt.WithIssuer(c.metadata.Issuer),
+        jwt.WithAudience(c.config.ClientID),
+        jwt.WithExpirationRequired(),
+        jwt.WithIssuedAt(),
+        jwt.WithLeeway(clockSkew),
+    )
+    if err != nil {
+        return nil, fmt.Errorf("invalid id token: %w", err)
+    }
+    
+    audience, _ := claims.GetAudience()
+    if azp, _ := claims["azp"].(string); len(audience) > 1 && azp != c.config.ClientID {
+        return nil, fmt.Errorf("invalid id token: authorized party is %q", azp)
+    }
+    
+    if got, _ := claims["nonce"].(string); got == "" || got != nonce {
+        return nil, fmt.Errorf("invalid id token: nonce mismatch")
+    }
+    
+    subject, _ := claims.GetSubject()
+    if subject == "" {
+        return nil, fmt.Errorf("invalid id token: no subject")
+    }
+    
+    expiresAt, _ := claims.GetExpirationTime()
+    return &IDToken{
+        Issuer:    c.config.Issuer,
+        Subject:   subject,
+        ExpiresAt: expiresAt.Time,
+        Claims:    claims,
+    }, nil
+}
+
+// do sends req and decodes a JSON response into v. OAuth error responses
+// (RFC 6749, section 5.2) are turned into errors.
+func (c *Client) do(req *http.Request, v interface{}) error {
+    req.Header.Set("Accept", "application/json")
+    resp, err := c.http.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    
+    body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+    if err != nil {
+        return err
+    }
+    
+    if resp.StatusCode != http.StatusOK {
+        var oauthErr struct {
+            Error       string `json:"error"`
+            Description string `json:"error_description"`
+        }
+        if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+            return fmt.Errorf("%s: %s", oauthErr.Error, oauthErr.Description)
+        }
+        return fmt.Errorf("%s returned %s", req.URL.Redacted(), resp.Status)
+    }
+    
+    return json.Unmarshal(body, v)
+}
+
+// NewVerifier returns a random PKCE code verifier; it doubles as a
+// generator for state and nonce values.
+func NewVerifier() (string, error) {
+    buf := make([]byte, 32)
+    if _, err := rand.Read(buf); err != nil {
+        return "", fmt.Errorf("failed to generate verifier: %w", err)
+    }
+    return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Challenge is the S256 code challenge of verifier.
+func Challenge(verifier string) string {
+    sum := sha256.Sum256([]byte(verifier))
+    return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func contains(values []string, value string) bool {
+    for _, v := range values {
+        if v == value {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,330 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+    ErrInvalidPatch = errors.New("invalid patch")
+    ErrMissingPath  = errors.New("path does not exist")
+    ErrTestFailed   = errors.New("test operation failed")
+)
+
+type operation struct {
+    Op       string
+    Path     string
+    From     string
+    Value    interface{}
+    HasValue bool
+}
+
+func ApplyJSONPatch(doc, patch []byte) ([]byte, error) {
+    var target interface{}
+    if err := json.Unmarshal(doc, &target); err != nil {
+        return nil, fmt.Errorf("invalid document: %w", err)
+    }
+    
+    ops, err := parseOperations(patch)
+    if err != nil {
+        return nil, err
+    }
+    
+    for i, op := range ops {
+        target, err = applyOperation(target, op)
+        if err != nil {
+            return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+        }
+    }
+    
+    return json.Marshal(target)
+}
+
+func parseOperations(patch []byte) ([]operation, error) {
+    var raw []map[string]json.RawMessage
+    if err := json.Unmarshal(patch, &raw); err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+    }
+    
+    ops := make([]operation, 0, len(raw))
+    for i, fields := range raw {
+        var op operation
+        if err := unmarshalField(fields, "op", &op.Op); err != nil {
+            return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+        }
+        if err := unmarshalField(fields, "path", &op.Path); err != nil {
+            return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+        }
+        
+        switch op.Op {
+        case "add", "replace", "test":
+            value, ok := fields["value"]
+            if !ok {
+                return nil, fmt.Errorf("%w: operation %d: 'value' is required for '%s'", ErrInvalidPatch, i, op.Op)
+            }
+            if err := json.Unmarshal(value, &op.Value); err != nil {
+                return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+            }
+            op.HasValue = true
+        case "move", "copy":
+            if err := unmarshalField(fields, "from", &op.From); err != nil {
+                return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+            }
+        case "remove":
+        default:
+            return nil, fmt.Errorf("%w: operation %d: unknown op '%s'", ErrInvalidPatch, i, op.Op)
+        }
+        
+        ops = append(ops, op)
+    }
+    
+    return ops, nil
+}
+
+func unmarshalField(fields map[string]json.RawMessage, name string, dst *string) error {
+    value, ok := fields[name]
+    if !ok {
+        return fmt.Errorf("'%s' is required", name)
+    }
+    
+    if err := json.Unmarshal(value, dst); err != nil {
+        return fmt.Errorf("'%s' must be a string", name)
+    }
+    
+    return nil
+}
+
+func applyOperation(doc interface{}, op operation) (interface{}, error) {
+    path, err := parsePointer(op.Path)
+    if err != nil {
+        return nil, err
+    }
+    
+    switch op.Op {
+    case "add":
+        return add(doc, path, op.Value)
+    case "remove":
+        doc, _, err = remove(doc, path)
+        return doc, err
+    case "replace":
+        if _, err := get(doc, path); err != nil {
+            return nil, err
+        }
+        if len(path) == 0 {
+            return op.Value, nil
+        }
+        doc, _, err = remove(doc, path)
+        if err != nil {
+            return nil, err
+        }
+        return add(doc, path, op.Value)
+    case "move":
+        from, err := parsePointer(op.From)
+        if err != nil {
+            return nil, err
+        }
+        if op.Path != op.From && strings.HasPrefix(op.Path, op.From+"/") {
+            return nil, fmt.Errorf("%w: cannot move '%s' into one of its children", ErrInvalidPatch, op.From)
+        }
+        doc, value, err := remove(doc, from)
+        if err != nil {
+            return nil, err
+        }
+        return add(doc, path, value)
+    case "copy":
+        from, err := parsePointer(op.From)
+        if err != nil {
+            return nil, err
+        }
+        value, err := get(doc, from)
+        if err != nil {
+            return nil, err
+        }
+        copied, err := deepCopy(value)
+        if err != nil {
+            return nil, err
+        }
+        return add(doc, path, copied)
+    case "test":
+        value, err := get(doc, path)
+        if err != nil {
+            return nil, err
+        }
+        if !reflect.DeepEqual(value, op.Value) {
+            return nil, ErrTestFailed
+        }
+        return doc, nil
+    }
+    
+    return nil, fmt.Errorf("%w: unknown op '%s'", ErrInvalidPatch, op.Op)
+}
+
+func parsePointer(pointer string) ([]string, error) {
+    if pointer == "" {
+        return nil, nil
+    }
+    
+    if !strings.HasPrefix(pointer, "/") {
+        return nil, fmt.Errorf("%w: pointer '%s' must start with '/'", ErrInvalidPatch, pointer)
+    }
+    
+    tokens := strings.Split(pointer[1:], "/")
+    for i, token := range tokens {
+        token = strings.ReplaceAll(token, "~1", "/")
+        tokens[i] = strings.ReplaceAll(token, "~0", "~")
+    }
+    
+    return tokens, nil
+}
+
+func get(node interface{}, path []string) (interface{}, error) {
+    for _, token := range path {
+        switch n := node.(type) {
+        case map[string]interface{}:
+            child, ok := n[token]
+            if !ok {
+                return nil, ErrMissingPath
+            }
+            node = child
+        case []interface{}:
+            idx, err := arrayIndex(token, len(n)-1)
+            if err != nil {
+                return nil, err
+            }
+            node = n[idx]
+        default:
+            return nil, ErrMissingPath
+        }
+    }
+    
+    return node, nil
+}
+
+func add(node interface{}, path []string, value interface{}) (interface{}, error) {
+    if len(path) == 0 {
+        return value, nil
+    }
+    
+    token, rest := path[0], path[1:]
+    switch n := node.(type) {
+    case map[string]interface{}:
+        if len(rest) == 0 {
+            n[token] = value
+            return n, nil
+        }
+        child, ok := n[token]
+        if !ok {
+            return nil, ErrMissingPath
+        }
+        updated, err := add(child, rest, value)
+        if err != nil {
+            return nil, err
+        }
+        n[token] = updated
+        return n, nil
+    case []interface{}:
+        if len(rest) == 0 {
+            idx := len(n)
+            if token != "-" {
+                var err error
+                idx, err = arrayIndex(token, len(n))
+                if err != nil {
+                    return nil, err
+                }
+            }
+            n = append(n, nil)
+            copy(n[idx+1:], n[idx:])
+            n[idx] = value
+            return n, nil
+        }
+        idx, err := arrayIndex(token, len(n)-1)
+        if err != nil {
+            return nil, err
+        }
+        updated, err := add(n[idx], rest, value)
+        if err != nil {
+            return nil, err
+        }
+        n[idx] = updated
+        return n, nil
+    }
+    
+    return nil, ErrMissingPath
+}
+
+func remove(node interface{}, path []string) (interface{}, interface{}, error) {
+    if len(path) == 0 {
+        return nil, nil, fmt.Errorf("%w: cannot remove the whole document", ErrInvalidPatch)
+    }
+    
+    token, rest := path[0], path[1:]
+    switch n := node.(type) {
+    case map[string]interface{}:
+        child, ok := n[token]
+        if !ok {
+            return nil, nil, ErrMissingPath
+        }
+        if len(rest) == 0 {
+            delete(n, token)
+            return n, child, nil
+        }
+        updated, removed, err := remove(child, rest)
+        if err != nil {
+            return nil, nil, err
+        }
+        n[token] = updated
+        return n, removed, nil
+    case []interface{}:
+        idx, err := arrayIndex(token, len(n)-1)
+        if err != nil {
+            return nil, nil, err
+        }
+        if len(rest) == 0 {
+            removed := n[idx]
+            return append(n[:idx], n[idx+1:]...), removed, nil
+        }
+        updated, removed, err := remove(n[idx], rest)
+        if err != nil {
+            return nil, nil, err
+        }
+        n[idx] = updated
+        return n, removed, nil
+    }
+    
+    return nil, nil, ErrMissingPath
+}
+
+func arrayIndex(token string, max int) (int, error) {
+    if token == "" || (len(token) > 1 && token[0] == '0') {
+        return 0, fmt.Errorf("%w: invalid array index '%s'", ErrInvalidPatch, token)
+    }
+    
+    idx, err := strconv.Atoi(token)
+    if err != nil || idx < 0 {
+        return 0, fmt.Errorf("%w: invalid array index '%s'", ErrInvalidPatch, token)
+    }
+    
+    if idx > max {
+        return 0, ErrMissingPath
+    }
+    
+    return idx, nil
+}
+
+func deepCopy(value interface{}) (interface{}, error) {
+    data, err := json.Marshal(value)
+    if err != nil {
+        return nil, err
+    }
+    
+    var copied interface{}
+    if err := json.Unmarshal(data, &copied); err != nil {
+        return nil, err
+    }
+    
+    return copied, nil
+}
\ No newline at end of file
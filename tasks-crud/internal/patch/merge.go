@@ -0,0 +1,42 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func ApplyMergePatch(doc, patch []byte) ([]byte, error) {
+    var target interface{}
+    if err := json.Unmarshal(doc, &target); err != nil {
+        return nil, fmt.Errorf("invalid document: %w", err)
+    }
+    
+    var patchValue interface{}
+    if err := json.Unmarshal(patch, &patchValue); err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+    }
+    
+    return json.Marshal(mergePatch(target, patchValue))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+    patchObject, ok := patch.(map[string]interface{})
+    if !ok {
+        return patch
+    }
+    
+    targetObject, ok := target.(map[string]interface{})
+    if !ok {
+        targetObject = make(map[string]interface{})
+    }
+    
+    for key, value := range patchObject {
+        if value == nil {
+            delete(targetObject, key)
+            continue
+        }
+        targetObject[key] = mergePatch(targetObject[key], value)
+    }
+    
+    return targetObject
+}
\ No newline at end of file
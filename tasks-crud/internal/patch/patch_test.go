@@ -0,0 +1,136 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func assertJSON(t *testing.T, got []byte, want string) {
+    t.Helper()
+    
+    var gotValue, wantValue interface{}
+    if err := json.Unmarshal(got, &gotValue); err != nil {
+        t.Fatalf("result is not JSON: %v", err)
+    }
+    if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+        t.Fatalf("expected value is not JSON: %v", err)
+    }
+    if !reflect.DeepEqual(gotValue, wantValue) {
+        t.Errorf("got %s, want %s", got, want)
+    }
+}
+
+// The examples of RFC 7396, Appendix A.
+func TestApplyMergePatch(t *testing.T) {
+    tests := []struct {
+        doc, patch, want string
+    }{
+        {`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+        {`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+        {`{"a":"b"}`, `{"a":null}`, `{}`},
+        {`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+        {`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+        {`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+        {`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+        {`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+        {`["a","b"]`, `["c","d"]`, `["c","d"]`},
+        {`{"a":"b"}`, `["c"]`, `["c"]`},
+        {`{"a":"foo"}`, `null`, `null`},
+        {`{"a":"foo"}`, `"bar"`, `"bar"`},
+        {`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+        {`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+        {`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+    }
+    
+    for _, tt := range tests {
+        got, err := ApplyMergePatch([]byte(tt.doc), []byte(tt.patch))
+        if err != nil {
+            t.Errorf("ApplyMergePatch(%s, %s): %v", tt.doc, tt.patch, err)
+            continue
+        }
+        assertJSON(t, got, tt.want)
+    }
+}
+
+func TestApplyMergePatchRejectsInvalidJSON(t *testing.T) {
+    _, err := ApplyMergePatch([]byte(`{}`), []byte(`{"a":`))
+    if !errors.Is(err, ErrInvalidPatch) {
+        t.Fatalf("got %v, want ErrInvalidPatch", err)
+    }
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+    tests := []struct {
+        name, doc, patch, want string
+    }{
+        {"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`},
+        {"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+        {"append to array", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc"]}]`, `{"foo":["bar",["abc"]]}`},
+        {"add replaces member", `{"foo":"bar"}`, `[{"op":"add","path":"/foo","value":null}]`, `{"foo":null}`},
+        {"add whole document", `{"foo":"bar"}`, `[{"op":"add","path":"","value":[1]}]`, `[1]`},
+        {"remove member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+        {"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+        {"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+        {"replace array element", `{"a":[1,2,3]}`, `[{"op":"replace","path":"/a/2","value":4}]`, `{"a":[1,2,4]}`},
+        {"move member", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+        {"move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+        {"copy is deep", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+        {"test passes", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+        {"test compares objects", `{"a":{"x":1,"y":[true]}}`, `[{"op":"test","path":"/a","value":{"y":[true],"x":1}}]`, `{"a":{"x":1,"y":[true]}}`},
+        {"test null", `{"a":null}`, `[{"op":"test","path":"/a","value":null}]`, `{"a":null}`},
+        {"escaped pointer", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10},{"op":"remove","path":"/~1"}]`, `{"~1":10}`},
+        {"empty key", `{"":1}`, `[{"op":"replace","path":"/","value":2}]`, `{"":2}`},
+    }
+    
+    for _, tt := range tests {
+        got, err := ApplyJSONPatch([]byte(tt.doc), []byte(tt.patch))
+        if err != nil {
+            t.Errorf("%s: %v", tt.name, err)
+            continue
+        }
+        assertJSON(t, got, tt.want)
+    }
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+    tests := []struct {
+        name, doc, patch string
+        want             error
+    }{
+        {"test fails", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, ErrTestFailed},
+        {"test number against string", `{"a":"1"}`, `[{"op":"test","path":"/a","value":1}]`, ErrTestFailed},
+        {"test missing member", `{}`, `[{"op":"test","path":"/a","value":null}]`, ErrMissingPath},
+        {"add to missing parent", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, ErrMissingPath},
+        {"add past end of array", `{"a":[1]}`, `[{"op":"add","path":"/a/2","value":2}]`, ErrMissingPath},
+        {"remove missing member", `{}`, `[{"op":"remove","path":"/a"}]`, ErrMissingPath},
+        {"remove past end of array", `{"a":[1]}`, `[{"op":"remove","path":"/a/1"}]`, ErrMissingPath},
+        {"replace missing member", `{}`, `[{"op":"replace","path":"/a","value":1}]`, ErrMissingPath},
+        {"leading zero index", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/01"}]`, ErrInvalidPatch},
+        {"negative index", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/-1"}]`, ErrInvalidPatch},
+        {"dash is only for add", `{"a":[1]}`, `[{"op":"remove","path":"/a/-"}]`, ErrInvalidPatch},
+        {"move into child", `{"a":{"b":{}}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`, ErrInvalidPatch},
+        {"pointer without slash", `{}`, `[{"op":"add","path":"a","value":1}]`, ErrInvalidPatch},
+        {"missing value", `{}`, `[{"op":"add","path":"/a"}]`, ErrInvalidPatch},
+        {"missing from", `{}`, `[{"op":"copy","path":"/a"}]`, ErrInvalidPatch},
+        {"unknown op", `{}`, `[{"op":"merge","path":"/a","value":1}]`, ErrInvalidPatch},
+        {"not an array", `{}`, `{"op":"add","path":"/a","value":1}`, ErrInvalidPatch},
+    }
+    
+    for _, tt := range tests {
+        _, err := ApplyJSONPatch([]byte(tt.doc), []byte(tt.patch))
+        if !errors.Is(err, tt.want) {
+            t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+        }
+    }
+}
+
+// A failed operation must not leave earlier ones applied: callers only get
+// an error, never a partly patched document.
+func TestApplyJSONPatchIsAllOrNothing(t *testing.T) {
+    got, err := ApplyJSONPatch([]byte(`{"a":1}`), []byte(`[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`))
+    if !errors.Is(err, ErrTestFailed) || got != nil {
+        t.Fatalf("got %s, %v; want nil, ErrTestFailed", got, err)
+    }
+}
@@ -0,0 +1,58 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Query struct {
+    Terms []Term
+}
+
+type Term struct {
+    Pos      int
+    Negated  bool
+    Field    string
+    Op       string
+    Value    string
+    ValuePos int
+    Quoted   bool
+}
+
+type ParseError struct {
+    Pos     int
+    Message string
+}
+
+func (e *ParseError) Error() string {
+    return fmt.Sprintf("position %d: %s", e.Pos, e.Message)
+}
+
+func (q *Query) String() string {
+    parts := make([]string, 0, len(q.Terms))
+    for _, term := range q.Terms {
+        parts = append(parts, term.String())
+    }
+    return strings.Join(parts, " ")
+}
+
+// quoteEscaper escapes what parseQuoted unescapes, so that String output
+// parses back to the same terms.
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func (t Term) String() string {
+    var b strings.Builder
+    if t.Negated {
+        b.WriteString("-")
+    }
+    if t.Field != "" {
+        b.WriteString(t.Field)
+        b.WriteString(t.Op)
+    }
+    if t.Quoted || t.Value == "" || strings.ContainsAny(t.Value, " \t\n\"") {
+        b.WriteString(`"` + quoteEscaper.Replace(t.Value) + `"`)
+    } else {
+        b.WriteString(t.Value)
+    }
+    return b.String()
+}
\ No newline at end of file
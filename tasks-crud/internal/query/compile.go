@@ -0,0 +1,364 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tasks-crud/internal/domain"
+)
+
+var fieldAliases = map[string]string{
+    "status":   domain.FilterFieldStatus,
+    "is":       domain.FilterFieldStatus,
+    "tag":      domain.FilterFieldTag,
+    "tags":     domain.FilterFieldTag,
+    "#":        domain.FilterFieldTag,
+    "priority": domain.FilterFieldPriority,
+    "p":        domain.FilterFieldPriority,
+    "due":      domain.FilterFieldDue,
+    "created":  domain.FilterFieldCreated,
+    "updated":  domain.FilterFieldUpdated,
+    "title":    domain.FilterFieldTitle,
+    "sort":     "sort",
+}
+
+var priorityNames = map[string]int{
+    "none":   domain.PriorityNone,
+    "low":    domain.PriorityLow,
+    "medium": domain.PriorityMedium,
+    "high":   domain.PriorityHigh,
+}
+
+var sortFields = map[string]bool{
+    domain.FilterFieldPriority: true,
+    domain.FilterFieldDue:      true,
+    domain.FilterFieldCreated:  true,
+    domain.FilterFieldUpdated:  true,
+    domain.FilterFieldTitle:    true,
+    domain.FilterFieldID:       true,
+}
+
+var comparisonOps = map[string]string{
+    ":":  domain.FilterOpEq,
+    "=":  domain.FilterOpEq,
+    "<":  domain.FilterOpLt,
+    "<=": domain.FilterOpLte,
+    ">":  domain.FilterOpGt,
+    ">=": domain.FilterOpGte,
+}
+
+func ParseFilter(input string, now time.Time) (domain.TaskFilter, error) {
+    q, err := Parse(input)
+    if err != nil {
+        return domain.TaskFilter{}, err
+    }
+    return Compile(q, now)
+}
+
+func Compile(q *Query, now time.Time) (domain.TaskFilter, error) {
+    filter := domain.TaskFilter{
+        Conditions: make([]domain.FilterCondition, 0, len(q.Terms)),
+        Sort:       make([]domain.SortOrder, 0),
+        Now:        now,
+    }
+    
+    for _, term := range q.Terms {
+        if term.Field == "" {
+            filter.Conditions = append(filter.Conditions, domain.FilterCondition{
+                Field:  domain.FilterFieldText,
+                Op:     domain.FilterOpContains,
+                Values: []string{term.Value},
+                Negate: term.Negated,
+            })
+            continue
+        }
+        
+        field, ok := fieldAliases[term.Field]
+        if !ok {
+            return filter, &ParseError{Pos: term.Pos + negationOffset(term), Message: "unknown field '" + term.Field + "'"}
+        }
+        
+        if field == "sort" {
+            orders, err := compileSort(term)
+            if err != nil {
+                return filter, err
+            }
+            filter.Sort = append(filter.Sort, orders...)
+            continue
+        }
+        
+        condition, err := compileCondition(field, term, now)
+        if err != nil {
+            return filter, err
+        }
+        if condition != nil {
+            filter.Conditions = append(filter.Conditions, *condition)
+        }
+    }
+    
+    return filter, nil
+}
+
+func compileCondition(field string, term Term, now time.Time) (*domain.FilterCondition, error) {
+    condition := &domain.FilterCondition{Field: field, Negate: term.Negated}
+    value := strings.ToLower(term.Value)
+    
+    switch field {
+    case domain.FilterFieldTitle:
+        if err := requireEquality(term); err != nil {
+            return nil, err
+        }
+        condition.Field = domain.FilterFieldText
+        condition.Op = domain.FilterOpContains
+        condition.Values = []string{term.Value}
+    case domain.FilterFieldStatus:
+        if err := requireEquality(term); err != nil {
+            return nil, err
+        }
+        condition.Op = domain.FilterOpEq
+        statuses := splitValues(value)
+        if err := requireValues(term, statuses); err != nil {
+            return nil, err
+        }
+        for _, status := range statuses {
+            switch status {
+            case "all":
+                return nil, nil
+            case "open", "todo", "active":
+                condition.Values = append(condition.Values, domain.StatusOpen)
+            case "done", "completed", "closed":
+                condition.Values = append(condition.Values, domain.StatusDone)
+            case "overdue":
+                condition.Values = append(condition.Values, domain.StatusOverdue)
+            default:
+                return nil, &ParseError{Pos: term.ValuePos, Message: "unknown status '" + status + "' (expected open, done, overdue or all)"}
+            }
+        }
+    case domain.FilterFieldTag:
+        if err := requireEquality(term); err != nil {
+            return nil, err
+        }
+        switch value {
+        case "none":
+            condition.Op = domain.FilterOpExists
+            condition.Negate = !condition.Negate
+        case "any":
+            condition.Op = domain.FilterOpExists
+        default:
+            condition.Op = domain.FilterOpEq
+            tags := splitValues(value)
+            if err := requireValues(term, tags); err != nil {
+                return nil, err
+            }
+            for _, tag := range tags {
+                condition.Values = append(condition.Values, strings.TrimPrefix(tag, "#"))
+            }
+        }
+    case domain.FilterFieldPriority:
+        condition.Op = comparisonOps[term.Op]
+        priority, ok := priorityNames[value]
+        if !ok {
+            number, err := strconv.Atoi(value)
+            if err != nil || number < domain.PriorityNone || number > domain.PriorityHigh {
+                return nil, &ParseError{Pos: term.ValuePos, Message: "invalid priority '" + term.Value + "' (expected none, low, medium, high or 0-3)"}
+            }
+            priority = number
+        }
+        condition.Number = priority
+    case domain.FilterFieldDue, domain.FilterFieldCreated, domain.FilterFieldUpdated:
+        return compileTimeCondition(condition, term, now)
+    }
+    
+    return condition, nil
+}
+
+func compileTimeCondition(condition *domain.FilterCondition, term Term, now time.Time) (*domain.FilterCondition, error) {
+    value := strings.ToLower(term.Value)
+    isEquality := term.Op == ":" || term.Op == "="
+    
+    if condition.Field == domain.FilterFieldDue && isEquality {
+        switch value {
+        case "none":
+            condition.Op = domain.FilterOpExists
+            condition.Negate = !condition.Negate
+            return condition, nil
+        case "any":
+            condition.Op = domain.FilterOpExists
+            return condition, nil
+        case "overdue":
+            condition.Op = domain.FilterOpLt
+            condition.Time = now
+            return condition, nil
+        }
+    }
+    
+    future := condition.Field == domain.FilterFieldDue
+    start, end, isDay, err := parseTime(value, now, future)
+    if err != nil {
+        return nil, &ParseError{Pos: term.ValuePos, Message: err.Error()}
+    }
+    
+    switch {
+    case isEquality && isDay:
+        condition.Op = domain.FilterOpBetween
+        condition.Time = start
+        condition.Until = end
+    case isEquality:
+        condition.Op = domain.FilterOpEq
+        condition.Time = start
+    case isDay && term.Op == ">":
+        condition.Op = domain.FilterOpGte
+        condition.Time = end
+    case isDay && term.Op == "<=":
+        condition.Op = domain.FilterOpLt
+        condition.Time = end
+    default:
+        condition.Op = comparisonOps[term.Op]
+        condition.Time = start
+    }
+    
+    return condition, nil
+}
+
+func compileSort(term Term) ([]domain.SortOrder, error) {
+    if term.Negated {
+        return nil, &ParseError{Pos: term.Pos, Message: "sort cannot be negated"}
+    }
+    if err := requireEquality(term); err != nil {
+        return nil, err
+    }
+    
+    values := splitValues(strings.ToLower(term.Value))
+    if err := requireValues(term, values); err != nil {
+        return nil, err
+    }
+    
+    orders := make([]domain.SortOrder, 0, len(values))
+    for _, value := range values {
+        order := domain.SortOrder{}
+        switch {
+        case strings.HasPrefix(value, "-"):
+            order.Descending = true
+            value = value[1:]
+        case strings.HasPrefix(value, "+"):
+            value = value[1:]
+        default:
+            order.Descending = value == domain.FilterFieldPriority
+        }
+        
+        field, ok := fieldAliases[value]
+        if value == domain.FilterFieldID {
+            field, ok = domain.FilterFieldID, true
+        }
+        if !ok || !sortFields[field] {
+            return nil, &ParseError{Pos: term.ValuePos, Message: "cannot sort by '" + value + "'"}
+        }
+        order.Field = field
+        orders = append(orders, order)
+    }
+    
+    return orders, nil
+}
+
+func parseTime(value string, now time.Time, future bool) (time.Time, time.Time, bool, error) {
+    today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+    
+    switch value {
+    case "today":
+        return today, today.AddDate(0, 0, 1), true, nil
+    case "tomorrow":
+        return today.AddDate(0, 0, 1), today.AddDate(0, 0, 2), true, nil
+    case "yesterday":
+        return today.AddDate(0, 0, -1), today, true, nil
+    case "now":
+        return now, now, false, nil
+    }
+    
+    if day, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+        return day, day.AddDate(0, 0, 1), true, nil
+    }
+    
+    if t, err := time.Parse(time.RFC3339, strings.ToUpper(value)); err == nil {
+        return t, t, false, nil
+    }
+    
+    offset, err := parseOffset(value, future)
+    if err != nil {
+        return time.Time{}, time.Time{}, false, err
+    }
+    
+    return now.Add(offset), now.Add(offset), false, nil
+}
+
+func parseOffset(value string, future bool) (time.Duration, error) {
+    invalid := fmt.Errorf("invalid date '%s' (expected YYYY-MM-DD, today, tomorrow or an offset like 7d, 2w, 12h)", value)
+    
+    sign := time.Duration(1)
+    if !future {
+        sign = -1
+    }
+    switch {
+    case strings.HasPrefix(value, "+"):
+        sign = 1
+        value = value[1:]
+    case strings.HasPrefix(value, "-"):
+        sign = -1
+        value = value[1:]
+    }
+    
+    if len(value) < 2 {
+        return 0, invalid
+    }
+    
+    amount, err := strconv.Atoi(value[:len(value)-1])
+    if err != nil || amount < 0 {
+        return 0, invalid
+    }
+    
+    var unit time.Duration
+    switch value[len(value)-1] {
+    case 'h':
+        unit = time.Hour
+    case 'd':
+        unit = 24 * time.Hour
+    case 'w':
+        unit = 7 * 24 * time.Hour
+    default:
+        return 0, invalid
+    }
+    
+    return sign * time.Duration(amount) * unit, nil
+}
+
+func requireValues(term Term, values []string) error {
+    if len(values) == 0 {
+        return &ParseError{Pos: term.ValuePos, Message: "expected value after '" + term.Field + term.Op + "'"}
+    }
+    return nil
+}
+
+func requireEquality(term Term) error {
+    if term.Op != ":" && term.Op != "=" {
+        return &ParseError{Pos: term.ValuePos - len([]rune(term.Op)), Message: "operator '" + term.Op + "' is not supported for '" + term.Field + "'"}
+    }
+    return nil
+}
+
+func splitValues(value string) []string {
+    values := make([]string, 0)
+    for _, part := range strings.Split(value, ",") {
+        if part = strings.TrimSpace(part); part != "" {
+            values = append(values, part)
+        }
+    }
+    return values
+}
+
+func negationOffset(term Term) int {
+    if term.Negated {
+        return 1
+    }
+    return 0
+}
\ No newline at end of file
@@ -0,0 +1,163 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+var operators = []string{"<=", ">=", ":", "<", ">", "="}
+
+type parser struct {
+    input []rune
+    pos   int
+}
+
+func Parse(input string) (*Query, error) {
+    p := &parser{input: []rune(input)}
+    query := &Query{Terms: make([]Term, 0)}
+    
+    for {
+        p.skipSpaces()
+        if p.eof() {
+            return query, nil
+        }
+        
+        term, err := p.parseTerm()
+        if err != nil {
+            return nil, err
+        }
+        query.Terms = append(query.Terms, term)
+    }
+}
+
+func (p *parser) parseTerm() (Term, error) {
+    term := Term{Pos: p.column()}
+    
+    if p.peek() == '-' && p.pos+1 < len(p.input) && !unicode.IsSpace(p.input[p.pos+1]) {
+        term.Negated = true
+        p.pos++
+    }
+    
+    if p.peek() == '"' {
+        term.ValuePos = p.column()
+        value, err := p.parseQuoted()
+        if err != nil {
+            return term, err
+        }
+        term.Value = value
+        term.Quoted = true
+        return term, p.expectTermEnd()
+    }
+    
+    start := p.pos
+    for !p.eof() && isFieldRune(p.peek()) {
+        p.pos++
+    }
+    
+    if op := p.matchOperator(); op != "" && p.pos > start {
+        term.Field = strings.ToLower(string(p.input[start:p.pos]))
+        term.Op = op
+        p.pos += len([]rune(op))
+        
+        term.ValuePos = p.column()
+        if p.peek() == '"' {
+            value, err := p.parseQuoted()
+            if err != nil {
+                return term, err
+            }
+            term.Value = value
+            term.Quoted = true
+            return term, p.expectTermEnd()
+        }
+        
+        term.Value = p.parseWord()
+        if term.Value == "" {
+            return term, &ParseError{Pos: term.ValuePos, Message: "expected value after '" + term.Field + term.Op + "'"}
+        }
+        return term, nil
+    }
+    
+    p.pos = start
+    term.ValuePos = p.column()
+    term.Value = p.parseWord()
+    if term.Value == "" {
+        return term, &ParseError{Pos: term.ValuePos, Message: "unexpected character '" + string(p.peek()) + "'"}
+    }
+    
+    return term, nil
+}
+
+func (p *parser) parseQuoted() (string, error) {
+    start := p.column()
+    p.pos++
+    
+    var b strings.Builder
+    for !p.eof() {
+        r := p.peek()
+        p.pos++
+        switch r {
+        case '\\':
+            if p.eof() {
+                return "", &ParseError{Pos: p.column(), Message: "unterminated escape sequence"}
+            }
+            b.WriteRune(p.peek())
+            p.pos++
+        case '"':
+            return b.String(), nil
+        default:
+            b.WriteRune(r)
+        }
+    }
+    
+    return "", &ParseError{Pos: start, Message: "unterminated quoted string"}
+}
+
+func (p *parser) parseWord() string {
+    start := p.pos
+    for !p.eof() && !unicode.IsSpace(p.peek()) {
+        p.pos++
+    }
+    return string(p.input[start:p.pos])
+}
+
+func (p *parser) matchOperator() string {
+    rest := string(p.input[p.pos:])
+    for _, op := range operators {
+        if strings.HasPrefix(rest, op) {
+            return op
+        }
+    }
+    return ""
+}
+
+func (p *parser) expectTermEnd() error {
+    if !p.eof() && !unicode.IsSpace(p.peek()) {
+        return &ParseError{Pos: p.column(), Message: "expected space after quoted string"}
+    }
+    return nil
+}
+
+func (p *parser) skipSpaces() {
+    for !p.eof() && unicode.IsSpace(p.peek()) {
+        p.pos++
+    }
+}
+
+func (p *parser) peek() rune {
+    if p.eof() {
+        return 0
+    }
+    return p.input[p.pos]
+}
+
+func (p *parser) eof() bool {
+    return p.pos >= len(p.input)
+}
+
+func (p *parser) column() int {
+    return p.pos + 1
+}
+
+func isFieldRune(r rune) bool {
+    return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '#'
+}
\ No newline at end of file
@@ -0,0 +1,53 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueryStringRoundTrips(t *testing.T) {
+    inputs := []string{
+        `status:open tag:backend due<7d -tag:blocked sort:priority`,
+        `"fix \"login\" page"`,
+        `title:"C:\\temp\\new"`,
+        `"ends with backslash \\"`,
+        `-"" priority>=2`,
+    }
+    
+    for _, input := range inputs {
+        parsed, err := Parse(input)
+        if err != nil {
+            t.Fatalf("Parse(%q): %v", input, err)
+        }
+        
+        reparsed, err := Parse(parsed.String())
+        if err != nil {
+            t.Fatalf("Parse(%q) of String() of %q: %v", parsed.String(), input, err)
+        }
+        if !reflect.DeepEqual(termValues(reparsed), termValues(parsed)) {
+            t.Errorf("%q: String() = %q, which parses to %+v, want %+v", input, parsed.String(), termValues(reparsed), termValues(parsed))
+        }
+    }
+}
+
+func TestTermStringQuotesValuesThatNeedIt(t *testing.T) {
+    term := Term{Value: `a "b" c\d`}
+    
+    parsed, err := Parse(term.String())
+    if err != nil {
+        t.Fatalf("Parse(%q): %v", term.String(), err)
+    }
+    if len(parsed.Terms) != 1 || parsed.Terms[0].Value != term.Value {
+        t.Errorf("Parse(%q) = %+v, want one term with value %q", term.String(), parsed.Terms, term.Value)
+    }
+}
+
+// termValues drops positions, which depend on the spelling of a query.
+func termValues(q *Query) []Term {
+    terms := make([]Term, 0, len(q.Terms))
+    for _, term := range q.Terms {
+        term.Pos, term.ValuePos = 0, 0
+        terms = append(terms, term)
+    }
+    return terms
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"tasks-crud/internal/domain"
+)
+
+type APIKeyRepository interface {
+    GetAll() ([]domain.APIKey, error)
+    GetByHash(hash string) (*domain.APIKey, error)
+    Create(key *domain.APIKey) error
+    Touch(id int, at time.Time) error
+    Revoke(id int, at time.Time) error
+    DeleteByTenant(tenantID string) error
+}
+
+type InMemoryAPIKeyRepository struct {
+    keys      map[int]domain.APIKey
+    currentID int
+    mu        sync.RWMutex
+}
+
+func NewInMemoryAPIKeyRepository() *InMemoryAPIKeyRepository {
+    return &InMemoryAPIKeyRepository{
+        keys:      make(map[int]domain.APIKey),
+        currentID: 1,
+    }
+}
+
+func (r *InMemoryAPIKeyRepository) GetAll() ([]domain.APIKey, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    keyList := make([]domain.APIKey, 0, len(r.keys))
+    for _, key := range r.keys {
+        keyList = append(keyList, key)
+    }
+    sort.Slice(keyList, func(i, j int) bool {
+        return keyList[i].ID < keyList[j].ID
+    })
+    
+    return keyList, nil
+}
+
+func (r *InMemoryAPIKeyRepository) GetByHash(hash string) (*domain.APIKey, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    for _, key := range r.keys {
+        if key.KeyHash == hash {
+            return &key, nil
+        }
+    }
+    
+    return nil, fmt.Errorf("api key %w", domain.ErrNotFound)
+}
+
+func (r *InMemoryAPIKeyRepository) Create(key *domain.APIKey) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    key.ID = r.currentID
+    key.CreatedAt = time.Now()
+    
+    stored := *key
+    stored.Key = ""
+    r.keys[r.currentID] = stored
+    r.currentID++
+    
+    return nil
+}
+
+func (r *InMemoryAPIKeyRepository) Touch(id int, at time.Time) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    key, exists := r.keys[id]
+    if !exists {
+        return fmt.Errorf("api key with id %d %w", id, domain.ErrNotFound)
+    }
+    
+    key.LastUsedAt = &at
+    r.keys[id] = key
+    
+    return nil
+}
+
+func (r *InMemoryAPIKeyRepository) Revoke(id int, at time.Time) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    key, exists := r.keys[id]
+    if !exists {
+        return fmt.Errorf("api key with id %d %w", id, domain.ErrNotFound)
+    }
+    
+    if key.RevokedAt == nil {
+        key.RevokedAt = &at
+        r.keys[id] = key
+    }
+    
+    return nil
+}
+
+func (r *InMemoryAPIKeyRepository) DeleteByTenant(tenantID string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    for id, key := range r.keys {
+        if key.TenantID == tenantID {
+            delete(r.keys, id)
+        }
+    }
+    
+    return nil
+}
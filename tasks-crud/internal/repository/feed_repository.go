@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"tasks-crud/internal/domain"
+)
+
+type CalendarFeedRepository interface {
+    GetAll() ([]domain.CalendarFeed, error)
+    GetByTokenHash(hash string) (*domain.CalendarFeed, error)
+    Create(feed *domain.CalendarFeed) error
+    Touch(id int, at time.Time) error
+    Delete(id int) error
+}
+
+type InMemoryCalendarFeedRepository struct {
+    feeds     map[int]domain.CalendarFeed
+    currentID int
+    mu        sync.RWMutex
+}
+
+func NewInMemoryCalendarFeedRepository() *InMemoryCalendarFeedRepository {
+    return &InMemoryCalendarFeedRepository{
+        feeds:     make(map[int]domain.CalendarFeed),
+        currentID: 1,
+    }
+}
+
+func (r *InMemoryCalendarFeedRepository) GetAll() ([]domain.CalendarFeed, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    feedList := make([]domain.CalendarFeed, 0, len(r.feeds))
+    for _, feed := range r.feeds {
+        feedList = append(feedList, feed)
+    }
+    sort.Slice(feedList, func(i, j int) bool {
+        return feedList[i].ID < feedList[j].ID
+    })
+    
+    return feedList, nil
+}
+
+func (r *InMemoryCalendarFeedRepository) GetByTokenHash(hash string) (*domain.CalendarFeed, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    for _, feed := range r.feeds {
+        if feed.TokenHash == hash {
+            return &feed, nil
+        }
+    }
+    
+    return nil, fmt.Errorf("feed %w", domain.ErrNotFound)
+}
+
+func (r *InMemoryCalendarFeedRepository) Create(feed *domain.CalendarFeed) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    feed.ID = r.currentID
+    feed.CreatedAt = time.Now()
+    
+    stored := *feed
+    stored.URL = ""
+    r.feeds[r.currentID] = stored
+    r.currentID++
+    
+    return nil
+}
+
+func (r *InMemoryCalendarFeedRepository) Touch(id int, at time.Time) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    feed, exists := r.feeds[id]
+    if !exists {
+        return fmt.Errorf("feed with id %d %w", id, domain.ErrNotFound)
+    }
+    
+    feed.LastUsedAt = &at
+    r.feeds[id] = feed
+    
+    return nil
+}
+
+func (r *InMemoryCalendarFeedRepository) Delete(id int) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    if _, exists := r.feeds[id]; !exists {
+        return fmt.Errorf("feed with id %d %w", id, domain.ErrNotFound)
+    }
+    
+    delete(r.feeds, id)
+    
+    return nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"tasks-crud/internal/domain"
+)
+
+type SavedFilterRepository interface {
+    GetAll() ([]domain.SavedFilter, error)
+    GetByID(id int) (*domain.SavedFilter, error)
+    Create(filter *domain.SavedFilter) error
+    Update(id int, filter *domain.SavedFilter) error
+    Delete(id int) error
+}
+
+type InMemorySavedFilterRepository struct {
+    filters   map[int]domain.SavedFilter
+    currentID int
+    mu        sync.RWMutex
+}
+
+func NewInMemorySavedFilterRepository() *InMemorySavedFilterRepository {
+    return &InMemorySavedFilterRepository{
+        filters:   make(map[int]domain.SavedFilter),
+        currentID: 1,
+    }
+}
+
+func (r *InMemorySavedFilterRepository) GetAll() ([]domain.SavedFilter, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    filterList := make([]domain.SavedFilter, 0, len(r.filters))
+    for _, filter := range r.filters {
+        filterList = append(filterList, filter)
+    }
+    sort.Slice(filterList, func(i, j int) bool {
+        return filterList[i].ID < filterList[j].ID
+    })
+    
+    return filterList, nil
+}
+
+func (r *InMemorySavedFilterRepository) GetByID(id int) (*domain.SavedFilter, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    filter, exists := r.filters[id]
+    if !exists {
+        return nil, fmt.Errorf("filter with id %d %w", id, domain.ErrNotFound)
+    }
+    
+    return &filter, nil
+}
+
+func (r *InMemorySavedFilterRepository) Create(filter *domain.SavedFilter) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    filter.ID = r.currentID
+    filter.CreatedAt = time.Now()
+    filter.UpdatedAt = filter.CreatedAt
+    
+    r.filters[r.currentID] = *filter
+    r.currentID++
+    
+    return nil
+}
+
+func (r *InMemorySavedFilterRepository) Update(id int, filter *domain.SavedFilter) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    if _, exists := r.filters[id]; !exists {
+        return fmt.Errorf("filter with id %d %w", id, domain.ErrNotFound)
+    }
+    
+    filter.UpdatedAt = time.Now()
+    r.filters[id] = *filter
+    
+    return nil
+}
+
+func (r *InMemorySavedFilterRepository) Delete(id int) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    if _, exists := r.filters[id]; !exists {
+        return fmt.Errorf("filter with id %d %w", id, domain.ErrNotFound)
+    }
+    
+    delete(r.filters, id)
+    
+    return nil
+}
\ No newline at end of file
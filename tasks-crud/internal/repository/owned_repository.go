@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sort"
+
+	"tasks-crud/internal/domain"
+)
+
+// OwnedTaskRepository restricts another repository to what one user may
+// access: their own tasks and the tasks shared with them. Tasks the user
+// can't see behave exactly like missing ones, so their existence is not
+// revealed; visible tasks the user may not change give domain.ErrForbidden.
+type OwnedTaskRepository struct {
+    base   TaskRepository
+    owner  string
+    shares ShareRepository
+    limit  domain.Role
+}
+
+// NewOwnedTaskRepository scopes base to owner. shares may be nil, in which
+// case only the owner's own tasks are visible.
+func NewOwnedTaskRepository(base TaskRepository, owner string, shares ShareRepository) *OwnedTaskRepository {
+    return &OwnedTaskRepository{
+        base:   base,
+        owner:  owner,
+        shares: shares,
+    }
+}
+
+// Limit returns a copy of r in which the owner has at most role on any
+// task. Creating tasks needs the editor role.
+func (r *OwnedTaskRepository) Limit(role domain.Role) *OwnedTaskRepository {
+    return &OwnedTaskRepository{
+        base:   r.base,
+        owner:  r.owner,
+        shares: r.shares,
+        limit:  role,
+    }
+}
+
+// Role returns the role the owner has on task, or "" if it's not visible.
+func (r *OwnedTaskRepository) Role(task domain.Task) domain.Role {
+    return domain.RoleOf(r.owner, r.grants(), task).Limit(r.limit)
+}
+
+// CanCreate reports whether the owner may create tasks.
+func (r *OwnedTaskRepository) CanCreate() bool {
+    return r.limit == "" || r.limit.Allows(domain.RoleEditor)
+}
+
+// Owner returns the user r is scoped to.
+func (r *OwnedTaskRepository) Owner() string {
+    return r.owner
+}
+
+// Unscoped returns the repository r restricts.
+func (r *OwnedTaskRepository) Unscoped() TaskRepository {
+    return r.base
+}
+
+func (r *OwnedTaskRepository) GetAll() ([]domain.Task, error) {
+    tasks, err := r.base.GetAll()
+    if err != nil {
+        return nil, err
+    }
+    
+    return r.filter(tasks), nil
+}
+
+func (r *OwnedTaskRepository) List(filter domain.TaskFilter) ([]domain.Task, error) {
+    tasks, err := r.base.List(filter)
+    if err != nil {
+        return nil, err
+    }
+    
+    return r.filter(tasks), nil
+}
+
+func (r *OwnedTaskRepository) GetByID(id int) (*domain.Task, error) {
+    task, err := r.base.GetByID(id)
+    if err != nil {
+        return nil, err
+    }
+    
+    if r.Role(*task) == "" {
+        return nil, fmt.Errorf("task with id %d %w", id, domain.ErrNotFound)
+    }
+    
+    return task, nil
+}
+
+func (r *OwnedTaskRepository) Create(task *domain.Task) error {
+    if !r.CanCreate() {
+        return fmt.Errorf("%w: %s role required to create tasks", domain.ErrForbidden, domain.RoleEditor)
+    }
+    
+    task.OwnerID = r.owner
+    return r.base.Create(task)
+}
+
+// CreateIf checks against every task of the base repository, not just
+// those the owner can see.
+func (r *OwnedTaskRepository) CreateIf(task *domain.Task, check func(stored iter.Seq[domain.Task]) error) error {
+    if !r.CanCreate() {
+        return fmt.Errorf("%w: %s role required to create tasks", domain.ErrForbidden, domain.RoleEditor)
+    }
+    
+    task.OwnerID = r.owner
+    return r.base.CreateIf(task, check)
+}
+
+// Update needs the editor role. The task keeps its owner, whoever edits it.
+func (r *OwnedTaskRepository) Update(id int, task *domain.Task) error {
+    existing, err := r.require(id, domain.RoleEditor)
+    if err != nil {
+        return err
+    }
+    
+    task.OwnerID = existing.OwnerID
+    return r.base.Update(id, task)
+}
+
+// Delete needs the owner role.
+func (r *OwnedTaskRepository) Delete(id int) error {
+    if _, err := r.require(id, domain.RoleOwner); err != nil {
+        return err
+    }
+    
+    return r.base.Delete(id)
+}
+
+// ChangesSince reports deletions of the owner's tasks and of tasks shared
+// with them individually; a deleted task no longer has tags, so deletions
+// seen only through a project share can't be attributed. Tasks the owner
+// lost access to when a share was revoked are reported like deletions, so
+// their clients don't keep stale copies.
+func (r *OwnedTaskRepository) ChangesSince(revision int64) ([]domain.Task, []domain.Tombstone, int64, error) {
+    changed, deleted, current, err := r.base.ChangesSince(revision)
+    if err != nil {
+        return nil, nil, 0, err
+    }
+    
+    grants := r.grants()
+    ownDeleted := make([]domain.Tombstone, 0, len(deleted))
+    reported := make(map[int]bool, len(deleted))
+    for _, tombstone := range deleted {
+        if domain.RoleOf(r.owner, grants, domain.Task{ID: tombstone.ID, OwnerID: tombstone.OwnerID}) != "" {
+            ownDeleted = append(ownDeleted, tombstone)
+            reported[tombstone.ID] = true
+        }
+    }
+    
+    lost, err := r.lostSince(revision, grants, reported)
+    if err != nil {
+        return nil, nil, 0, err
+    }
+    ownDeleted = append(ownDeleted, lost...)
+    sort.Slice(ownDeleted, func(i, j int) bool {
+        return ownDeleted[i].Revision < ownDeleted[j].Revision
+    })
+    
+    return r.filter(changed), ownDeleted, current, nil
+}
+
+// lostSince returns tombstones for the tasks that shares revoked after
+// revision covered and that the owner can't see now. Tasks in reported
+// already have one.
+func (r *OwnedTaskRepository) lostSince(revision int64, grants []domain.Share, reported map[int]bool) ([]domain.Tombstone, error) {
+    revocations, err := r.RevocationsSince(revision)
+    if err != nil || len(revocations) == 0 {
+        return nil, err
+    }
+    
+    lost := make(map[int]domain.Tombstone)
+    add := func(id int, revocation domain.Revocation) {
+        if !reported[id] {
+            lost[id] = domain.Tombstone{
+                ID:        id,
+                Revision:  revocation.Revision,
+                DeletedAt: revocation.RevokedAt,
+                Revoked:   true,
+                OwnerID:   revocation.Share.OwnerID,
+            }
+        }
+    }
+    
+    var tasks []domain.Task
+    for _, revocation := range revocations {
+        share := revocation.Share
+        if share.TaskID != nil {
+            task, err := r.base.GetByID(*share.TaskID)
+            if err != nil || domain.RoleOf(r.owner, grants, *task) == "" {
+                add(*share.TaskID, revocation)
+            }
+            continue
+        }
+        
+        if tasks == nil {
+            if tasks, err = r.base.GetAll(); err != nil {
+                return nil, err
+            }
+        }
+        for _, task := range tasks {
+            if task.OwnerID == share.OwnerID && task.Project() == share.Project && domain.RoleOf(r.owner, grants, task) == "" {
+                add(task.ID, revocation)
+            }
+        }
+    }
+    
+    tombstones := make([]domain.Tombstone, 0, len(lost))
+    for _, tombstone := range lost {
+        tombstones = append(tombstones, tombstone)
+    }
+    return tombstones, nil
+}
+
+func (r *OwnedTaskRepository) Revoke(share domain.Share) error {
+    return r.base.Revoke(share)
+}
+
+// RevocationsSince lists only the revocations of shares granted to the
+// owner.
+func (r *OwnedTaskRepository) RevocationsSince(revision int64) ([]domain.Revocation, error) {
+    revocations, err := r.base.RevocationsSince(revision)
+    if err != nil {
+        return nil, err
+    }
+    
+    own := make([]domain.Revocation, 0, len(revocations))
+    for _, revocation := range revocations {
+        if revocation.Share.GranteeID == r.owner {
+            own = append(own, revocation)
+        }
+    }
+    return own, nil
+}
+
+func (r *OwnedTaskRepository) CurrentRevision() (int64, error) {
+    return r.base.CurrentRevision()
+}
+
+func (r *OwnedTaskRepository) WithinTransaction(fn func(tx TaskRepository) error) error {
+    return r.base.WithinTransaction(func(tx TaskRepository) error {
+        return fn(NewOwnedTaskRepository(tx, r.owner, r.shares).Limit(r.limit))
+    })
+}
+
+// Search scores only the tasks visible to the owner, so rankings don't
+// depend on anyone else's tasks.
+func (r *OwnedTaskRepository) Search(query string, limit int, visible func(domain.Task) bool) ([]domain.SearchResult, error) {
+    grants := r.grants()
+    return r.base.Search(query, limit, func(task domain.Task) bool {
+        return domain.RoleOf(r.owner, grants, task) != "" && (visible == nil || visible(task))
+    })
+}
+
+func (r *OwnedTaskRepository) History(id int) ([]domain.TaskEvent, error) {
+    history, err := r.base.History(id)
+    if err != nil {
+        return nil, err
+    }
+    
+    if len(history) == 0 || history[len(history)-1].Task == nil || r.Role(*history[len(history)-1].Task) == "" {
+        return nil, fmt.Errorf("task with id %d %w", id, domain.ErrNotFound)
+    }
+    
+    return history, nil
+}
+
+func (r *OwnedTaskRepository) Watch(ctx context.Context) (<-chan domain.TaskEvent, error) {
+    events, err := r.base.Watch(ctx)
+    if err != nil {
+        return nil, err
+    }
+    
+    owned := make(chan domain.TaskEvent)
+    go func() {
+        defer close(owned)
+        for event := range events {
+            if event.Task == nil || r.Role(*event.Task) == "" {
+                continue
+            }
+            select {
+            case owned <- event:
+            case <-ctx.Done():
+                return
+            }
+        }
+    }()
+    
+    return owned, nil
+}
+
+func (r *OwnedTaskRepository) require(id int, role domain.Role) (*domain.Task, error) {
+    existing, err := r.GetByID(id)
+    if err != nil {
+        return nil, err
+    }
+    
+    if !r.Role(*existing).Allows(role) {
+        return nil, fmt.Errorf("%w: %s role required for task %d", domain.ErrForbidden, role, id)
+    }
+    
+    return existing, nil
+}
+
+func (r *OwnedTaskRepository) filter(tasks []domain.Task) []domain.Task {
+    grants := r.grants()
+    owned := make([]domain.Task, 0, len(tasks))
+    for _, task := range tasks {
+        if domain.RoleOf(r.owner, grants, task) != "" {
+            owned = append(owned, task)
+        }
+    }
+    return owned
+}
+
+func (r *OwnedTaskRepository) grants() []domain.Share {
+    if r.shares == nil {
+        return nil
+    }
+    
+    shares, err := r.shares.GetByGrantee(r.owner)
+    if err != nil {
+        return nil
+    }
+    return shares
+}
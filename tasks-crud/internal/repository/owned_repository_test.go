@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"testing"
+
+	"tasks-crud/internal/domain"
+)
+
+// Another user's tasks must not change how the owner's tasks rank, or the
+// scores would reveal what those tasks contain.
+func TestOwnedSearchScoresOnlyVisibleTasks(t *testing.T) {
+    alone := NewEmptyInMemoryTaskRepository()
+    shared := NewEmptyInMemoryTaskRepository()
+    for _, repo := range []*InMemoryTaskRepository{alone, shared} {
+        owned := NewOwnedTaskRepository(repo, "alice", nil)
+        for _, title := range []string{"pay invoice", "plan trip"} {
+            if err := owned.Create(&domain.Task{Title: title}); err != nil {
+                t.Fatal(err)
+            }
+        }
+    }
+    other := NewOwnedTaskRepository(shared, "bob", nil)
+    for i := 0; i < 5; i++ {
+        if err := other.Create(&domain.Task{Title: "invoice draft"}); err != nil {
+            t.Fatal(err)
+        }
+    }
+    
+    want, err := NewOwnedTaskRepository(alone, "alice", nil).Search("invoice", 0, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    got, err := NewOwnedTaskRepository(shared, "alice", nil).Search("invoice", 0, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    if len(got) != 1 || len(want) != 1 {
+        t.Fatalf("got %d results, want 1 (and %d without bob's tasks)", len(got), len(want))
+    }
+    if got[0].Task.OwnerID != "alice" {
+        t.Errorf("result owned by %q, want alice", got[0].Task.OwnerID)
+    }
+    if got[0].Score != want[0].Score {
+        t.Errorf("score %v with bob's tasks, %v without", got[0].Score, want[0].Score)
+    }
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"tasks-crud/internal/domain"
+)
+
+type SessionRepository interface {
+    GetByID(id string) (*domain.Session, error)
+    Create(session *domain.Session) error
+    // Rotate replaces the refresh hash only if it still equals oldHash, so
+    // a refresh token can be exchanged at most once.
+    Rotate(id, oldHash, newHash string) error
+    Revoke(id string, at time.Time) error
+    // RevokeUser revokes every session of a user except keep.
+    RevokeUser(userID int, keep string, at time.Time) error
+}
+
+type InMemorySessionRepository struct {
+    sessions map[string]domain.Session
+    mu       sync.RWMutex
+}
+
+func NewInMemorySessionRepository() *InMemorySessionRepository {
+    return &InMemorySessionRepository{
+        sessions: make(map[string]domain.Session),
+    }
+}
+
+func (r *InMemorySessionRepository) GetByID(id string) (*domain.Session, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    session, exists := r.sessions[id]
+    if !exists {
+        return nil, fmt.Errorf("session %w", domain.ErrNotFound)
+    }
+    
+    return &session, nil
+}
+
+func (r *InMemorySessionRepository) Create(session *domain.Session) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    if _, exists := r.sessions[session.ID]; exists {
+        return fmt.Errorf("session %w", domain.ErrAlreadyExists)
+    }
+    
+    session.CreatedAt = time.Now()
+    r.sessions[session.ID] = *session
+    
+    return nil
+}
+
+func (r *InMemorySessionRepository) Rotate(id, oldHash, newHash string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    session, exists := r.sessions[id]
+    if !exists {
+        return fmt.Errorf("session %w", domain.ErrNotFound)
+    }
+    if session.RefreshHash != oldHash {
+        return fmt.Errorf("refresh token has already been used")
+    }
+    
+    session.RefreshHash = newHash
+    r.sessions[id] = session
+    
+    return nil
+}
+
+func (r *InMemorySessionRepository) Revoke(id string, at time.Time) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    session, exists := r.sessions[id]
+    if !exists {
+        return fmt.Errorf("session %w", domain.ErrNotFound)
+    }
+    
+    if session.RevokedAt == nil {
+        session.RevokedAt = &at
+        r.sessions[id] = session
+    }
+    
+    return nil
+}
+
+func (r *InMemorySessionRepository) RevokeUser(userID int, keep string, at time.Time) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    for id, session := range r.sessions {
+        if session.UserID != userID || id == keep || session.RevokedAt != nil {
+            continue
+        }
+        session.RevokedAt = &at
+        r.sessions[id] = session
+    }
+    
+    return nil
+}
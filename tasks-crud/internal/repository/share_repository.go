@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"tasks-crud/internal/domain"
+)
+
+type ShareRepository interface {
+    GetAll() ([]domain.Share, error)
+    // GetByGrantee returns the shares granted to a user, which is all
+    // that's needed to decide what that user may see.
+    GetByGrantee(grantee string) ([]domain.Share, error)
+    // Save creates a share, or changes the role of an existing share of
+    // the same target with the same grantee.
+    Save(share *domain.Share) error
+    Delete(id int) error
+}
+
+type InMemoryShareRepository struct {
+    shares    map[int]domain.Share
+    currentID int
+    mu        sync.RWMutex
+}
+
+func NewInMemoryShareRepository() *InMemoryShareRepository {
+    return &InMemoryShareRepository{
+        shares:    make(map[int]domain.Share),
+        currentID: 1,
+    }
+}
+
+func (r *InMemoryShareRepository) GetAll() ([]domain.Share, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    return r.sorted(func(domain.Share) bool { return true }), nil
+}
+
+func (r *InMemoryShareRepository) GetByGrantee(grantee string) ([]domain.Share, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    return r.sorted(func(share domain.Share) bool { return share.GranteeID == grantee }), nil
+}
+
+func (r *InMemoryShareRepository) Save(share *domain.Share) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    for id, existing := range r.shares {
+        if sameTarget(existing, *share) {
+            existing.Role = share.Role
+            r.shares[id] = existing
+            *share = existing
+            return nil
+        }
+    }
+    
+    share.ID = r.currentID
+    share.CreatedAt = time.Now()
+    r.shares[r.currentID] = *share
+    r.currentID++
+    
+    return nil
+}
+
+func (r *InMemoryShareRepository) Delete(id int) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    if _, exists := r.shares[id]; !exists {
+        return fmt.Errorf("share with id %d %w", id, domain.ErrNotFound)
+    }
+    
+    delete(r.shares, id)
+    
+    return nil
+}
+
+func (r *InMemoryShareRepository) sorted(keep func(domain.Share) bool) []domain.Share {
+    shareList := make([]domain.Share, 0)
+    for _, share := range r.shares {
+        if keep(share) {
+            shareList = append(shareList, share)
+        }
+    }
+    sort.Slice(shareList, func(i, j int) bool {
+        return shareList[i].ID < shareList[j].ID
+    })
+    return shareList
+}
+
+func sameTarget(a, b domain.Share) bool {
+    if a.OwnerID != b.OwnerID || a.GranteeID != b.GranteeID || a.Project != b.Project {
+        return false
+    }
+    if a.TaskID == nil || b.TaskID == nil {
+        return a.TaskID == nil && b.TaskID == nil
+    }
+    return *a.TaskID == *b.TaskID
+}
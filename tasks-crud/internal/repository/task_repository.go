@@ -1,46 +1,120 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"iter"
+	"maps"
+	"sort"
 	"sync"
 	"time"
 
 	"tasks-crud/internal/domain"
+	"tasks-crud/internal/events"
+	"tasks-crud/internal/search"
 )
 
 type TaskRepository interface {
     GetAll() ([]domain.Task, error)
+    List(filter domain.TaskFilter) ([]domain.Task, error)
     GetByID(id int) (*domain.Task, error)
     Create(task *domain.Task) error
+    // CreateIf is Create unless check fails. check is given every stored
+    // task, whatever the caller may see, and runs under the same lock as
+    // the insert, so concurrent creates can't all pass it.
+    CreateIf(task *domain.Task, check func(stored iter.Seq[domain.Task]) error) error
     Update(id int, task *domain.Task) error
     Delete(id int) error
+    // ChangesSince fails with domain.ErrSyncTokenExpired for revisions
+    // older than the tombstones still kept.
+    ChangesSince(revision int64) ([]domain.Task, []domain.Tombstone, int64, error)
+    // Revoke records, at a revision of its own, that share was withdrawn;
+    // RevocationsSince lists the revocations after revision.
+    Revoke(share domain.Share) error
+    RevocationsSince(revision int64) ([]domain.Revocation, error)
+    CurrentRevision() (int64, error)
+    WithinTransaction(fn func(tx TaskRepository) error) error
+    // Search ranks the tasks matching query. If visible is set, only the
+    // tasks it accepts are searched and scored.
+    Search(query string, limit int, visible func(domain.Task) bool) ([]domain.SearchResult, error)
+    History(id int) ([]domain.TaskEvent, error)
+    Watch(ctx context.Context) (<-chan domain.TaskEvent, error)
 }
 
+const maxHistoryPerTask = 100
+
+// tombstoneRetention is how long deletions and revocations are kept for
+// sync clients. A client that stays away longer has to resync fully.
+const tombstoneRetention = 30 * 24 * time.Hour
+
 type InMemoryTaskRepository struct {
-    tasks     map[int]domain.Task  
-    currentID int                 
-    mu        sync.RWMutex         
+    tasks       map[int]domain.Task
+    tombstones  map[int]domain.Tombstone
+    revocations []domain.Revocation
+    currentID   int
+    revision    int64
+    // horizon is the newest revision of a dropped tombstone or revocation;
+    // sync tokens before it are refused.
+    horizon   int64
+    retention time.Duration
+    prunedAt  time.Time
+    index      *search.Index
+    broker     *events.Broker
+    history    map[int][]domain.TaskEvent
+    inTx       bool
+    pending    []domain.TaskEvent
+    mu         sync.RWMutex
 }
 
-func NewInMemoryTaskRepository() *InMemoryTaskRepository {
-    repo := &InMemoryTaskRepository{
-        tasks:     make(map[int]domain.Task),
-        currentID: 1,
+// NewEmptyInMemoryTaskRepository returns a repository without the demo
+// tasks, as used for every tenant.
+func NewEmptyInMemoryTaskRepository() *InMemoryTaskRepository {
+    return &InMemoryTaskRepository{
+        tasks:      make(map[int]domain.Task),
+        tombstones: make(map[int]domain.Tombstone),
+        currentID:  1,
+        retention:  tombstoneRetention,
+        index:      search.NewIndex(),
+        broker:     events.NewBroker(),
+        history:    make(map[int][]domain.TaskEvent),
     }
+}
+
+func NewInMemoryTaskRepository() *InMemoryTaskRepository {
+    repo := NewEmptyInMemoryTaskRepository()
     
+    now := time.Now()
     repo.tasks[1] = domain.Task{
         ID:        1,
         Title:     "Выучить основы Go",
         Completed: false,
-        CreatedAt: time.Now(),
+        Tags:      []string{},
+        CreatedAt: now,
+        UpdatedAt: now,
+        Revision:  1,
     }
     repo.tasks[2] = domain.Task{
         ID:        2,
         Title:     "Написать первое API",
         Completed: true,
-        CreatedAt: time.Now(),
+        Tags:      []string{},
+        CreatedAt: now,
+        UpdatedAt: now,
+        Revision:  2,
     }
     repo.currentID = 3 
+    repo.revision = 2
+    
+    for id := 1; id < repo.currentID; id++ {
+        task := repo.tasks[id]
+        repo.apply(domain.TaskEvent{
+            Type:     domain.TaskEventCreated,
+            TaskID:   id,
+            Revision: task.Revision,
+            Task:     &task,
+            At:       now,
+        })
+    }
     
     return repo
 }
@@ -54,6 +128,26 @@ func (r *InMemoryTaskRepository) GetAll() ([]domain.Task, error) {
         taskList = append(taskList, task)
     }
     
+    sort.Slice(taskList, func(i, j int) bool {
+        return taskList[i].ID < taskList[j].ID
+    })
+    
+    return taskList, nil
+}
+
+func (r *InMemoryTaskRepository) List(filter domain.TaskFilter) ([]domain.Task, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    taskList := make([]domain.Task, 0)
+    for _, task := range r.tasks {
+        if filter.Matches(task) {
+            taskList = append(taskList, task)
+        }
+    }
+    
+    filter.SortTasks(taskList)
+    
     return taskList, nil
 }
 
@@ -63,7 +157,7 @@ func (r *InMemoryTaskRepository) GetByID(id int) (*domain.Task, error) {
     
     task, exists := r.tasks[id]
     if !exists {
-        return nil, fmt.Errorf("task with id %d not found", id)
+        return nil, fmt.Errorf("task with id %d %w", id, domain.ErrNotFound)
     }
     
     return &task, nil
@@ -73,14 +167,38 @@ func (r *InMemoryTaskRepository) Create(task *domain.Task) error {
     r.mu.Lock()         
     defer r.mu.Unlock()
     
+    r.insert(task)
+    return nil
+}
+
+func (r *InMemoryTaskRepository) CreateIf(task *domain.Task, check func(stored iter.Seq[domain.Task]) error) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    if err := check(maps.Values(r.tasks)); err != nil {
+        return err
+    }
+    
+    r.insert(task)
+    return nil
+}
+
+// insert stores task under the next ID. The caller holds the lock.
+func (r *InMemoryTaskRepository) insert(task *domain.Task) {
+    r.revision++
+    now := time.Now()
     task.ID = r.currentID
-    task.CreatedAt = time.Now()
+    if task.CreatedAt.IsZero() {
+        task.CreatedAt = now
+    }
+    task.UpdatedAt = now
+    task.Revision = r.revision
+    stampCompletion(task, now)
     
     r.tasks[r.currentID] = *task
+    r.record(domain.TaskEventCreated, *task)
     
     r.currentID++
-    
-    return nil
 }
 
 func (r *InMemoryTaskRepository) Update(id int, updatedTask *domain.Task) error {
@@ -90,10 +208,16 @@ func (r *InMemoryTaskRepository) Update(id int, updatedTask *domain.Task) error
     
     _, exists := r.tasks[id]
     if !exists {
-        return fmt.Errorf("task with id %d not found", id)
+        return fmt.Errorf("task with id %d %w", id, domain.ErrNotFound)
     }
     
+    r.revision++
+    updatedTask.UpdatedAt = time.Now()
+    updatedTask.Revision = r.revision
+    stampCompletion(updatedTask, updatedTask.UpdatedAt)
+    
     r.tasks[id] = *updatedTask
+    r.record(domain.TaskEventUpdated, *updatedTask)
     
     return nil
 }
@@ -102,12 +226,263 @@ func (r *InMemoryTaskRepository) Delete(id int) error {
     r.mu.Lock()
     defer r.mu.Unlock()
     
-    _, exists := r.tasks[id]
+    task, exists := r.tasks[id]
     if !exists {
-        return fmt.Errorf("task with id %d not found", id)
+        return fmt.Errorf("task with id %d %w", id, domain.ErrNotFound)
     }
     
     delete(r.tasks, id)
     
+    r.revision++
+    r.tombstones[id] = domain.Tombstone{
+        ID:        id,
+        Revision:  r.revision,
+        DeletedAt: time.Now(),
+        OwnerID:   task.OwnerID,
+    }
+    
+    task.Revision = r.revision
+    r.record(domain.TaskEventDeleted, task)
+    r.prune(time.Now())
+    
+    return nil
+}
+
+func (r *InMemoryTaskRepository) Revoke(share domain.Share) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    r.revision++
+    now := time.Now()
+    r.revocations = append(r.revocations, domain.Revocation{
+        Share:     share,
+        Revision:  r.revision,
+        RevokedAt: now,
+    })
+    r.prune(now)
+    
     return nil
+}
+
+func (r *InMemoryTaskRepository) RevocationsSince(revision int64) ([]domain.Revocation, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    // Revocations are appended in revision order.
+    first := sort.Search(len(r.revocations), func(i int) bool {
+        return r.revocations[i].Revision > revision
+    })
+    return append([]domain.Revocation(nil), r.revocations[first:]...), nil
+}
+
+// prune drops the tombstones and revocations that are past the retention
+// period. It runs at most a few times per period, as it walks all of them.
+// Callers hold r.mu.
+func (r *InMemoryTaskRepository) prune(now time.Time) {
+    if now.Sub(r.prunedAt) < r.retention/100 {
+        return
+    }
+    r.prunedAt = now
+    cutoff := now.Add(-r.retention)
+    
+    for id, tombstone := range r.tombstones {
+        if tombstone.DeletedAt.Before(cutoff) {
+            delete(r.tombstones, id)
+            r.horizon = max(r.horizon, tombstone.Revision)
+        }
+    }
+    
+    kept := 0
+    for kept < len(r.revocations) && r.revocations[kept].RevokedAt.Before(cutoff) {
+        r.horizon = max(r.horizon, r.revocations[kept].Revision)
+        kept++
+    }
+    r.revocations = r.revocations[kept:]
+}
+
+func (r *InMemoryTaskRepository) ChangesSince(revision int64) ([]domain.Task, []domain.Tombstone, int64, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    if revision > r.revision {
+        return nil, nil, 0, fmt.Errorf("revision %d is ahead of current revision %d", revision, r.revision)
+    }
+    // Without a token the client starts from scratch and misses nothing.
+    if revision > 0 && revision < r.horizon {
+        return nil, nil, 0, fmt.Errorf("revision %d: %w", revision, domain.ErrSyncTokenExpired)
+    }
+    
+    changed := make([]domain.Task, 0)
+    for _, task := range r.tasks {
+        if task.Revision > revision {
+            changed = append(changed, task)
+        }
+    }
+    sort.Slice(changed, func(i, j int) bool {
+        return changed[i].Revision < changed[j].Revision
+    })
+    
+    deleted := make([]domain.Tombstone, 0)
+    for _, tombstone := range r.tombstones {
+        if tombstone.Revision > revision {
+            deleted = append(deleted, tombstone)
+        }
+    }
+    sort.Slice(deleted, func(i, j int) bool {
+        return deleted[i].Revision < deleted[j].Revision
+    })
+    
+    return changed, deleted, r.revision, nil
+}
+
+func (r *InMemoryTaskRepository) CurrentRevision() (int64, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    return r.revision, nil
+}
+
+func (r *InMemoryTaskRepository) WithinTransaction(fn func(tx TaskRepository) error) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    tx := &InMemoryTaskRepository{
+        tasks:       make(map[int]domain.Task, len(r.tasks)),
+        tombstones:  make(map[int]domain.Tombstone, len(r.tombstones)),
+        revocations: r.revocations[:len(r.revocations):len(r.revocations)],
+        currentID:   r.currentID,
+        revision:    r.revision,
+        horizon:     r.horizon,
+        retention:   r.retention,
+        prunedAt:    r.prunedAt,
+        inTx:        true,
+    }
+    for id, task := range r.tasks {
+        tx.tasks[id] = task
+    }
+    for id, tombstone := range r.tombstones {
+        tx.tombstones[id] = tombstone
+    }
+    
+    if err := fn(tx); err != nil {
+        return err
+    }
+    
+    r.tasks = tx.tasks
+    r.tombstones = tx.tombstones
+    r.revocations = tx.revocations
+    r.currentID = tx.currentID
+    r.revision = tx.revision
+    r.horizon = tx.horizon
+    r.prunedAt = tx.prunedAt
+    
+    // A nested transaction hands its events to the enclosing one, which
+    // applies them when it commits.
+    if r.inTx {
+        r.pending = append(r.pending, tx.pending...)
+        return nil
+    }
+    for _, event := range tx.pending {
+        r.apply(event)
+    }
+    
+    return nil
+}
+
+func (r *InMemoryTaskRepository) Search(query string, limit int, visible func(domain.Task) bool) ([]domain.SearchResult, error) {
+    if r.inTx {
+        return nil, fmt.Errorf("search is not available inside a transaction")
+    }
+    
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    var include func(id int) bool
+    if visible != nil {
+        include = func(id int) bool {
+            task, exists := r.tasks[id]
+            return exists && visible(task)
+        }
+    }
+    hits := r.index.Search(query, 0, include)
+    
+    results := make([]domain.SearchResult, 0, len(hits))
+    for _, hit := range hits {
+        task, exists := r.tasks[hit.ID]
+        if !exists {
+            continue
+        }
+        results = append(results, domain.SearchResult{
+            Task:    task,
+            Score:   hit.Score,
+            Snippet: hit.Snippet,
+        })
+        if limit > 0 && len(results) == limit {
+            break
+        }
+    }
+    
+    return results, nil
+}
+
+func (r *InMemoryTaskRepository) History(id int) ([]domain.TaskEvent, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    history, exists := r.history[id]
+    if !exists {
+        return nil, fmt.Errorf("task with id %d %w", id, domain.ErrNotFound)
+    }
+    
+    return append([]domain.TaskEvent(nil), history...), nil
+}
+
+func (r *InMemoryTaskRepository) Watch(ctx context.Context) (<-chan domain.TaskEvent, error) {
+    if r.inTx {
+        return nil, fmt.Errorf("watch is not available inside a transaction")
+    }
+    
+    return r.broker.Subscribe(ctx), nil
+}
+
+// stampCompletion sets CompletedAt when a task is completed without one and
+// clears it when the task is open.
+func stampCompletion(task *domain.Task, now time.Time) {
+    if !task.Completed {
+        task.CompletedAt = nil
+    } else if task.CompletedAt == nil {
+        task.CompletedAt = &now
+    }
+}
+
+func (r *InMemoryTaskRepository) record(eventType string, task domain.Task) {
+    event := domain.TaskEvent{
+        Type:     eventType,
+        TaskID:   task.ID,
+        Revision: task.Revision,
+        Task:     &task,
+        At:       time.Now(),
+    }
+    
+    if r.inTx {
+        r.pending = append(r.pending, event)
+        return
+    }
+    r.apply(event)
+}
+
+func (r *InMemoryTaskRepository) apply(event domain.TaskEvent) {
+    if event.Type == domain.TaskEventDeleted {
+        r.index.Remove(event.TaskID)
+    } else {
+        r.index.Add(event.TaskID, event.Task.Title)
+    }
+    
+    history := append(r.history[event.TaskID], event)
+    if len(history) > maxHistoryPerTask {
+        history = history[len(history)-maxHistoryPerTask:]
+    }
+    r.history[event.TaskID] = history
+    
+    r.broker.Publish(event)
 }
\ No newline at end of file
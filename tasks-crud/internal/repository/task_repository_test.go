@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"errors"
+	"iter"
+	"testing"
+	"time"
+
+	"tasks-crud/internal/domain"
+)
+
+func TestChangesSinceRefusesTokensOlderThanKeptTombstones(t *testing.T) {
+    repo := NewEmptyInMemoryTaskRepository()
+    for _, title := range []string{"first", "second", "third"} {
+        if err := repo.Create(&domain.Task{Title: title}); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if err := repo.Delete(1); err != nil {
+        t.Fatal(err)
+    }
+    token, _ := repo.CurrentRevision()
+    
+    // The first tombstone ages out, and the next deletion prunes.
+    repo.tombstones[1] = domain.Tombstone{ID: 1, Revision: repo.tombstones[1].Revision, DeletedAt: time.Now().Add(-2 * tombstoneRetention)}
+    repo.prunedAt = time.Time{}
+    if err := repo.Delete(2); err != nil {
+        t.Fatal(err)
+    }
+    
+    if _, ok := repo.tombstones[1]; ok {
+        t.Fatal("the expired tombstone was kept")
+    }
+    if _, _, _, err := repo.ChangesSince(token - 1); !errors.Is(err, domain.ErrSyncTokenExpired) {
+        t.Fatalf("token before the dropped tombstone: got %v, want ErrSyncTokenExpired", err)
+    }
+    _, deleted, _, err := repo.ChangesSince(token)
+    if err != nil || len(deleted) != 1 || deleted[0].ID != 2 {
+        t.Fatalf("token after the dropped tombstone: got %v, %v; want the later deletion", deleted, err)
+    }
+    if _, _, _, err := repo.ChangesSince(0); err != nil {
+        t.Fatalf("a full sync was refused: %v", err)
+    }
+}
+
+// A former grantee's client must learn that a task it synced is gone once
+// the share is revoked, without learning about anything else.
+func TestChangesSinceReportsRevokedShares(t *testing.T) {
+    repo := NewEmptyInMemoryTaskRepository()
+    shares := NewInMemoryShareRepository()
+    alice := NewOwnedTaskRepository(repo, "alice", shares)
+    bob := NewOwnedTaskRepository(repo, "bob", shares)
+    
+    for _, task := range []*domain.Task{
+        {Title: "shared alone", Tags: []string{}},
+        {Title: "in project", Tags: []string{"trip"}},
+        {Title: "never shared", Tags: []string{}},
+    } {
+        if err := alice.Create(task); err != nil {
+            t.Fatal(err)
+        }
+    }
+    one := 1
+    byTask := &domain.Share{OwnerID: "alice", GranteeID: "bob", TaskID: &one, Role: domain.RoleViewer}
+    byProject := &domain.Share{OwnerID: "alice", GranteeID: "bob", Project: "trip", Role: domain.RoleViewer}
+    for _, share := range []*domain.Share{byTask, byProject} {
+        if err := shares.Save(share); err != nil {
+            t.Fatal(err)
+        }
+    }
+    
+    visible, _, token, err := bob.ChangesSince(0)
+    if err != nil || len(visible) != 2 {
+        t.Fatalf("bob synced %v, %v; want the two shared tasks", visible, err)
+    }
+    
+    for _, share := range []*domain.Share{byTask, byProject} {
+        if err := shares.Delete(share.ID); err != nil {
+            t.Fatal(err)
+        }
+        if err := alice.Revoke(*share); err != nil {
+            t.Fatal(err)
+        }
+    }
+    
+    changed, deleted, _, err := bob.ChangesSince(token)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(changed) != 0 || len(deleted) != 2 || deleted[0].ID != 1 || deleted[1].ID != 2 || !deleted[0].Revoked {
+        t.Fatalf("got changed %v, deleted %v; want revoked tombstones for tasks 1 and 2", changed, deleted)
+    }
+    if _, deleted, _, _ := alice.ChangesSince(token); len(deleted) != 0 {
+        t.Fatalf("the owner got tombstones for tasks she still has: %v", deleted)
+    }
+}
+
+func TestCreateIfChecksEveryTaskOfTheStore(t *testing.T) {
+    repo := NewEmptyInMemoryTaskRepository()
+    if err := repo.Create(&domain.Task{Title: "alice's", OwnerID: "alice"}); err != nil {
+        t.Fatal(err)
+    }
+    bob := NewOwnedTaskRepository(repo, "bob", nil)
+    
+    full := errors.New("full")
+    var seen int
+    err := bob.CreateIf(&domain.Task{Title: "bob's"}, func(stored iter.Seq[domain.Task]) error {
+        for range stored {
+            seen++
+        }
+        return full
+    })
+    if err != full || seen != 1 {
+        t.Fatalf("got %v after seeing %d tasks, want the check's error after seeing alice's task", err, seen)
+    }
+    if tasks, _ := repo.GetAll(); len(tasks) != 1 {
+        t.Fatalf("a refused task was stored: %+v", tasks)
+    }
+    
+    if err := bob.CreateIf(&domain.Task{Title: "bob's"}, func(iter.Seq[domain.Task]) error { return nil }); err != nil {
+        t.Fatal(err)
+    }
+    if tasks, _ := bob.GetAll(); len(tasks) != 1 || tasks[0].OwnerID != "bob" {
+        t.Fatalf("bob sees %+v, want his new task", tasks)
+    }
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"tasks-crud/internal/domain"
+)
+
+// TenantData holds the stores of one tenant.
+type TenantData struct {
+    Tasks   TaskRepository
+    Shares  ShareRepository
+    Filters SavedFilterRepository
+    Feeds   CalendarFeedRepository
+}
+
+func NewTenantData() *TenantData {
+    return &TenantData{
+        Tasks:   NewEmptyInMemoryTaskRepository(),
+        Shares:  NewInMemoryShareRepository(),
+        Filters: NewInMemorySavedFilterRepository(),
+        Feeds:   NewInMemoryCalendarFeedRepository(),
+    }
+}
+
+type tenantDataContextKey struct{}
+
+// WithTenantData stores the resolved tenant's data in ctx, where the
+// services' WithContext methods pick it up.
+func WithTenantData(ctx context.Context, data *TenantData) context.Context {
+    return context.WithValue(ctx, tenantDataContextKey{}, data)
+}
+
+func TenantDataFromContext(ctx context.Context) *TenantData {
+    data, _ := ctx.Value(tenantDataContextKey{}).(*TenantData)
+    return data
+}
+
+type TenantRepository interface {
+    GetAll() ([]domain.Tenant, error)
+    GetByID(id string) (*domain.Tenant, error)
+    // Data returns the stores of a tenant.
+    Data(id string) (*TenantData, error)
+    Create(tenant *domain.Tenant) error
+    Update(id string, tenant *domain.Tenant) error
+    // Delete removes a tenant together with its stores.
+    Delete(id string) error
+}
+
+type InMemoryTenantRepository struct {
+    tenants map[string]domain.Tenant
+    data    map[string]*TenantData
+    mu      sync.RWMutex
+}
+
+func NewInMemoryTenantRepository() *InMemoryTenantRepository {
+    return &InMemoryTenantRepository{
+        tenants: make(map[string]domain.Tenant),
+        data:    make(map[string]*TenantData),
+    }
+}
+
+func (r *InMemoryTenantRepository) GetAll() ([]domain.Tenant, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    tenantList := make([]domain.Tenant, 0, len(r.tenants))
+    for _, tenant := range r.tenants {
+        tenantList = append(tenantList, tenant)
+    }
+    sort.Slice(tenantList, func(i, j int) bool {
+        return tenantList[i].ID < tenantList[j].ID
+    })
+    
+    return tenantList, nil
+}
+
+func (r *InMemoryTenantRepository) GetByID(id string) (*domain.Tenant, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    tenant, exists := r.tenants[id]
+    if !exists {
+        return nil, fmt.Errorf("tenant '%s' %w", id, domain.ErrNotFound)
+    }
+    
+    return &tenant, nil
+}
+
+func (r *InMemoryTenantRepository) Data(id string) (*TenantData, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    data, exists := r.data[id]
+    if !exists {
+        return nil, fmt.Errorf("tenant '%s' %w", id, domain.ErrNotFound)
+    }
+    
+    return data, nil
+}
+
+func (r *InMemoryTenantRepository) Create(tenant *domain.Tenant) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    if _, exists := r.tenants[tenant.ID]; exists {
+        return fmt.Errorf("tenant '%s' %w", tenant.ID, domain.ErrAlreadyExists)
+    }
+    
+    tenant.CreatedAt = time.Now()
+    r.tenants[tenant.ID] = *tenant
+    r.data[tenant.ID] = NewTenantData()
+    
+    return nil
+}
+
+func (r *InMemoryTenantRepository) Update(id string, tenant *domain.Tenant) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    existing, exists := r.tenants[id]
+    if !exists {
+        return fmt.Errorf("tenant '%s' %w", id, domain.ErrNotFound)
+    }
+    
+    tenant.ID = id
+    tenant.CreatedAt = existing.CreatedAt
+    r.tenants[id] = *tenant
+    
+    return nil
+}
+
+func (r *InMemoryTenantRepository) Delete(id string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    if _, exists := r.tenants[id]; !exists {
+        return fmt.Errorf("tenant '%s' %w", id, domain.ErrNotFound)
+    }
+    
+    delete(r.tenants, id)
+    delete(r.data, id)
+    
+    return nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"tasks-crud/internal/domain"
+)
+
+type UserRepository interface {
+    GetByID(id int) (*domain.User, error)
+    GetByUsername(tenantID, username string) (*domain.User, error)
+    // GetByExternalID finds the account of a single sign-on user.
+    GetByExternalID(tenantID, provider, externalID string) (*domain.User, error)
+    Create(user *domain.User) error
+    Update(id int, user *domain.User) error
+    // DeleteByTenant removes every user of a tenant and returns them.
+    DeleteByTenant(tenantID string) ([]domain.User, error)
+}
+
+type InMemoryUserRepository struct {
+    users     map[int]domain.User
+    currentID int
+    mu        sync.RWMutex
+}
+
+func NewInMemoryUserRepository() *InMemoryUserRepository {
+    return &InMemoryUserRepository{
+        users:     make(map[int]domain.User),
+        currentID: 1,
+    }
+}
+
+func (r *InMemoryUserRepository) GetByID(id int) (*domain.User, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    user, exists := r.users[id]
+    if !exists {
+        return nil, fmt.Errorf("user with id %d %w", id, domain.ErrNotFound)
+    }
+    
+    return &user, nil
+}
+
+func (r *InMemoryUserRepository) GetByUsername(tenantID, username string) (*domain.User, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    for _, user := range r.users {
+        if user.TenantID == tenantID && user.Username == username {
+            return &user, nil
+        }
+    }
+    
+    return nil, fmt.Errorf("user '%s' %w", username, domain.ErrNotFound)
+}
+
+func (r *InMemoryUserRepository) GetByExternalID(tenantID, provider, externalID string) (*domain.User, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    
+    for _, user := range r.users {
+        if user.TenantID == tenantID && user.Provider == provider && user.ExternalID == externalID {
+            return &user, nil
+        }
+    }
+    
+    return nil, fmt.Errorf("user '%s' of %s %w", externalID, provider, domain.ErrNotFound)
+}
+
+// Create stores user, failing if the username is taken in its tenant. The
+// check and the insert happen under one lock so concurrent registrations
+// can't both win.
+func (r *InMemoryUserRepository) Create(user *domain.User) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    for _, existing := range r.users {
+        if existing.TenantID == user.TenantID && existing.Username == user.Username {
+            return fmt.Errorf("user '%s' %w", user.Username, domain.ErrAlreadyExists)
+        }
+    }
+    
+    user.ID = r.currentID
+    user.CreatedAt = time.Now()
+    user.UpdatedAt = user.CreatedAt
+    
+    r.users[r.currentID] = *user
+    r.currentID++
+    
+    return nil
+}
+
+func (r *InMemoryUserRepository) Update(id int, user *domain.User) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    existing, exists := r.users[id]
+    if !exists {
+        return fmt.Errorf("user with id %d %w", id, domain.ErrNotFound)
+    }
+    
+    user.ID = id
+    user.CreatedAt = existing.CreatedAt
+    user.UpdatedAt = time.Now()
+    r.users[id] = *user
+    
+    return nil
+}
+
+func (r *InMemoryUserRepository) DeleteByTenant(tenantID string) ([]domain.User, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    var deleted []domain.User
+    for id, user := range r.users {
+        if user.TenantID == tenantID {
+            deleted = append(deleted, user)
+            delete(r.users, id)
+        }
+    }
+    
+    return deleted, nil
+}
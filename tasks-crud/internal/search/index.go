@@ -0,0 +1,304 @@
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+    bm25K1         = 1.2
+    bm25B          = 0.75
+    prefixWeight   = 0.5
+    snippetRadius  = 100
+    highlightOpen  = "<mark>"
+    highlightClose = "</mark>"
+)
+
+type Hit struct {
+    ID      int
+    Score   float64
+    Snippet string
+}
+
+type document struct {
+    text   string
+    tokens []Token
+    stems  []string
+}
+
+// Index is an inverted index over short texts. terms, the sorted keys of
+// postings used for prefix matching, is kept up to date by the writers so
+// that searches only need the read lock.
+type Index struct {
+    docs        map[int]*document
+    postings    map[string]map[int]int
+    terms       []string
+    totalLength int
+    mu          sync.RWMutex
+}
+
+func NewIndex() *Index {
+    return &Index{
+        docs:     make(map[int]*document),
+        postings: make(map[string]map[int]int),
+    }
+}
+
+func (idx *Index) Add(id int, text string) {
+    idx.mu.Lock()
+    defer idx.mu.Unlock()
+    
+    idx.remove(id)
+    
+    tokens := Tokenize(text)
+    doc := &document{
+        text:   text,
+        tokens: tokens,
+        stems:  make([]string, len(tokens)),
+    }
+    for i, token := range tokens {
+        stem := Stem(token.Term)
+        doc.stems[i] = stem
+        
+        docs, exists := idx.postings[stem]
+        if !exists {
+            docs = make(map[int]int)
+            idx.postings[stem] = docs
+            idx.insertTerm(stem)
+        }
+        docs[id]++
+    }
+    
+    idx.docs[id] = doc
+    idx.totalLength += len(tokens)
+}
+
+func (idx *Index) Remove(id int) {
+    idx.mu.Lock()
+    defer idx.mu.Unlock()
+    
+    idx.remove(id)
+}
+
+func (idx *Index) remove(id int) {
+    doc, exists := idx.docs[id]
+    if !exists {
+        return
+    }
+    
+    for _, stem := range doc.stems {
+        docs := idx.postings[stem]
+        delete(docs, id)
+        if len(docs) == 0 && idx.postings[stem] != nil {
+            delete(idx.postings, stem)
+            idx.deleteTerm(stem)
+        }
+    }
+    
+    idx.totalLength -= len(doc.tokens)
+    delete(idx.docs, id)
+}
+
+// Search ranks the documents matching every word of query with BM25. When
+// include is set, only the documents it accepts are searched, and the
+// collection statistics (document count, average length and document
+// frequencies) are computed over them alone, so scores reveal nothing
+// about the rest of the index.
+func (idx *Index) Search(query string, limit int, include func(id int) bool) []Hit {
+    queryTokens := Tokenize(query)
+    if len(queryTokens) == 0 {
+        return []Hit{}
+    }
+    
+    idx.mu.RLock()
+    defer idx.mu.RUnlock()
+    
+    count, totalLength := len(idx.docs), idx.totalLength
+    if include != nil {
+        count, totalLength = 0, 0
+        for id, doc := range idx.docs {
+            if include(id) {
+                count++
+                totalLength += len(doc.tokens)
+            }
+        }
+    }
+    
+    avgLength := 1.0
+    if count > 0 {
+        avgLength = float64(totalLength) / float64(count)
+    }
+    
+    var scores map[int]float64
+    matched := make(map[string]bool)
+    for _, token := range queryTokens {
+        termScores := make(map[int]float64)
+        for term, weight := range idx.expand(token.Term) {
+            docs := idx.postings[term]
+            if include != nil {
+                docs = filterPostings(docs, include)
+                if len(docs) == 0 {
+                    continue
+                }
+            }
+            matched[term] = true
+            idf := math.Log(1 + (float64(count)-float64(len(docs))+0.5)/(float64(len(docs))+0.5))
+            for id, freq := range docs {
+                length := float64(len(idx.docs[id].tokens))
+                tf := float64(freq) * (bm25K1 + 1) / (float64(freq) + bm25K1*(1-bm25B+bm25B*length/avgLength))
+                if score := weight * idf * tf; score > termScores[id] {
+                    termScores[id] = score
+                }
+            }
+        }
+        
+        if scores == nil {
+            scores = termScores
+            continue
+        }
+        for id := range scores {
+            if termScore, ok := termScores[id]; ok {
+                scores[id] += termScore
+            } else {
+                delete(scores, id)
+            }
+        }
+    }
+    
+    hits := make([]Hit, 0, len(scores))
+    for id, score := range scores {
+        hits = append(hits, Hit{ID: id, Score: score})
+    }
+    sort.Slice(hits, func(i, j int) bool {
+        if hits[i].Score != hits[j].Score {
+            return hits[i].Score > hits[j].Score
+        }
+        return hits[i].ID < hits[j].ID
+    })
+    
+    if limit > 0 && len(hits) > limit {
+        hits = hits[:limit]
+    }
+    for i := range hits {
+        hits[i].Snippet = idx.docs[hits[i].ID].snippet(matched)
+    }
+    
+    return hits
+}
+
+func (idx *Index) expand(term string) map[string]float64 {
+    expanded := make(map[string]float64)
+    
+    stem := Stem(term)
+    if _, exists := idx.postings[stem]; exists {
+        expanded[stem] = 1
+    }
+    
+    for _, prefix := range []string{term, stem} {
+        start := sort.SearchStrings(idx.terms, prefix)
+        for i := start; i < len(idx.terms) && strings.HasPrefix(idx.terms[i], prefix); i++ {
+            if _, exists := expanded[idx.terms[i]]; !exists {
+                expanded[idx.terms[i]] = prefixWeight
+            }
+        }
+    }
+    
+    return expanded
+}
+
+func filterPostings(docs map[int]int, include func(id int) bool) map[int]int {
+    filtered := make(map[int]int)
+    for id, freq := range docs {
+        if include(id) {
+            filtered[id] = freq
+        }
+    }
+    return filtered
+}
+
+func (idx *Index) insertTerm(term string) {
+    i := sort.SearchStrings(idx.terms, term)
+    idx.terms = append(idx.terms, "")
+    copy(idx.terms[i+1:], idx.terms[i:])
+    idx.terms[i] = term
+}
+
+func (idx *Index) deleteTerm(term string) {
+    i := sort.SearchStrings(idx.terms, term)
+    if i < len(idx.terms) && idx.terms[i] == term {
+        idx.terms = append(idx.terms[:i], idx.terms[i+1:]...)
+    }
+}
+
+func (doc *document) snippet(matched map[string]bool) string {
+    first, last := -1, -1
+    for i, stem := range doc.stems {
+        if matched[stem] {
+            if first < 0 {
+                first = i
+            }
+            last = i
+        }
+    }
+    
+    start, end := 0, len(doc.text)
+    if first >= 0 {
+        if doc.tokens[first].Start > snippetRadius {
+            start = wordBoundaryAfter(doc.text, doc.tokens[first].Start-snippetRadius)
+        }
+        if len(doc.text)-doc.tokens[last].End > snippetRadius {
+            end = wordBoundaryBefore(doc.text, doc.tokens[last].End+snippetRadius)
+        }
+    } else if len(doc.text) > 2*snippetRadius {
+        end = wordBoundaryBefore(doc.text, 2*snippetRadius)
+    }
+    
+    var b strings.Builder
+    if start > 0 {
+        b.WriteString("…")
+    }
+    
+    pos := start
+    for i, token := range doc.tokens {
+        if token.Start < start || token.End > end || !matched[doc.stems[i]] {
+            continue
+        }
+        b.WriteString(html.EscapeString(doc.text[pos:token.Start]))
+        b.WriteString(highlightOpen)
+        b.WriteString(html.EscapeString(doc.text[token.Start:token.End]))
+        b.WriteString(highlightClose)
+        pos = token.End
+    }
+    b.WriteString(html.EscapeString(doc.text[pos:end]))
+    
+    if end < len(doc.text) {
+        b.WriteString("…")
+    }
+    
+    return b.String()
+}
+
+func wordBoundaryAfter(text string, pos int) int {
+    if i := strings.IndexByte(text[pos:], ' '); i >= 0 {
+        return pos + i + 1
+    }
+    return runeStart(text, pos)
+}
+
+func wordBoundaryBefore(text string, pos int) int {
+    if i := strings.LastIndexByte(text[:pos], ' '); i > 0 {
+        return i
+    }
+    return runeStart(text, pos)
+}
+
+func runeStart(text string, pos int) int {
+    for pos > 0 && pos < len(text) && !utf8.RuneStart(text[pos]) {
+        pos--
+    }
+    return pos
+}
\ No newline at end of file
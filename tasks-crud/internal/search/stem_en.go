@@ -0,0 +1,244 @@
+package search
+
+import "strings"
+
+type porterStemmer struct {
+    b []byte
+    k int
+    j int
+}
+
+func stemEnglish(word string) string {
+    for i := 0; i < len(word); i++ {
+        if word[i] < 'a' || word[i] > 'z' {
+            return word
+        }
+    }
+    
+    z := &porterStemmer{b: []byte(word), k: len(word) - 1}
+    z.step1ab()
+    if z.k > 0 {
+        z.step1c()
+        z.step2()
+        z.step3()
+        z.step4()
+        z.step5()
+    }
+    
+    return string(z.b[:z.k+1])
+}
+
+func (z *porterStemmer) cons(i int) bool {
+    switch z.b[i] {
+    case 'a', 'e', 'i', 'o', 'u':
+        return false
+    case 'y':
+        if i == 0 {
+            return true
+        }
+        return !z.cons(i - 1)
+    }
+    return true
+}
+
+func (z *porterStemmer) m() int {
+    n := 0
+    i := 0
+    for {
+        if i > z.j {
+            return n
+        }
+        if !z.cons(i) {
+            break
+        }
+        i++
+    }
+    i++
+    for {
+        for {
+            if i > z.j {
+                return n
+            }
+            if z.cons(i) {
+                break
+            }
+            i++
+        }
+        i++
+        n++
+        for {
+            if i > z.j {
+                return n
+            }
+            if !z.cons(i) {
+                break
+            }
+            i++
+        }
+        i++
+    }
+}
+
+func (z *porterStemmer) vowelInStem() bool {
+    for i := 0; i <= z.j; i++ {
+        if !z.cons(i) {
+            return true
+        }
+    }
+    return false
+}
+
+func (z *porterStemmer) doublec(j int) bool {
+    if j < 1 || z.b[j] != z.b[j-1] {
+        return false
+    }
+    return z.cons(j)
+}
+
+func (z *porterStemmer) cvc(i int) bool {
+    if i < 2 || !z.cons(i) || z.cons(i-1) || !z.cons(i-2) {
+        return false
+    }
+    switch z.b[i] {
+    case 'w', 'x', 'y':
+        return false
+    }
+    return true
+}
+
+func (z *porterStemmer) ends(s string) bool {
+    length := len(s)
+    if length > z.k+1 {
+        return false
+    }
+    if !strings.HasSuffix(string(z.b[:z.k+1]), s) {
+        return false
+    }
+    z.j = z.k - length
+    return true
+}
+
+func (z *porterStemmer) setto(s string) {
+    z.b = append(z.b[:z.j+1], s...)
+    z.k = z.j + len(s)
+}
+
+func (z *porterStemmer) r(s string) {
+    if z.m() > 0 {
+        z.setto(s)
+    }
+}
+
+func (z *porterStemmer) step1ab() {
+    if z.b[z.k] == 's' {
+        switch {
+        case z.ends("sses"):
+            z.k -= 2
+        case z.ends("ies"):
+            z.setto("i")
+        case z.k > 0 && z.b[z.k-1] != 's':
+            z.k--
+        }
+    }
+    
+    if z.ends("eed") {
+        if z.m() > 0 {
+            z.k--
+        }
+        return
+    }
+    
+    if (z.ends("ed") || z.ends("ing")) && z.vowelInStem() {
+        z.k = z.j
+        switch {
+        case z.ends("at"):
+            z.setto("ate")
+        case z.ends("bl"):
+            z.setto("ble")
+        case z.ends("iz"):
+            z.setto("ize")
+        case z.doublec(z.k):
+            z.k--
+            switch z.b[z.k] {
+            case 'l', 's', 'z':
+                z.k++
+            }
+        default:
+            z.j = z.k
+            if z.m() == 1 && z.cvc(z.k) {
+                z.setto("e")
+            }
+        }
+    }
+}
+
+func (z *porterStemmer) step1c() {
+    if z.ends("y") && z.vowelInStem() {
+        z.b[z.k] = 'i'
+    }
+}
+
+var porterStep2 = [][2]string{
+    {"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+    {"izer", "ize"}, {"bli", "ble"}, {"alli", "al"}, {"entli", "ent"},
+    {"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+    {"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"},
+    {"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+    {"logi", "log"},
+}
+
+var porterStep3 = [][2]string{
+    {"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+    {"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+var porterStep4 = []string{
+    "al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement",
+    "ment", "ent", "ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+func (z *porterStemmer) step2() {
+    for _, rule := range porterStep2 {
+        if z.ends(rule[0]) {
+            z.r(rule[1])
+            return
+        }
+    }
+}
+
+func (z *porterStemmer) step3() {
+    for _, rule := range porterStep3 {
+        if z.ends(rule[0]) {
+            z.r(rule[1])
+            return
+        }
+    }
+}
+
+func (z *porterStemmer) step4() {
+    for _, suffix := range porterStep4 {
+        if !z.ends(suffix) {
+            continue
+        }
+        if suffix == "ion" && (z.j < 0 || (z.b[z.j] != 's' && z.b[z.j] != 't')) {
+            return
+        }
+        if z.m() > 1 {
+            z.k = z.j
+        }
+        return
+    }
+}
+
+func (z *porterStemmer) step5() {
+    z.j = z.k
+    if z.b[z.k] == 'e' {
+        a := z.m()
+        if a > 1 || a == 1 && !z.cvc(z.k-1) {
+            z.k--
+        }
+    }
+    if z.b[z.k] == 'l' && z.doublec(z.k) && z.m() > 1 {
+        z.k--
+    }
+}
\ No newline at end of file
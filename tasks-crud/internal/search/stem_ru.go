@@ -0,0 +1,132 @@
+package search
+
+import "strings"
+
+var (
+    ruPerfectiveGerund1 = []string{"вшись", "вши", "в"}
+    ruPerfectiveGerund2 = []string{"ившись", "ывшись", "ивши", "ывши", "ив", "ыв"}
+    ruAdjective         = []string{"ими", "ыми", "его", "ого", "ему", "ому", "ее", "ие", "ые", "ое", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею"}
+    ruParticiple1       = []string{"ем", "нн", "вш", "ющ", "щ"}
+    ruParticiple2       = []string{"ивш", "ывш", "ующ"}
+    ruReflexive         = []string{"ся", "сь"}
+    ruVerb1             = []string{"ете", "йте", "ешь", "нно", "ла", "на", "ли", "ем", "ло", "но", "ет", "ют", "ны", "ть", "й", "л", "н"}
+    ruVerb2             = []string{"ейте", "уйте", "ила", "ыла", "ена", "ите", "или", "ыли", "ило", "ыло", "ено", "ует", "уют", "ены", "ить", "ыть", "ишь", "ей", "уй", "ил", "ыл", "им", "ым", "ен", "ят", "ит", "ыт", "ую", "ю"}
+    ruNoun              = []string{"иями", "ями", "ами", "ией", "иям", "ием", "иях", "ев", "ов", "ие", "ье", "еи", "ии", "ей", "ой", "ий", "ям", "ем", "ам", "ом", "ах", "ях", "ию", "ью", "ия", "ья", "а", "е", "и", "й", "о", "у", "ы", "ь", "ю", "я"}
+    ruSuperlative       = []string{"ейше", "ейш"}
+    ruDerivational      = []string{"ость", "ост"}
+)
+
+func stemRussian(word string) string {
+    runes := []rune(word)
+    rv, r2 := russianRegions(runes)
+    if rv >= len(runes) {
+        return word
+    }
+    
+    stem := string(runes[:rv])
+    rest := string(runes[rv:])
+    
+    if r, ok := removeRussianEnding(rest, ruPerfectiveGerund1, true); ok {
+        rest = r
+    } else if r, ok := removeRussianEnding(rest, ruPerfectiveGerund2, false); ok {
+        rest = r
+    } else {
+        if r, ok := removeRussianEnding(rest, ruReflexive, false); ok {
+            rest = r
+        }
+        if r, ok := removeRussianAdjectival(rest); ok {
+            rest = r
+        } else if r, ok := removeRussianEnding(rest, ruVerb1, true); ok {
+            rest = r
+        } else if r, ok := removeRussianEnding(rest, ruVerb2, false); ok {
+            rest = r
+        } else if r, ok := removeRussianEnding(rest, ruNoun, false); ok {
+            rest = r
+        }
+    }
+    
+    rest = strings.TrimSuffix(rest, "и")
+    
+    r2InRest := r2 - rv
+    for _, ending := range ruDerivational {
+        if strings.HasSuffix(rest, ending) && len([]rune(rest))-len([]rune(ending)) >= r2InRest {
+            rest = strings.TrimSuffix(rest, ending)
+            break
+        }
+    }
+    
+    switch {
+    case strings.HasSuffix(rest, "нн"):
+        rest = strings.TrimSuffix(rest, "н")
+    default:
+        if r, ok := removeRussianEnding(rest, ruSuperlative, false); ok {
+            rest = r
+            if strings.HasSuffix(rest, "нн") {
+                rest = strings.TrimSuffix(rest, "н")
+            }
+        } else {
+            rest = strings.TrimSuffix(rest, "ь")
+        }
+    }
+    
+    return stem + rest
+}
+
+func removeRussianAdjectival(rest string) (string, bool) {
+    r, ok := removeRussianEnding(rest, ruAdjective, false)
+    if !ok {
+        return rest, false
+    }
+    
+    if p, ok := removeRussianEnding(r, ruParticiple1, true); ok {
+        return p, true
+    }
+    if p, ok := removeRussianEnding(r, ruParticiple2, false); ok {
+        return p, true
+    }
+    
+    return r, true
+}
+
+func removeRussianEnding(rest string, endings []string, afterAOrYa bool) (string, bool) {
+    for _, ending := range endings {
+        if !strings.HasSuffix(rest, ending) {
+            continue
+        }
+        trimmed := strings.TrimSuffix(rest, ending)
+        if afterAOrYa && !strings.HasSuffix(trimmed, "а") && !strings.HasSuffix(trimmed, "я") {
+            continue
+        }
+        return trimmed, true
+    }
+    
+    return rest, false
+}
+
+func russianRegions(runes []rune) (int, int) {
+    rv := len(runes)
+    for i, r := range runes {
+        if isRussianVowel(r) {
+            rv = i + 1
+            break
+        }
+    }
+    
+    r1 := regionAfterVowelConsonant(runes, 0)
+    r2 := regionAfterVowelConsonant(runes, r1)
+    
+    return rv, r2
+}
+
+func regionAfterVowelConsonant(runes []rune, from int) int {
+    for i := from + 1; i < len(runes); i++ {
+        if !isRussianVowel(runes[i]) && isRussianVowel(runes[i-1]) {
+            return i + 1
+        }
+    }
+    return len(runes)
+}
+
+func isRussianVowel(r rune) bool {
+    return strings.ContainsRune("аеиоуыэюя", r)
+}
\ No newline at end of file
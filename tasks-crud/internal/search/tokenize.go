@@ -0,0 +1,73 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Token struct {
+    Term  string
+    Start int
+    End   int
+}
+
+func Tokenize(text string) []Token {
+    tokens := make([]Token, 0)
+    start := -1
+    
+    for i, r := range text {
+        if unicode.IsLetter(r) || unicode.IsDigit(r) {
+            if start < 0 {
+                start = i
+            }
+            continue
+        }
+        if start >= 0 {
+            tokens = append(tokens, Token{Term: Normalize(text[start:i]), Start: start, End: i})
+            start = -1
+        }
+    }
+    if start >= 0 {
+        tokens = append(tokens, Token{Term: Normalize(text[start:]), Start: start, End: len(text)})
+    }
+    
+    return tokens
+}
+
+func Normalize(word string) string {
+    word = strings.ToLower(word)
+    return strings.Map(func(r rune) rune {
+        if r == 'ё' {
+            return 'е'
+        }
+        return r
+    }, word)
+}
+
+func Stem(word string) string {
+    if utf8.RuneCountInString(word) < 3 {
+        return word
+    }
+    
+    switch script(word) {
+    case unicode.Cyrillic:
+        return stemRussian(word)
+    case unicode.Latin:
+        return stemEnglish(word)
+    }
+    
+    return word
+}
+
+func script(word string) *unicode.RangeTable {
+    for _, r := range word {
+        switch {
+        case unicode.Is(unicode.Cyrillic, r):
+            return unicode.Cyrillic
+        case r < utf8.RuneSelf && unicode.IsLetter(r):
+            return unicode.Latin
+        }
+    }
+    return nil
+}
\ No newline at end of file
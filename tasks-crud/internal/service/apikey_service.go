@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/repository"
+)
+
+const (
+    // APIKeyPrefix starts every key, so keys are easy to tell apart from
+    // JWTs and to find with secret scanners.
+    APIKeyPrefix       = "tk_"
+    apiKeyBytes        = 32
+    apiKeyShownChars   = 8
+    maxAPIKeysPerOwner = 50
+)
+
+var apiKeyScopes = []string{domain.ScopeTasksRead, domain.ScopeTasksWrite}
+
+type APIKeyService struct {
+    repo   repository.APIKeyRepository
+    owner  string
+    tenant string
+    role   domain.Role
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository) *APIKeyService {
+    return &APIKeyService{
+        repo: repo,
+    }
+}
+
+// WithContext scopes key management to the principal in ctx. Keys are
+// bound to the tenant they were created in and keep the role their owner
+// had then.
+func (s *APIKeyService) WithContext(ctx context.Context) *APIKeyService {
+    principal := domain.PrincipalFromContext(ctx)
+    if principal == nil {
+        return s
+    }
+    
+    return &APIKeyService{
+        repo:   s.repo,
+        owner:  principal.Owner(),
+        tenant: domain.TenantFromContext(ctx),
+        role:   principal.Role,
+    }
+}
+
+func (s *APIKeyService) GetAllKeys() ([]domain.APIKey, error) {
+    keys, err := s.repo.GetAll()
+    if err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to get api keys: %w", err))
+    }
+    
+    owned := make([]domain.APIKey, 0, len(keys))
+    for _, key := range keys {
+        if key.OwnerID == s.owner {
+            owned = append(owned, key)
+        }
+    }
+    
+    return owned, nil
+}
+
+// CreateKey issues a key for the current owner. The returned APIKey is the
+// only place the plain key appears.
+func (s *APIKeyService) CreateKey(req domain.CreateAPIKeyRequest) (*domain.APIKey, error) {
+    if s.owner == "" {
+        return nil, fmt.Errorf("api keys require an authenticated user")
+    }
+    
+    name := strings.TrimSpace(req.Name)
+    if name == "" {
+        return nil, fmt.Errorf("name is required")
+    }
+    
+    if len(name) > 100 {
+        return nil, fmt.Errorf("name is too long (max 100 characters)")
+    }
+    
+    scopes, err := normalizeScopes(req.Scopes)
+    if err != nil {
+        return nil, err
+    }
+    
+    if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+        return nil, fmt.Errorf("expires_at must be in the future")
+    }
+    
+    existing, err := s.GetAllKeys()
+    if err != nil {
+        return nil, err
+    }
+    active := 0
+    for _, key := range existing {
+        if key.Active(time.Now()) {
+            active++
+        }
+    }
+    if active >= maxAPIKeysPerOwner {
+        return nil, fmt.Errorf("too many api keys (max %d active)", maxAPIKeysPerOwner)
+    }
+    
+    secret, err := randomToken(apiKeyBytes)
+    if err != nil {
+        return nil, domain.Internal(err)
+    }
+    plain := APIKeyPrefix + secret
+    
+    key := &domain.APIKey{
+        Name:      name,
+        Prefix:    plain[:len(APIKeyPrefix)+apiKeyShownChars],
+        Key:       plain,
+        KeyHash:   hashToken(plain),
+        Scopes:    scopes,
+        OwnerID:   s.owner,
+        TenantID:  s.tenant,
+        Role:      s.role,
+        ExpiresAt: req.ExpiresAt,
+    }
+    
+    if err := s.repo.Create(key); err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to create api key: %w", err))
+    }
+    
+    return key, nil
+}
+
+// RevokeKey disables a key immediately. Revoked keys stay listed for
+// auditing.
+func (s *APIKeyService) RevokeKey(id int) error {
+    if id <= 0 {
+        return fmt.Errorf("invalid api key id: %d", id)
+    }
+    
+    keys, err := s.GetAllKeys()
+    if err != nil {
+        return err
+    }
+    
+    for _, key := range keys {
+        if key.ID != id {
+            continue
+        }
+        if err := s.repo.Revoke(id, time.Now()); err != nil {
+            return domain.Internal(fmt.Errorf("failed to revoke api key: %w", err))
+        }
+        return nil
+    }
+    
+    return fmt.Errorf("api key %d %w", id, domain.ErrNotFound)
+}
+
+// AuthenticateKey resolves a presented key to the principal it acts for
+// and records when it was last used.
+func (s *APIKeyService) AuthenticateKey(plain string) (*domain.Principal, error) {
+    if !strings.HasPrefix(plain, APIKeyPrefix) {
+        return nil, fmt.Errorf("invalid api key")
+    }
+    
+    now := time.Now()
+    key, err := s.repo.GetByHash(hashToken(plain))
+    if err != nil || !key.Active(now) {
+        return nil, fmt.Errorf("invalid api key")
+    }
+    
+    if err := s.repo.Touch(key.ID, now); err != nil {
+        log.Printf("failed to record use of api key %d: %v", key.ID, err)
+    }
+    
+    principal := &domain.Principal{
+        Subject:  key.OwnerID,
+        Scopes:   key.Scopes,
+        Method:   domain.AuthMethodAPIKey,
+        TenantID: key.TenantID,
+        Role:     key.Role,
+        KeyID:    key.ID,
+    }
+    if key.ExpiresAt != nil {
+        principal.ExpiresAt = *key.ExpiresAt
+    }
+    
+    return principal, nil
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+    if len(scopes) == 0 {
+        return nil, fmt.Errorf("at least one scope is required (%s)", strings.Join(apiKeyScopes, ", "))
+    }
+    
+    normalized := make([]string, 0, len(scopes))
+    for _, scope := range scopes {
+        scope = strings.ToLower(strings.TrimSpace(scope))
+        known := false
+        for _, allowed := range apiKeyScopes {
+            if scope == allowed {
+                known = true
+            }
+        }
+        if !known {
+            return nil, fmt.Errorf("unknown scope '%s' (allowed: %s)", scope, strings.Join(apiKeyScopes, ", "))
+        }
+        if !containsString(normalized, scope) {
+            normalized = append(normalized, scope)
+        }
+    }
+    
+    return normalized, nil
+}
+
+func containsString(values []string, value string) bool {
+    for _, v := range values {
+        if v == value {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/repository"
+)
+
+const (
+    minUsernameLength = 3
+    maxUsernameLength = 32
+    minPasswordLength = 8
+    maxPasswordLength = 128
+    sessionIDBytes    = 16
+    refreshTokenBytes = 32
+)
+
+// AuthConfig controls the tokens AuthService issues. Access tokens are
+// HS256 JWTs signed with SigningKey; refresh tokens are opaque.
+type AuthConfig struct {
+    SigningKey   []byte
+    Issuer       string
+    Audience     string
+    AccessTTL    time.Duration
+    RefreshTTL   time.Duration
+    PasswordHash string
+}
+
+type AuthService struct {
+    users    repository.UserRepository
+    sessions repository.SessionRepository
+    config   AuthConfig
+    tenant   string
+    dummy    *dummyPassword
+}
+
+// dummyPassword is hashed once and checked against when a login names an
+// unknown user, so that it takes as long as a wrong password.
+type dummyPassword struct {
+    once sync.Once
+    hash string
+}
+
+func NewAuthService(users repository.UserRepository, sessions repository.SessionRepository, config AuthConfig) *AuthService {
+    return &AuthService{
+        users:    users,
+        sessions: sessions,
+        config:   config,
+        dummy:    &dummyPassword{},
+    }
+}
+
+// WithContext returns a service for the tenant in ctx: accounts are
+// registered in it, and only its accounts can log in or refresh.
+func (s *AuthService) WithContext(ctx context.Context) *AuthService {
+    return &AuthService{
+        users:    s.users,
+        sessions: s.sessions,
+        config:   s.config,
+        tenant:   domain.TenantFromContext(ctx),
+        dummy:    s.dummy,
+    }
+}
+
+// Register creates an account and logs it in.
+func (s *AuthService) Register(req domain.RegisterRequest) (*domain.TokenResponse, error) {
+    username, err := validateUsername(req.Username)
+    if err != nil {
+        return nil, err
+    }
+    
+    if err := validatePassword(req.Password); err != nil {
+        return nil, err
+    }
+    
+    hash, err := hashPassword(s.config.PasswordHash, req.Password)
+    if err != nil {
+        return nil, err
+    }
+    
+    user := &domain.User{
+        Username:     username,
+        TenantID:     s.tenant,
+        PasswordHash: hash,
+    }
+    
+    if err := s.users.Create(user); err != nil {
+        if errors.Is(err, domain.ErrAlreadyExists) {
+            return nil, err
+        }
+        return nil, fmt.Errorf("failed to create user: %w", err)
+    }
+    
+    return s.startSession(user)
+}
+
+// Login checks the password and starts a new session. Unknown users and
+// wrong passwords give the same error and take about the same time.
+func (s *AuthService) Login(req domain.LoginRequest) (*domain.TokenResponse, error) {
+    username := strings.ToLower(strings.TrimSpace(req.Username))
+    
+    user, err := s.users.GetByUsername(s.tenant, username)
+    if err != nil || user.PasswordHash == "" {
+        verifyPassword(s.config.PasswordHash, s.dummyPasswordHash(), req.Password)
+        return nil, fmt.Errorf("invalid username or password")
+    }
+    
+    ok, rehash, err := verifyPassword(s.config.PasswordHash, user.PasswordHash, req.Password)
+    if err != nil {
+        return nil, fmt.Errorf("failed to verify password: %w", err)
+    }
+    if !ok {
+        return nil, fmt.Errorf("invalid username or password")
+    }
+    
+    if rehash {
+        s.upgradeHash(user, req.Password)
+    }
+    
+    return s.startSession(user)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new
+// refresh token. Presenting a refresh token that was already exchanged
+// means it leaked, so the whole session is revoked.
+func (s *AuthService) Refresh(req domain.RefreshRequest) (*domain.TokenResponse, error) {
+    sessionID, secret, found := strings.Cut(req.RefreshToken, ".")
+    if !found || sessionID == "" || secret == "" {
+        return nil, fmt.Errorf("invalid refresh token")
+    }
+    
+    session, err := s.sessions.GetByID(sessionID)
+    if err != nil || !session.Active(time.Now()) {
+        return nil, fmt.Errorf("invalid refresh token")
+    }
+    
+    // A refresh token only works in the tenant it was issued in.
+    if user, err := s.users.GetByID(session.UserID); err != nil || user.TenantID != s.tenant {
+        return nil, fmt.Errorf("invalid refresh token")
+    }
+    
+    newSecret, err := randomToken(refreshTokenBytes)
+    if err != nil {
+        return nil, err
+    }
+    
+    if err := s.sessions.Rotate(session.ID, hashToken(secret), hashToken(newSecret)); err != nil {
+        if revokeErr := s.sessions.Revoke(session.ID, time.Now()); revokeErr != nil {
+            log.Printf("failed to revoke session %s after refresh token reuse: %v", session.ID, revokeErr)
+        }
+        return nil, fmt.Errorf("invalid refresh token")
+    }
+    
+    user, err := s.users.GetByID(session.UserID)
+    if err != nil {
+        return nil, fmt.Errorf("invalid refresh token")
+    }
+    
+    return s.tokenResponse(user, session, newSecret)
+}
+
+// Logout revokes the session the principal's access token belongs to.
+// Access tokens of that session stop working immediately.
+func (s *AuthService) Logout(principal *domain.Principal) error {
+    if !s.isLocal(principal) || principal.SessionID == "" {
+        return fmt.Errorf("token was not issued by this server")
+    }
+    
+    if err := s.sessions.Revoke(principal.SessionID, time.Now()); err != nil {
+        return fmt.Errorf("session %w", domain.ErrNotFound)
+    }
+    
+    return nil
+}
+
+// ChangePassword sets a new password after checking the current one and
+// revokes every other session of the user.
+func (s *AuthService) ChangePassword(principal *domain.Principal, req domain.ChangePasswordRequest) error {
+    user, err := s.CurrentUser(principal)
+    if err != nil {
+        return err
+    }
+    
+    if user.PasswordHash == "" {
+        return fmt.Errorf("account signs in with %s and has no password", user.Provider)
+    }
+    
+    ok, _, err := verifyPassword(s.config.PasswordHash, user.PasswordHash, req.CurrentPassword)
+    if err != nil {
+        return fmt.Errorf("failed to verify password: %w", err)
+    }
+    if !ok {
+        return fmt.Errorf("invalid current password")
+    }
+    
+    if err := validatePassword(req.NewPassword); err != nil {
+        return err
+    }
+    
+    hash, err := hashPassword(s.config.PasswordHash, req.NewPassword)
+    if err != nil {
+        return err
+    }
+    
+    user.PasswordHash = hash
+    if err := s.users.Update(user.ID, user); err != nil {
+        return fmt.Errorf("failed to update user: %w", err)
+    }
+    
+    if err := s.sessions.RevokeUser(user.ID, principal.SessionID, time.Now()); err != nil {
+        return fmt.Errorf("failed to revoke sessions: %w", err)
+    }
+    
+    return nil
+}
+
+// CurrentUser returns the local account a principal stands for.
+func (s *AuthService) CurrentUser(principal *domain.Principal) (*domain.User, error) {
+    if !s.isLocal(principal) {
+        return nil, fmt.Errorf("user %w", domain.ErrNotFound)
+    }
+    
+    id, err := strconv.Atoi(principal.Subject)
+    if err != nil {
+        return nil, fmt.Errorf("user %w", domain.ErrNotFound)
+    }
+    
+    user, err := s.users.GetByID(id)
+    if err != nil {
+        return nil, fmt.Errorf("user %w", domain.ErrNotFound)
+    }
+    
+    return user, nil
+}
+
+// SessionActive tells the JWT verifier whether a token's session is still
+// valid. Tokens from other issuers are not ours to judge.
+func (s *AuthService) SessionActive(issuer, id string) bool {
+    if issuer != s.config.Issuer {
+        return true
+    }
+    
+    session, err := s.sessions.GetByID(id)
+    return err == nil && session.Active(time.Now())
+}
+
+func (s *AuthService) startSession(user *domain.User) (*domain.TokenResponse, error) {
+    id, err := randomToken(sessionIDBytes)
+    if err != nil {
+        return nil, err
+    }
+    secret, err := randomToken(refreshTokenBytes)
+    if err != nil {
+        return nil, err
+    }
+    
+    session := &domain.Session{
+        ID:          id,
+        UserID:      user.ID,
+        RefreshHash: hashToken(secret),
+        ExpiresAt:   time.Now().Add(s.config.RefreshTTL),
+    }
+    if err := s.sessions.Create(session); err != nil {
+        return nil, fmt.Errorf("failed to create session: %w", err)
+    }
+    
+    return s.tokenResponse(user, session, secret)
+}
+
+func (s *AuthService) tokenResponse(user *domain.User, session *domain.Session, secret string) (*domain.TokenResponse, error) {
+    now := time.Now()
+    claims := struct {
+        jwt.RegisteredClaims
+        SessionID string      `json:"sid"`
+        TenantID  string      `json:"tenant,omitempty"`
+        Role      domain.Role `json:"role,omitempty"`
+    }{
+        RegisteredClaims: jwt.RegisteredClaims{
+            Subject:   strconv.Itoa(user.ID),
+            Issuer:    s.config.Issuer,
+            IssuedAt:  jwt.NewNumericDate(now),
+            ExpiresAt: jwt.NewNumericDate(now.Add(s.config.AccessTTL)),
+        },
+        SessionID: session.ID,
+        TenantID:  user.TenantID,
+        Role:      user.Role,
+    }
+    if s.config.Audience != "" {
+        claims.Audience = jwt.ClaimStrings{s.config.Audience}
+    }
+    
+    accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.config.SigningKey)
+    if err != nil {
+        return nil, fmt.Errorf("failed to sign access token: %w", err)
+    }
+    
+    return &domain.TokenResponse{
+        AccessToken:  accessToken,
+        TokenType:    "Bearer",
+        ExpiresIn:    int(s.config.AccessTTL.Seconds()),
+        RefreshToken: session.ID + "." + secret,
+        User:         user,
+    }, nil
+}
+
+// isLocal reports whether principal holds a token this server issued.
+func (s *AuthService) isLocal(principal *domain.Principal) bool {
+    return principal != nil && principal.Method == domain.AuthMethodJWT && !principal.External && principal.Issuer == s.config.Issuer
+}
+
+// upgradeHash rehashes a password that was stored with an outdated
+// algorithm. Failing to do so doesn't fail the login.
+func (s *AuthService) upgradeHash(user *domain.User, password string) {
+    hash, err := hashPassword(s.config.PasswordHash, password)
+    if err != nil {
+        log.Printf("failed to rehash password of user %d: %v", user.ID, err)
+        return
+    }
+    
+    user.PasswordHash = hash
+    if err := s.users.Update(user.ID, user); err != nil {
+        log.Printf("failed to rehash password of user %d: %v", user.ID, err)
+    }
+}
+
+func (s *AuthService) dummyPasswordHash() string {
+    s.dummy.once.Do(func() {
+        s.dummy.hash, _ = hashPassword(s.config.PasswordHash, "dummy-password")
+    })
+    return s.dummy.hash
+}
+
+func validateUsername(username string) (string, error) {
+    username = strings.ToLower(strings.TrimSpace(username))
+    if len(username) < minUsernameLength || len(username) > maxUsernameLength {
+        return "", fmt.Errorf("username must be %d to %d characters long", minUsernameLength, maxUsernameLength)
+    }
+    
+    for _, r := range username {
+        if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-') {
+            return "", fmt.Errorf("username may only contain letters, digits, '_', '.' and '-'")
+        }
+    }
+    
+    return username, nil
+}
+
+func validatePassword(password string) error {
+    if len(password) < minPasswordLength {
+        return fmt.Errorf("password is too short (min %d characters)", minPasswordLength)
+    }
+    
+    if len(password) > maxPasswordLength {
+        return fmt.Errorf("password is too long (max %d characters)", maxPasswordLength)
+    }
+    
+    return nil
+}
+
+func randomToken(size int) (string, error) {
+    buf := make([]byte, size)
+    if _, err := rand.Read(buf); err != nil {
+        return "", fmt.Errorf("failed to generate token: %w", err)
+    }
+    return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+    sum := sha256.Sum256([]byte(token))
+    return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/repository"
+)
+
+const maxBulkOperations = 1000
+
+var errBulkAborted = errors.New("bulk operation aborted")
+
+func (s *TaskService) ExecuteBulk(req domain.BulkRequest) (*domain.BulkResponse, error) {
+    if len(req.Operations) == 0 {
+        return nil, fmt.Errorf("operations are required")
+    }
+    
+    if len(req.Operations) > maxBulkOperations {
+        return nil, fmt.Errorf("too many operations (max %d)", maxBulkOperations)
+    }
+    
+    resp := &domain.BulkResponse{
+        Atomic:  req.Atomic,
+        Results: make([]domain.BulkResult, len(req.Operations)),
+    }
+    
+    err := s.repo.WithinTransaction(func(tx repository.TaskRepository) error {
+        txService := s.inTransaction(tx)
+        
+        for i, op := range req.Operations {
+            result := txService.applyBulkOperation(op)
+            result.Index = i
+            resp.Results[i] = result
+            
+            if req.Atomic && result.Status == domain.BulkStatusFailed {
+                return errBulkAborted
+            }
+        }
+        
+        return nil
+    })
+    if err != nil && !errors.Is(err, errBulkAborted) {
+        return nil, fmt.Errorf("failed to execute bulk operations: %w", err)
+    }
+    
+    if errors.Is(err, errBulkAborted) {
+        for i := range resp.Results {
+            result := &resp.Results[i]
+            switch {
+            case result.Status == domain.BulkStatusSucceeded:
+                result.Status = domain.BulkStatusRolledBack
+                result.Task = nil
+            case result.Status == "":
+                result.Index = i
+                result.Op = req.Operations[i].Op
+                result.Status = domain.BulkStatusSkipped
+            }
+        }
+    }
+    
+    for _, result := range resp.Results {
+        switch result.Status {
+        case domain.BulkStatusSucceeded:
+            resp.Succeeded++
+        case domain.BulkStatusFailed:
+            resp.Failed++
+        }
+    }
+    
+    return resp, nil
+}
+
+func (s *TaskService) applyBulkOperation(op domain.BulkOperation) domain.BulkResult {
+    result := domain.BulkResult{Op: op.Op}
+    
+    var task *domain.Task
+    var err error
+    switch op.Op {
+    case domain.BulkOpCreate:
+        task, err = s.applyBulkCreate(op)
+    case domain.BulkOpUpdate:
+        task, err = s.UpdateTask(op.ID, domain.UpdateTaskRequest{
+            Title:     op.Title,
+            Completed: op.Completed,
+            Priority:  op.Priority,
+            Tags:      op.Tags,
+            DueDate:   op.DueDate,
+        })
+    case domain.BulkOpDelete:
+        err = s.DeleteTask(op.ID)
+    default:
+        err = fmt.Errorf("unknown op '%s'", op.Op)
+    }
+    
+    if err != nil {
+        result.Status = domain.BulkStatusFailed
+        result.Error = err.Error()
+        return result
+    }
+    
+    result.Status = domain.BulkStatusSucceeded
+    result.Task = task
+    return result
+}
+
+func (s *TaskService) applyBulkCreate(op domain.BulkOperation) (*domain.Task, error) {
+    if op.Title == nil {
+        return nil, fmt.Errorf("title is required")
+    }
+    
+    req := domain.CreateTaskRequest{
+        Title:   *op.Title,
+        Tags:    op.Tags,
+        DueDate: op.DueDate,
+    }
+    if op.Priority != nil {
+        req.Priority = *op.Priority
+    }
+    
+    task, err := s.CreateTask(req)
+    if err != nil {
+        return nil, err
+    }
+    
+    if op.Completed != nil && *op.Completed {
+        return s.UpdateTask(task.ID, domain.UpdateTaskRequest{Completed: op.Completed})
+    }
+    
+    return task, nil
+}
\ No newline at end of file
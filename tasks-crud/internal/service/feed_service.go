@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/query"
+	"tasks-crud/internal/repository"
+)
+
+const feedTokenBytes = 32
+
+type FeedService struct {
+    repo  repository.CalendarFeedRepository
+    tasks *TaskService
+    owner string
+}
+
+func NewFeedService(repo repository.CalendarFeedRepository, tasks *TaskService) *FeedService {
+    return &FeedService{
+        repo:  repo,
+        tasks: tasks,
+    }
+}
+
+// WithContext scopes feed management to the tenant and the principal in
+// ctx. OpenFeed only uses the tenant: a feed token always opens its owner's
+// tasks.
+func (s *FeedService) WithContext(ctx context.Context) *FeedService {
+    scoped := &FeedService{
+        repo:  s.repo,
+        tasks: s.tasks,
+    }
+    if data := repository.TenantDataFromContext(ctx); data != nil {
+        scoped.repo = data.Feeds
+        scoped.tasks = s.tasks.ForTenant(data)
+    }
+    if principal := domain.PrincipalFromContext(ctx); principal != nil {
+        scoped.owner = principal.Owner()
+    }
+    
+    return scoped
+}
+
+func (s *FeedService) GetAllFeeds() ([]domain.CalendarFeed, error) {
+    feeds, err := s.repo.GetAll()
+    if err != nil {
+        return nil, fmt.Errorf("failed to get feeds: %w", err)
+    }
+    
+    owned := make([]domain.CalendarFeed, 0, len(feeds))
+    for _, feed := range feeds {
+        if s.owner == "" || feed.OwnerID == s.owner {
+            owned = append(owned, feed)
+        }
+    }
+    
+    return owned, nil
+}
+
+// CreateFeed stores a new feed and returns it with its secret token, which
+// can't be recovered later.
+func (s *FeedService) CreateFeed(req domain.CreateCalendarFeedRequest) (*domain.CalendarFeed, string, error) {
+    name := strings.TrimSpace(req.Name)
+    if name == "" {
+        return nil, "", fmt.Errorf("name is required")
+    }
+    
+    if len(name) > 100 {
+        return nil, "", fmt.Errorf("name is too long (max 100 characters)")
+    }
+    
+    q := strings.TrimSpace(req.Query)
+    if len(q) > maxFilterQueryLength {
+        return nil, "", fmt.Errorf("query is too long (max %d characters)", maxFilterQueryLength)
+    }
+    
+    if _, err := query.ParseFilter(q, time.Now()); err != nil {
+        return nil, "", err
+    }
+    
+    buf := make([]byte, feedTokenBytes)
+    if _, err := rand.Read(buf); err != nil {
+        return nil, "", fmt.Errorf("failed to generate feed token: %w", err)
+    }
+    token := base64.RawURLEncoding.EncodeToString(buf)
+    
+    feed := &domain.CalendarFeed{
+        Name:      name,
+        Query:     q,
+        OwnerID:   s.owner,
+        TokenHash: hashFeedToken(token),
+    }
+    
+    if err := s.repo.Create(feed); err != nil {
+        return nil, "", fmt.Errorf("failed to create feed: %w", err)
+    }
+    
+    return feed, token, nil
+}
+
+func (s *FeedService) DeleteFeed(id int) error {
+    if id <= 0 {
+        return fmt.Errorf("invalid feed id: %d", id)
+    }
+    
+    if s.owner != "" {
+        feeds, err := s.GetAllFeeds()
+        if err != nil {
+            return err
+        }
+        if !containsFeed(feeds, id) {
+            return fmt.Errorf("feed %d %w", id, domain.ErrNotFound)
+        }
+    }
+    
+    if err := s.repo.Delete(id); err != nil {
+        return fmt.Errorf("feed %d %w: %w", id, domain.ErrNotFound, err)
+    }
+    
+    return nil
+}
+
+// OpenFeed resolves a secret token to its feed and the tasks the feed
+// currently selects.
+func (s *FeedService) OpenFeed(token string) (*domain.CalendarFeed, []domain.Task, error) {
+    if token == "" {
+        return nil, nil, fmt.Errorf("feed %w", domain.ErrNotFound)
+    }
+    
+    feed, err := s.repo.GetByTokenHash(hashFeedToken(token))
+    if err != nil {
+        return nil, nil, fmt.Errorf("feed %w", domain.ErrNotFound)
+    }
+    
+    if err := s.repo.Touch(feed.ID, time.Now()); err != nil {
+        return nil, nil, fmt.Errorf("feed %w", domain.ErrNotFound)
+    }
+    
+    tasks := s.tasks
+    if feed.OwnerID != "" {
+        tasks = tasks.ForOwner(feed.OwnerID)
+    }
+    
+    selected, err := tasks.ListTasks(feed.Query)
+    if err != nil {
+        return nil, nil, err
+    }
+    
+    return feed, selected, nil
+}
+
+func hashFeedToken(token string) string {
+    sum := sha256.Sum256([]byte(token))
+    return hex.EncodeToString(sum[:])
+}
+
+func containsFeed(feeds []domain.CalendarFeed, id int) bool {
+    for _, feed := range feeds {
+        if feed.ID == id {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/query"
+	"tasks-crud/internal/repository"
+)
+
+const maxFilterQueryLength = 500
+
+func (s *TaskService) ListTasks(q string) ([]domain.Task, error) {
+    filter, err := query.ParseFilter(q, time.Now())
+    if err != nil {
+        return nil, err
+    }
+    
+    tasks, err := s.repo.List(filter)
+    if err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to list tasks: %w", err))
+    }
+    
+    return tasks, nil
+}
+
+type FilterService struct {
+    repo  repository.SavedFilterRepository
+    tasks *TaskService
+    owner string
+}
+
+func NewFilterService(repo repository.SavedFilterRepository, tasks *TaskService) *FilterService {
+    return &FilterService{
+        repo:  repo,
+        tasks: tasks,
+    }
+}
+
+// WithContext scopes the service to the tenant and the filters of the
+// principal in ctx; filters run against that principal's tasks only.
+func (s *FilterService) WithContext(ctx context.Context) *FilterService {
+    scoped := &FilterService{
+        repo:  s.repo,
+        tasks: s.tasks.WithContext(ctx),
+    }
+    if data := repository.TenantDataFromContext(ctx); data != nil {
+        scoped.repo = data.Filters
+    }
+    if principal := domain.PrincipalFromContext(ctx); principal != nil {
+        scoped.owner = principal.Owner()
+    }
+    
+    return scoped
+}
+
+func (s *FilterService) GetAllFilters() ([]domain.SavedFilter, error) {
+    filters, err := s.repo.GetAll()
+    if err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to get filters: %w", err))
+    }
+    
+    owned := make([]domain.SavedFilter, 0, len(filters))
+    for _, filter := range filters {
+        if s.owns(filter.OwnerID) {
+            owned = append(owned, filter)
+        }
+    }
+    
+    return owned, nil
+}
+
+func (s *FilterService) GetFilterByID(id int) (*domain.SavedFilter, error) {
+    if id <= 0 {
+        return nil, fmt.Errorf("invalid filter id")
+    }
+    
+    filter, err := s.repo.GetByID(id)
+    if err != nil || !s.owns(filter.OwnerID) {
+        return nil, fmt.Errorf("filter %w", domain.ErrNotFound)
+    }
+    
+    return filter, nil
+}
+
+func (s *FilterService) CreateFilter(req domain.CreateSavedFilterRequest) (*domain.SavedFilter, error) {
+    name, err := s.validateFilter(0, req.Name, req.Query)
+    if err != nil {
+        return nil, err
+    }
+    
+    filter := &domain.SavedFilter{
+        Name:    name,
+        Query:   strings.TrimSpace(req.Query),
+        OwnerID: s.owner,
+    }
+    
+    if err := s.repo.Create(filter); err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to create filter: %w", err))
+    }
+    
+    return filter, nil
+}
+
+func (s *FilterService) UpdateFilter(id int, req domain.UpdateSavedFilterRequest) (*domain.SavedFilter, error) {
+    if id <= 0 {
+        return nil, fmt.Errorf("invalid filter id: %d", id)
+    }
+    
+    existingFilter, err := s.repo.GetByID(id)
+    if err != nil {
+        return nil, fmt.Errorf("filter %d %w: %w", id, domain.ErrNotFound, err)
+    }
+    if !s.owns(existingFilter.OwnerID) {
+        return nil, fmt.Errorf("filter %d %w", id, domain.ErrNotFound)
+    }
+    
+    updatedFilter := *existingFilter
+    if req.Name != nil {
+        updatedFilter.Name = *req.Name
+    }
+    if req.Query != nil {
+        updatedFilter.Query = strings.TrimSpace(*req.Query)
+    }
+    
+    name, err := s.validateFilter(id, updatedFilter.Name, updatedFilter.Query)
+    if err != nil {
+        return nil, err
+    }
+    updatedFilter.Name = name
+    
+    if err := s.repo.Update(id, &updatedFilter); err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to update filter: %w", err))
+    }
+    
+    return &updatedFilter, nil
+}
+
+func (s *FilterService) DeleteFilter(id int) error {
+    if id <= 0 {
+        return fmt.Errorf("invalid filter id: %d", id)
+    }
+    
+    if filter, err := s.repo.GetByID(id); err == nil && !s.owns(filter.OwnerID) {
+        return fmt.Errorf("filter %d %w", id, domain.ErrNotFound)
+    }
+    
+    if err := s.repo.Delete(id); err != nil {
+        return fmt.Errorf("filter %d %w: %w", id, domain.ErrNotFound, err)
+    }
+    
+    return nil
+}
+
+func (s *FilterService) RunFilter(id int) ([]domain.Task, error) {
+    filter, err := s.GetFilterByID(id)
+    if err != nil {
+        return nil, err
+    }
+    
+    return s.tasks.ListTasks(filter.Query)
+}
+
+func (s *FilterService) validateFilter(id int, name, q string) (string, error) {
+    name = strings.TrimSpace(name)
+    if name == "" {
+        return "", fmt.Errorf("name is required")
+    }
+    
+    if len(name) > 100 {
+        return "", fmt.Errorf("name is too long (max 100 characters)")
+    }
+    
+    if len(q) > maxFilterQueryLength {
+        return "", fmt.Errorf("query is too long (max %d characters)", maxFilterQueryLength)
+    }
+    
+    if _, err := query.ParseFilter(q, time.Now()); err != nil {
+        return "", err
+    }
+    
+    filters, err := s.repo.GetAll()
+    if err != nil {
+        return "", domain.Internal(fmt.Errorf("failed to get filters: %w", err))
+    }
+    for _, filter := range filters {
+        if filter.ID != id && s.owns(filter.OwnerID) && strings.EqualFold(filter.Name, name) {
+            return "", fmt.Errorf("filter with name '%s' %w", name, domain.ErrAlreadyExists)
+        }
+    }
+    
+    return name, nil
+}
+
+// owns reports whether a filter of owner is visible; an unscoped service
+// sees all of them.
+func (s *FilterService) owns(owner string) bool {
+    return s.owner == "" || owner == s.owner
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/repository"
+)
+
+const maxImportRecords = 10000
+
+var errDryRun = errors.New("dry run")
+
+// ImportTasks creates a task for every record that passes validation and
+// does not duplicate an existing title (or one earlier in the same import).
+// Creation and completion dates read from the file are kept.
+// With dryRun the whole import runs in a transaction that is then rolled
+// back, so the report is exactly what a real import would do.
+func (s *TaskService) ImportTasks(records []domain.ImportRecord, dryRun bool) (*domain.ImportResponse, error) {
+    if len(records) == 0 {
+        return nil, fmt.Errorf("no tasks to import")
+    }
+    
+    if len(records) > maxImportRecords {
+        return nil, fmt.Errorf("too many tasks to import (max %d)", maxImportRecords)
+    }
+    
+    resp := &domain.ImportResponse{
+        DryRun:  dryRun,
+        Results: make([]domain.ImportResult, 0, len(records)),
+    }
+    
+    err := s.repo.WithinTransaction(func(tx repository.TaskRepository) error {
+        txService := s.inTransaction(tx)
+        
+        for _, record := range records {
+            result := txService.importRecord(record)
+            if dryRun {
+                result.Task = nil
+            }
+            resp.Results = append(resp.Results, result)
+        }
+        
+        if dryRun {
+            return errDryRun
+        }
+        return nil
+    })
+    if err != nil && !errors.Is(err, errDryRun) {
+        return nil, fmt.Errorf("failed to import tasks: %w", err)
+    }
+    
+    for _, result := range resp.Results {
+        switch result.Status {
+        case domain.ImportStatusCreated:
+            resp.Created++
+        case domain.ImportStatusSkipped:
+            resp.Skipped++
+        case domain.ImportStatusRejected:
+            resp.Rejected++
+        }
+    }
+    
+    return resp, nil
+}
+
+func (s *TaskService) importRecord(record domain.ImportRecord) domain.ImportResult {
+    result := domain.ImportResult{
+        Row:   record.Row,
+        Title: strings.TrimSpace(record.Task.Title),
+    }
+    
+    if record.Err != nil {
+        return importRejected(result, record.Err)
+    }
+    
+    if err := validateCreateRequest(record.Task); err != nil {
+        return importRejected(result, err)
+    }
+    
+    isDuplicate, err := s.isDuplicateTitle(record.Task.Title)
+    if err != nil {
+        return importRejected(result, domain.Internal(fmt.Errorf("failed to check title: %w", err)))
+    }
+    if isDuplicate {
+        result.Status = domain.ImportStatusSkipped
+        result.Error = fmt.Sprintf("task with title '%s' already exists", result.Title)
+        return result
+    }
+    
+    task, err := s.CreateTask(record.Task)
+    if err != nil {
+        return importRejected(result, err)
+    }
+    
+    if record.Completed || record.CreatedAt != nil {
+        imported := *task
+        imported.Completed = record.Completed
+        imported.CompletedAt = record.CompletedAt
+        if record.CreatedAt != nil {
+            imported.CreatedAt = *record.CreatedAt
+        }
+        if err := s.repo.Update(task.ID, &imported); err != nil {
+            return importRejected(result, err)
+        }
+        task = &imported
+    }
+    
+    result.Status = domain.ImportStatusCreated
+    result.Task = task
+    return result
+}
+
+func importRejected(result domain.ImportResult, err error) domain.ImportResult {
+    result.Status = domain.ImportStatusRejected
+    result.Error = err.Error()
+    return result
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+    providerClientID     = "tasks-crud-test"
+    providerClientSecret = "test-secret"
+    providerKeyID        = "test"
+    providerCodeTTL      = time.Minute
+    providerTokenTTL     = 5 * time.Minute
+)
+
+type providerUser struct {
+    Subject  string
+    Username string
+    Email    string
+    Groups   []string
+}
+
+// testProvider is a minimal OpenID provider for the single sign-on tests.
+// It signs in every authorization request without asking: as the user
+// named by login_hint, or as the first user.
+type testProvider struct {
+    // URL is the issuer, e.g. "http://127.0.0.1:41234".
+    URL   string
+    Users []providerUser
+    
+    // Nonce, if set, is put in ID tokens instead of the requested one.
+    Nonce string
+    
+    server *httptest.Server
+    key    *rsa.PrivateKey
+    codes  map[string]grant
+    mu     sync.Mutex
+}
+
+// grant is an issued authorization code.
+type grant struct {
+    user        providerUser
+    redirectURI string
+    challenge   string
+    nonce       string
+    expiresAt   time.Time
+}
+
+// newTestProvider starts a provider on a random local port for the
+// duration of the test.
+func newTestProvider(t *testing.T, users ...providerUser) *testProvider {
+    t.Helper()
+    key, err := rsa.GenerateKey(rand.Reader, 2048)
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    p := &testProvider{
+        Users: users,
+        key:   key,
+        codes: make(map[string]grant),
+    }
+    
+    mux := http.NewServeMux()
+    mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+    mux.HandleFunc("/authorize", p.authorize)
+    mux.HandleFunc("/token", p.token)
+    mux.HandleFunc("/jwks", p.jwks)
+    p.server = httptest.NewServer(mux)
+    p.URL = p.server.URL
+    t.Cleanup(p.server.Close)
+    
+    return p
+}
+
+func (p *testProvider) discovery(w http.ResponseWriter, r *http.Request) {
+    writeProviderJSON(w, http.StatusOK, map[string]interface{}{
+        "issuer":                                p.URL,
+        "authorization_endpoint":                p.URL + "/authorize",
+        "token_endpoint":                        p.URL + "/token",
+        "jwks_uri":                              p.URL + "/jwks",
+        "response_types_supported":              []string{"code"},
+        "subject_types_supported":               []string{"public"},
+        "id_token_signing_alg_values_supported": []string{"RS256"},
+        "code_challenge_methods_supported":      []string{"S256"},
+        "grant_types_supported":                 []string{"authorization_code"},
+    })
+}
+
+func (p *testProvider) jwks(w http.ResponseWriter, r *http.Request) {
+    writeProviderJSON(w, http.StatusOK, map[string]interface{}{
+        "keys": []map[string]string{{
+            "kty": "RSA",
+            "kid": providerKeyID,
+            "use": "sig",
+            "alg": "RS256",
+            "n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+            "e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+        }},
+    })
+}
+
+func (p *testProvider) authorize(w http.ResponseWriter, r *http.Request) {
+    query := r.URL.Query()
+    redirectURI, err := url.Parse(query.Get("redirect_uri"))
+    if err != nil || !redirectURI.IsAbs() || query.Get("client_id") != providerClientID {
+        http.Error(w, "invalid client_id or redirect_uri", http.StatusBadRequest)
+        return
+    }
+    
+    reply := redirectURI.Query()
+    reply.Set("state", query.Get("state"))
+    
+    switch {
+    case query.Get("response_type") != "code":
+        reply.Set("error", "unsupported_response_type")
+    case query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256":
+        reply.Set("error", "invalid_request")
+        reply.Set("error_description", "PKCE with S256 is required")
+    case len(p.Users) == 0:
+        reply.Set("error", "access_denied")
+    default:
+        user := p.Users[0]
+        for _, candidate := range p.Users {
+            if candidate.Username == query.Get("login_hint") {
+                user = candidate
+            }
+        }
+        
+        code := randomProviderString()
+        p.mu.Lock()
+        p.codes[code] = grant{
+            user:        user,
+            redirectURI: redirectURI.String(),
+            challenge:   query.Get("code_challenge"),
+            nonce:       query.Get("nonce"),
+            expiresAt:   time.Now().Add(providerCodeTTL),
+        }
+        p.mu.Unlock()
+        reply.Set("code", code)
+    }
+    
+    redirectURI.RawQuery = reply.Encode()
+    http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (p *testProvider) token(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost || r.ParseForm() != nil {
+        tokenError(w, http.StatusBadRequest, "invalid_request", "POST a form")
+        return
+    }
+    
+    clientID, clientSecret, ok := r.BasicAuth()
+    if ok {
+        clientID, _ = url.QueryUnescape(clientID)
+        clientSecret, _ = url.QueryUnescape(clientSecret)
+    } else {
+        clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+    }
+    if clientID != providerClientID || clientSecret != providerClientSecret {
+        tokenError(w, http.StatusUnauthorized, "invalid_client", "unknown client or wrong secret")
+        return
+    }
+    
+    if r.PostForm.Get("grant_type") != "authorization_code" {
+        tokenError(w, http.StatusBadRequest, "unsupported_grant_type", "")
+        return
+    }
+    
+    p.mu.Lock()
+    code := r.PostForm.Get("code")
+    issued, exists := p.codes[code]
+    delete(p.codes, code)
+    p.mu.Unlock()
+    
+    switch {
+    case !exists || time.Now().After(issued.expiresAt):
+        tokenError(w, http.StatusBadRequest, "invalid_grant", "unknown or expired code")
+        return
+    case r.PostForm.Get("redirect_uri") != issued.redirectURI:
+        tokenError(w, http.StatusBadRequest, "invalid_grant", "redirect_uri mismatch")
+        return
+    case providerChallenge(r.PostForm.Get("code_verifier")) != issued.challenge:
+        tokenError(w, http.StatusBadRequest, "invalid_grant", "code_verifier doesn't match the challenge")
+        return
+    }
+    
+    nonce := issued.nonce
+    if p.Nonce != "" {
+        nonce = p.Nonce
+    }
+    
+    now := time.Now()
+    claims := jwt.MapClaims{
+        "iss":                p.URL,
+        "sub":                issued.user.Subject,
+        "aud":                providerClientID,
+        "iat":                now.Unix(),
+        "exp":                now.Add(providerTokenTTL).Unix(),
+        "nonce":              nonce,
+        "preferred_username": issued.user.Username,
+        "email":              issued.user.Email,
+        "email_verified":     issued.user.Email != "",
+        "groups":             issued.user.Groups,
+    }
+    token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+    token.Header["kid"] = providerKeyID
+    idToken, err := token.SignedString(p.key)
+    if err != nil {
+        tokenError(w, http.StatusInternalServerError, "server_error", err.Error())
+        return
+    }
+    
+    w.Header().Set("Cache-Control", "no-store")
+    writeProviderJSON(w, http.StatusOK, map[string]interface{}{
+        "access_token": randomProviderString(),
+        "token_type":   "Bearer",
+        "expires_in":   int(providerTokenTTL.Seconds()),
+        "id_token":     idToken,
+    })
+}
+
+func tokenError(w http.ResponseWriter, status int, code, description string) {
+    writeProviderJSON(w, status, map[string]string{
+        "error":             code,
+        "error_description": description,
+    })
+}
+
+func writeProviderJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(v)
+}
+
+// providerChallenge computes the S256 challenge independently of
+// oidc.Challenge, so the tests don't check the client against itself.
+func providerChallenge(verifier string) string {
+    sum := sha256.Sum256([]byte(verifier))
+    return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomProviderString() string {
+    buf := make([]byte, 24)
+    rand.Read(buf)
+    return base64.RawURLEncoding.EncodeToString(buf)
+}
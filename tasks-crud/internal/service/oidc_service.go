@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/oidc"
+	"tasks-crud/internal/repository"
+)
+
+const (
+    OIDCLoginTTL        = 10 * time.Minute
+    maxPendingOIDCLogin = 10000
+    
+    // maxOIDCLoginsPerClient bounds the logins one client can have in
+    // progress. Starting another drops its oldest, so a client can't crowd
+    // out everyone else and a user who gives up and retries isn't refused.
+    maxOIDCLoginsPerClient = 5
+)
+
+// OIDCConfig maps the groups of single sign-on users to roles. A user gets
+// the highest role of their groups, or DefaultRole if none is mapped; an
+// empty DefaultRole refuses such users.
+type OIDCConfig struct {
+    GroupsClaim string
+    GroupRoles  map[string]domain.Role
+    DefaultRole domain.Role
+}
+
+// OIDCService logs users in through an OpenID provider and provisions an
+// account on their first login. The session that follows is an ordinary
+// AuthService session.
+type OIDCService struct {
+    client *oidc.Client
+    auth   *AuthService
+    users  repository.UserRepository
+    config OIDCConfig
+    
+    pending map[string]oidcLogin
+    mu      sync.Mutex
+}
+
+// oidcLogin is a login that was sent to the provider and hasn't come back.
+type oidcLogin struct {
+    verifier  string
+    nonce     string
+    tenantID  string
+    client    string
+    expiresAt time.Time
+}
+
+func NewOIDCService(client *oidc.Client, auth *AuthService, users repository.UserRepository, config OIDCConfig) *OIDCService {
+    if config.GroupsClaim == "" {
+        config.GroupsClaim = "groups"
+    }
+    
+    return &OIDCService{
+        client:  client,
+        auth:    auth,
+        users:   users,
+        config:  config,
+        pending: make(map[string]oidcLogin),
+    }
+}
+
+// BeginLogin returns the provider URL to send the browser to and the
+// login's state, which the caller binds to the browser (see
+// CompleteLogin). The login happens in the tenant in ctx; client
+// identifies the caller for the per-client limit on pending logins.
+func (s *OIDCService) BeginLogin(ctx context.Context, loginHint, client string) (string, string, error) {
+    state, err := oidc.NewVerifier()
+    if err != nil {
+        return "", "", err
+    }
+    nonce, err := oidc.NewVerifier()
+    if err != nil {
+        return "", "", err
+    }
+    verifier, err := oidc.NewVerifier()
+    if err != nil {
+        return "", "", err
+    }
+    
+    authURL, err := s.client.AuthCodeURL(ctx, state, nonce, verifier, loginHint)
+    if err != nil {
+        return "", "", err
+    }
+    
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    
+    now := time.Now()
+    s.sweep(now)
+    s.dropOldest(client)
+    if len(s.pending) >= maxPendingOIDCLogin {
+        return "", "", fmt.Errorf("too many logins in progress")
+    }
+    s.pending[state] = oidcLogin{
+        verifier:  verifier,
+        nonce:     nonce,
+        tenantID:  domain.TenantFromContext(ctx),
+        client:    client,
+        expiresAt: now.Add(OIDCLoginTTL),
+    }
+    
+    return authURL, state, nil
+}
+
+// CompleteLogin handles the provider's redirect back: it redeems code,
+// validates the ID token and starts a session for the user, creating the
+// account first if needed. state can be used only once, and only by the
+// browser that started the login: browserState is the state that browser
+// was given by BeginLogin.
+func (s *OIDCService) CompleteLogin(ctx context.Context, state, browserState, code string) (*domain.TokenResponse, error) {
+    if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+        return nil, fmt.Errorf("login state doesn't belong to this browser")
+    }
+    
+    s.mu.Lock()
+    login, exists := s.pending[state]
+    delete(s.pending, state)
+    s.mu.Unlock()
+    
+    if !exists || time.Now().After(login.expiresAt) {
+        return nil, fmt.Errorf("invalid or expired login state")
+    }
+    if code == "" {
+        return nil, fmt.Errorf("invalid authorization code")
+    }
+    
+    token, err := s.client.Exchange(ctx, code, login.verifier, login.nonce)
+    if err != nil {
+        return nil, err
+    }
+    
+    role := s.roleFor(token.Strings(s.config.GroupsClaim))
+    if role == "" {
+        return nil, fmt.Errorf("%w: none of the user's groups grants access", domain.ErrForbidden)
+    }
+    
+    user, err := s.provision(login.tenantID, token, role)
+    if err != nil {
+        return nil, err
+    }
+    
+    auth := s.auth.WithContext(domain.WithTenant(ctx, login.tenantID))
+    return auth.startSession(user)
+}
+
+// provision returns the account of the token's subject, creating it on
+// first login. The role and email are refreshed on every login, so group
+// changes at the provider take effect at the next one.
+func (s *OIDCService) provision(tenantID string, token *oidc.IDToken, role domain.Role) (*domain.User, error) {
+    email := token.String("email")
+    
+    user, err := s.users.GetByExternalID(tenantID, token.Issuer, token.Subject)
+    if err == nil {
+        if user.Role != role || user.Email != email {
+            user.Role = role
+            user.Email = email
+            if err := s.users.Update(user.ID, user); err != nil {
+                return nil, fmt.Errorf("failed to update user: %w", err)
+            }
+        }
+        return user, nil
+    }
+    
+    base := usernameFrom(token)
+    for attempt := 1; attempt <= 100; attempt++ {
+        username := base
+        if attempt > 1 {
+            suffix := "-" + strconv.Itoa(attempt)
+            if len(username)+len(suffix) > maxUsernameLength {
+                username = username[:maxUsernameLength-len(suffix)]
+            }
+            username += suffix
+        }
+        
+        user := &domain.User{
+            Username:   username,
+            TenantID:   tenantID,
+            Email:      email,
+            Provider:   token.Issuer,
+            ExternalID: token.Subject,
+            Role:       role,
+        }
+        err := s.users.Create(user)
+        if err == nil {
+            log.Printf("provisioned user %d (%s) for %s at %s", user.ID, user.Username, token.Subject, token.Issuer)
+            return user, nil
+        }
+        if !errors.Is(err, domain.ErrAlreadyExists) {
+            return nil, fmt.Errorf("failed to create user: %w", err)
+        }
+    }
+    
+    return nil, fmt.Errorf("failed to create user: no free username for '%s'", base)
+}
+
+func (s *OIDCService) roleFor(groups []string) domain.Role {
+    var best domain.Role
+    for _, group := range groups {
+        if role := s.config.GroupRoles[group]; role.Valid() && !best.Allows(role) {
+            best = role
+        }
+    }
+    
+    if best == "" {
+        return s.config.DefaultRole
+    }
+    return best
+}
+
+// sweep drops expired logins. Callers hold s.mu.
+func (s *OIDCService) sweep(now time.Time) {
+    for state, login := range s.pending {
+        if now.After(login.expiresAt) {
+            delete(s.pending, state)
+        }
+    }
+}
+
+// dropOldest makes room for another login of client by dropping its
+// oldest ones beyond maxOIDCLoginsPerClient-1. Callers hold s.mu.
+func (s *OIDCService) dropOldest(client string) {
+    for {
+        oldest, count := "", 0
+        for state, login := range s.pending {
+            if login.client != client {
+                continue
+            }
+            count++
+            if oldest == "" || login.expiresAt.Before(s.pending[oldest].expiresAt) {
+                oldest = state
+            }
+        }
+        if count < maxOIDCLoginsPerClient {
+            return
+        }
+        delete(s.pending, oldest)
+    }
+}
+
+// usernameFrom derives a valid username from preferred_username, the
+// local part of the email or the subject, in that order.
+func usernameFrom(token *oidc.IDToken) string {
+    candidates := []string{
+        token.String("preferred_username"),
+        strings.SplitN(token.String("email"), "@", 2)[0],
+        token.Subject,
+    }
+    
+    for _, candidate := range candidates {
+        var b strings.Builder
+        for _, r := range strings.ToLower(candidate) {
+            switch {
+            case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+                b.WriteRune(r)
+            case r == '@' || r == ' ':
+                b.WriteRune('.')
+            }
+        }
+        
+        username := b.String()
+        if len(username) > maxUsernameLength {
+            username = username[:maxUsernameLength]
+        }
+        if len(username) >= minUsernameLength {
+            return username
+        }
+    }
+    
+    return "user-" + strconv.FormatInt(time.Now().UnixNano()%1000000, 10)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/oidc"
+	"tasks-crud/internal/repository"
+)
+
+func newOIDCTestService(provider *testProvider, config OIDCConfig) (*OIDCService, repository.UserRepository) {
+    users := repository.NewInMemoryUserRepository()
+    auth := NewAuthService(users, repository.NewInMemorySessionRepository(), AuthConfig{
+        SigningKey: []byte("test-signing-key-of-enough-length"),
+        Issuer:     "tasks-crud",
+        Audience:   "tasks-crud",
+        AccessTTL:  time.Minute,
+        RefreshTTL: time.Hour,
+    })
+    client := oidc.NewClient(oidc.Config{
+        Issuer:       provider.URL,
+        ClientID:     providerClientID,
+        ClientSecret: providerClientSecret,
+        RedirectURL:  "http://tasks.test/api/v1/auth/oidc/callback",
+        Scopes:       []string{"openid"},
+    })
+    return NewOIDCService(client, auth, users, config), users
+}
+
+// authorize sends the browser to the provider and returns the login's
+// state, the authorization URL and the code the provider sent back.
+func authorize(t *testing.T, s *OIDCService, loginHint string) (string, *url.URL, string) {
+    t.Helper()
+    authURL, state, err := s.BeginLogin(context.Background(), loginHint, "ip:192.0.2.1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    browser := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+        return http.ErrUseLastResponse
+    }}
+    resp, err := browser.Get(authURL)
+    if err != nil {
+        t.Fatal(err)
+    }
+    resp.Body.Close()
+    
+    callback, err := url.Parse(resp.Header.Get("Location"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := callback.Query().Get("state"); got != state {
+        t.Fatalf("provider returned state %q, want %q", got, state)
+    }
+    
+    sent, err := url.Parse(authURL)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return state, sent, callback.Query().Get("code")
+}
+
+func TestOIDCLoginUsesPKCE(t *testing.T) {
+    provider := newTestProvider(t, providerUser{Subject: "1", Username: "alice"})
+    s, _ := newOIDCTestService(provider, OIDCConfig{DefaultRole: domain.RoleEditor})
+    
+    state, sent, code := authorize(t, s, "")
+    verifier := s.pending[state].verifier
+    query := sent.Query()
+    if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") != providerChallenge(verifier) {
+        t.Fatalf("authorization request has challenge %q (%s), want the S256 challenge of the verifier",
+            query.Get("code_challenge"), query.Get("code_challenge_method"))
+    }
+    if strings.Contains(sent.RawQuery, verifier) {
+        t.Fatal("the verifier itself was sent to the provider")
+    }
+    if _, err := s.CompleteLogin(context.Background(), state, state, code); err != nil {
+        t.Fatalf("login failed: %v", err)
+    }
+    
+    // A code redeemed with another verifier, as by someone who intercepted
+    // it, is refused by the provider.
+    state, _, code = authorize(t, s, "")
+    login := s.pending[state]
+    login.verifier, _ = oidc.NewVerifier()
+    s.pending[state] = login
+    if _, err := s.CompleteLogin(context.Background(), state, state, code); err == nil || !strings.Contains(err.Error(), "failed to redeem code") {
+        t.Fatalf("got %v, want the code to be refused", err)
+    }
+}
+
+func TestOIDCLoginChecksNonce(t *testing.T) {
+    provider := newTestProvider(t, providerUser{Subject: "1", Username: "alice"})
+    provider.Nonce = "replayed"
+    s, _ := newOIDCTestService(provider, OIDCConfig{DefaultRole: domain.RoleEditor})
+    
+    state, _, code := authorize(t, s, "")
+    if _, err := s.CompleteLogin(context.Background(), state, state, code); err == nil || !strings.Contains(err.Error(), "nonce mismatch") {
+        t.Fatalf("got %v, want a nonce mismatch", err)
+    }
+}
+
+func TestOIDCStateIsBoundToTheBrowserAndUsedOnce(t *testing.T) {
+    provider := newTestProvider(t, providerUser{Subject: "1", Username: "alice"})
+    s, _ := newOIDCTestService(provider, OIDCConfig{DefaultRole: domain.RoleEditor})
+    
+    state, _, code := authorize(t, s, "")
+    otherState, _, _ := authorize(t, s, "")
+    
+    for _, browserState := range []string{"", otherState} {
+        if _, err := s.CompleteLogin(context.Background(), state, browserState, code); err == nil {
+            t.Fatalf("a browser holding state %q completed someone else's login", browserState)
+        }
+    }
+    if _, err := s.CompleteLogin(context.Background(), state, state, code); err != nil {
+        t.Fatalf("the browser that started the login was refused: %v", err)
+    }
+    if _, err := s.CompleteLogin(context.Background(), state, state, code); err == nil {
+        t.Fatal("state was accepted twice")
+    }
+}
+
+func TestOIDCPendingLoginsAreCappedPerClient(t *testing.T) {
+    provider := newTestProvider(t)
+    s, _ := newOIDCTestService(provider, OIDCConfig{})
+    
+    var states []string
+    for i := 0; i <= maxOIDCLoginsPerClient; i++ {
+        _, state, err := s.BeginLogin(context.Background(), "", "ip:192.0.2.1")
+        if err != nil {
+            t.Fatal(err)
+        }
+        states = append(states, state)
+    }
+    _, other, err := s.BeginLogin(context.Background(), "", "ip:192.0.2.2")
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    if len(s.pending) != maxOIDCLoginsPerClient+1 {
+        t.Fatalf("%d logins pending, want %d", len(s.pending), maxOIDCLoginsPerClient+1)
+    }
+    if _, ok := s.pending[states[0]]; ok {
+        t.Fatal("the client's oldest login was kept")
+    }
+    if _, ok := s.pending[states[len(states)-1]]; !ok {
+        t.Fatal("the client's newest login was dropped")
+    }
+    if _, ok := s.pending[other]; !ok {
+        t.Fatal("another client's login was dropped")
+    }
+}
+
+func TestOIDCGroupsMapToTheHighestRole(t *testing.T) {
+    provider := newTestProvider(t,
+        providerUser{Subject: "1", Username: "alice", Groups: []string{"staff", "admins"}},
+        providerUser{Subject: "2", Username: "bob", Groups: []string{"staff"}},
+        providerUser{Subject: "3", Username: "carol", Groups: []string{"visitors"}},
+    )
+    groupRoles := map[string]domain.Role{"admins": domain.RoleOwner, "staff": domain.RoleEditor}
+    s, users := newOIDCTestService(provider, OIDCConfig{GroupRoles: groupRoles, DefaultRole: domain.RoleViewer})
+    
+    for _, tc := range []struct {
+        username string
+        subject  string
+        role     domain.Role
+    }{
+        {"alice", "1", domain.RoleOwner},
+        {"bob", "2", domain.RoleEditor},
+        {"carol", "3", domain.RoleViewer},
+    } {
+        state, _, code := authorize(t, s, tc.username)
+        if _, err := s.CompleteLogin(context.Background(), state, state, code); err != nil {
+            t.Fatalf("%s: %v", tc.username, err)
+        }
+        user, err := users.GetByExternalID("", provider.URL, tc.subject)
+        if err != nil {
+            t.Fatalf("%s: %v", tc.username, err)
+        }
+        if user.Role != tc.role {
+            t.Errorf("%s got role %q, want %q", tc.username, user.Role, tc.role)
+        }
+    }
+    
+    strict, _ := newOIDCTestService(provider, OIDCConfig{GroupRoles: groupRoles})
+    state, _, code := authorize(t, strict, "carol")
+    if _, err := strict.CompleteLogin(context.Background(), state, state, code); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("got %v, want ErrForbidden for a user without a mapped group", err)
+    }
+}
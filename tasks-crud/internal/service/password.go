@@ -0,0 +1,100 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"tasks-crud/internal/domain"
+)
+
+// Argon2id parameters from the OWASP password storage recommendations:
+// 19 MiB of memory, two passes, one lane.
+const (
+    argon2Memory  = 19 * 1024
+    argon2Time    = 2
+    argon2Threads = 1
+    argon2SaltLen = 16
+    argon2KeyLen  = 32
+)
+
+// hashPassword hashes password with algorithm. Argon2id hashes use the PHC
+// string format: $argon2id$v=19$m=...,t=...,p=...$salt$key.
+func hashPassword(algorithm, password string) (string, error) {
+    if algorithm == domain.PasswordHashBcrypt {
+        if len(password) > 72 {
+            return "", fmt.Errorf("password is too long (max 72 bytes with bcrypt)")
+        }
+        hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+        if err != nil {
+            return "", fmt.Errorf("failed to hash password: %w", err)
+        }
+        return string(hash), nil
+    }
+    
+    salt := make([]byte, argon2SaltLen)
+    if _, err := rand.Read(salt); err != nil {
+        return "", fmt.Errorf("failed to hash password: %w", err)
+    }
+    key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+    
+    return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+        argon2.Version, argon2Memory, argon2Time, argon2Threads,
+        base64.RawStdEncoding.EncodeToString(salt),
+        base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// verifyPassword checks password against an argon2id or bcrypt hash.
+// rehash is true when the hash was made with a different algorithm or
+// weaker parameters than algorithm would use today.
+func verifyPassword(algorithm, hash, password string) (ok bool, rehash bool, err error) {
+    if strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$") {
+        err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+        if err == bcrypt.ErrMismatchedHashAndPassword {
+            return false, false, nil
+        }
+        if err != nil {
+            return false, false, err
+        }
+        cost, _ := bcrypt.Cost([]byte(hash))
+        return true, algorithm != domain.PasswordHashBcrypt || cost < bcrypt.DefaultCost, nil
+    }
+    
+    parts := strings.Split(hash, "$")
+    if len(parts) != 6 || parts[1] != "argon2id" {
+        return false, false, fmt.Errorf("unknown password hash format")
+    }
+    
+    var version int
+    if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+        return false, false, fmt.Errorf("unsupported argon2 version")
+    }
+    
+    var memory, passes uint32
+    var threads uint8
+    if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &passes, &threads); err != nil {
+        return false, false, fmt.Errorf("invalid argon2 parameters")
+    }
+    
+    salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+    if err != nil {
+        return false, false, fmt.Errorf("invalid argon2 salt")
+    }
+    key, err := base64.RawStdEncoding.DecodeString(parts[5])
+    if err != nil {
+        return false, false, fmt.Errorf("invalid argon2 key")
+    }
+    
+    candidate := argon2.IDKey([]byte(password), salt, passes, memory, threads, uint32(len(key)))
+    if subtle.ConstantTimeCompare(candidate, key) != 1 {
+        return false, false, nil
+    }
+    
+    weaker := memory < argon2Memory || passes < argon2Time
+    return true, algorithm != domain.PasswordHashArgon2id || weaker, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/patch"
+	"tasks-crud/internal/repository"
+)
+
+func (s *TaskService) MergePatchTask(id int, mergePatch []byte) (*domain.Task, error) {
+    return s.patchTask(id, func(doc []byte) ([]byte, error) {
+        return patch.ApplyMergePatch(doc, mergePatch)
+    })
+}
+
+func (s *TaskService) JSONPatchTask(id int, jsonPatch []byte) (*domain.Task, error) {
+    return s.patchTask(id, func(doc []byte) ([]byte, error) {
+        return patch.ApplyJSONPatch(doc, jsonPatch)
+    })
+}
+
+// patchTask reads, patches and writes the task in one transaction, so
+// concurrent patches can't overwrite each other's changes.
+func (s *TaskService) patchTask(id int, apply func(doc []byte) ([]byte, error)) (*domain.Task, error) {
+    if id <= 0 {
+        return nil, fmt.Errorf("invalid task id: %d", id)
+    }
+    
+    var task *domain.Task
+    err := s.repo.WithinTransaction(func(tx repository.TaskRepository) error {
+        var err error
+        task, err = s.inTransaction(tx).applyPatch(id, apply)
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+    
+    return task, nil
+}
+
+func (s *TaskService) applyPatch(id int, apply func(doc []byte) ([]byte, error)) (*domain.Task, error) {
+    existingTask, err := s.repo.GetByID(id)
+    if err != nil {
+        return nil, fmt.Errorf("task %d %w: %w", id, domain.ErrNotFound, err)
+    }
+    
+    if err := s.authorize(existingTask, domain.RoleEditor); err != nil {
+        return nil, err
+    }
+    
+    doc, err := json.Marshal(existingTask)
+    if err != nil {
+        return nil, fmt.Errorf("failed to encode task: %w", err)
+    }
+    
+    patched, err := apply(doc)
+    if err != nil {
+        return nil, err
+    }
+    
+    var result domain.Task
+    decoder := json.NewDecoder(bytes.NewReader(patched))
+    decoder.DisallowUnknownFields()
+    if err := decoder.Decode(&result); err != nil {
+        return nil, fmt.Errorf("%w: patched task is invalid: %v", patch.ErrInvalidPatch, err)
+    }
+    
+    if err := checkReadOnlyFields(existingTask, &result); err != nil {
+        return nil, err
+    }
+    
+    return s.ReplaceTask(id, domain.ReplaceTaskRequest{
+        Title:     result.Title,
+        Completed: result.Completed,
+        Priority:  result.Priority,
+        Tags:      result.Tags,
+        DueDate:   result.DueDate,
+        ParentID:  result.ParentID,
+    })
+}
+
+func checkReadOnlyFields(existing, patched *domain.Task) error {
+    switch {
+    case patched.ID != existing.ID:
+        return fmt.Errorf("field 'id' is read-only")
+    case !patched.CreatedAt.Equal(existing.CreatedAt):
+        return fmt.Errorf("field 'created_at' is read-only")
+    case !patched.UpdatedAt.Equal(existing.UpdatedAt):
+        return fmt.Errorf("field 'updated_at' is read-only")
+    case !sameTime(patched.CompletedAt, existing.CompletedAt):
+        return fmt.Errorf("field 'completed_at' is read-only")
+    case patched.Revision != existing.Revision:
+        return fmt.Errorf("field 'revision' is read-only")
+    case patched.OwnerID != existing.OwnerID:
+        return fmt.Errorf("field 'owner_id' is read-only")
+    }
+    
+    return nil
+}
+
+func sameTime(a, b *time.Time) bool {
+    if a == nil || b == nil {
+        return a == b
+    }
+    return a.Equal(*b)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/repository"
+)
+
+// Every patch appends a tag; had two of them read the same revision, one
+// tag would be lost.
+func TestConcurrentPatchesKeepEveryChange(t *testing.T) {
+    service := NewTaskService(repository.NewEmptyInMemoryTaskRepository())
+    
+    const rounds, patches = 50, 20
+    for round := 0; round < rounds; round++ {
+        task, err := service.CreateTask(domain.CreateTaskRequest{Title: fmt.Sprintf("patched %d", round)})
+        if err != nil {
+            t.Fatal(err)
+        }
+        
+        var wg sync.WaitGroup
+        start := make(chan struct{})
+        for i := 0; i < patches; i++ {
+            wg.Add(1)
+            go func(i int) {
+                defer wg.Done()
+                <-start
+                patch := fmt.Sprintf(`[{"op":"add","path":"/tags/-","value":"t%d"}]`, i)
+                if _, err := service.JSONPatchTask(task.ID, []byte(patch)); err != nil {
+                    t.Error(err)
+                }
+            }(i)
+        }
+        close(start)
+        wg.Wait()
+        
+        patched, err := service.GetTaskByID(task.ID)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if len(patched.Tags) != patches {
+            t.Fatalf("round %d: got %d tags, want %d: %v", round, len(patched.Tags), patches, patched.Tags)
+        }
+    }
+}
+
+func TestPatchFailureLeavesTaskUnchanged(t *testing.T) {
+    service := NewTaskService(repository.NewEmptyInMemoryTaskRepository())
+    task, err := service.CreateTask(domain.CreateTaskRequest{Title: "kept"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    if _, err := service.JSONPatchTask(task.ID, []byte(`[{"op":"replace","path":"/title","value":"changed"},{"op":"test","path":"/priority","value":3}]`)); err == nil {
+        t.Fatal("patch with a failing test op succeeded")
+    }
+    if _, err := service.MergePatchTask(task.ID, []byte(`{"revision":99}`)); err == nil {
+        t.Fatal("patch of a read-only field succeeded")
+    }
+    
+    current, err := service.GetTaskByID(task.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if current.Title != "kept" || current.Revision != task.Revision {
+        t.Fatalf("task changed: %+v", current)
+    }
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"tasks-crud/internal/domain"
+)
+
+const (
+    defaultSearchLimit = 20
+    maxSearchLimit     = 100
+    maxQueryLength     = 200
+)
+
+func (s *TaskService) SearchTasks(query string, limit int) (*domain.SearchResponse, error) {
+    query = strings.TrimSpace(query)
+    if query == "" {
+        return nil, fmt.Errorf("query is required")
+    }
+    
+    if len(query) > maxQueryLength {
+        return nil, fmt.Errorf("query is too long (max %d characters)", maxQueryLength)
+    }
+    
+    if limit <= 0 {
+        limit = defaultSearchLimit
+    }
+    if limit > maxSearchLimit {
+        limit = maxSearchLimit
+    }
+    
+    results, err := s.repo.Search(query, limit, nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to search tasks: %w", err)
+    }
+    
+    return &domain.SearchResponse{
+        Query:   query,
+        Total:   len(results),
+        Results: results,
+    }, nil
+}
\ No newline at end of file
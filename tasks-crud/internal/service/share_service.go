@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/repository"
+)
+
+type ShareService struct {
+    shares repository.ShareRepository
+    tasks  *TaskService
+    users  repository.UserRepository
+    owner  string
+    tenant string
+}
+
+// NewShareService manages shares of the tasks in tasks. users resolves
+// usernames of local accounts and may be nil.
+func NewShareService(shares repository.ShareRepository, tasks *TaskService, users repository.UserRepository) *ShareService {
+    return &ShareService{
+        shares: shares,
+        tasks:  tasks,
+        users:  users,
+    }
+}
+
+// WithContext acts for the principal in ctx, within its tenant.
+func (s *ShareService) WithContext(ctx context.Context) *ShareService {
+    scoped := &ShareService{
+        shares: s.shares,
+        tasks:  s.tasks.WithContext(ctx),
+        users:  s.users,
+        tenant: domain.TenantFromContext(ctx),
+    }
+    if data := repository.TenantDataFromContext(ctx); data != nil {
+        scoped.shares = data.Shares
+    }
+    if principal := domain.PrincipalFromContext(ctx); principal != nil {
+        scoped.owner = principal.Owner()
+    }
+    
+    return scoped
+}
+
+// GetTaskShares lists who a task is shared with. Only users with the owner
+// role on the task may see it.
+func (s *ShareService) GetTaskShares(taskID int) ([]domain.Share, error) {
+    task, err := s.ownedTask(taskID)
+    if err != nil {
+        return nil, err
+    }
+    
+    return s.find(func(share domain.Share) bool {
+        return share.OwnerID == task.OwnerID && share.TaskID != nil && *share.TaskID == task.ID
+    })
+}
+
+// ShareTask grants a role on one task; sharing it again with the same user
+// changes the role.
+func (s *ShareService) ShareTask(taskID int, req domain.CreateShareRequest) (*domain.Share, error) {
+    task, err := s.ownedTask(taskID)
+    if err != nil {
+        return nil, err
+    }
+    
+    share, err := s.newShare(task.OwnerID, req)
+    if err != nil {
+        return nil, err
+    }
+    share.TaskID = &task.ID
+    
+    if err := s.shares.Save(share); err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to share task: %w", err))
+    }
+    
+    return share, nil
+}
+
+func (s *ShareService) UnshareTask(taskID int, grantee string) error {
+    task, err := s.ownedTask(taskID)
+    if err != nil {
+        return err
+    }
+    
+    return s.remove(func(share domain.Share) bool {
+        return share.OwnerID == task.OwnerID && share.GranteeID == grantee && share.TaskID != nil && *share.TaskID == task.ID
+    })
+}
+
+// GetProjectShares lists who the caller's project is shared with.
+func (s *ShareService) GetProjectShares(project string) ([]domain.Share, error) {
+    project, err := s.checkProject(project)
+    if err != nil {
+        return nil, err
+    }
+    
+    return s.find(func(share domain.Share) bool {
+        return share.OwnerID == s.owner && share.Project == project
+    })
+}
+
+// ShareProject grants a role on every task of the caller whose project
+// (first tag) is project, including tasks added to it later.
+func (s *ShareService) ShareProject(project string, req domain.CreateShareRequest) (*domain.Share, error) {
+    project, err := s.checkProject(project)
+    if err != nil {
+        return nil, err
+    }
+    
+    share, err := s.newShare(s.owner, req)
+    if err != nil {
+        return nil, err
+    }
+    share.Project = project
+    
+    if err := s.shares.Save(share); err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to share project: %w", err))
+    }
+    
+    return share, nil
+}
+
+func (s *ShareService) UnshareProject(project, grantee string) error {
+    project, err := s.checkProject(project)
+    if err != nil {
+        return err
+    }
+    
+    return s.remove(func(share domain.Share) bool {
+        return share.OwnerID == s.owner && share.GranteeID == grantee && share.Project == project
+    })
+}
+
+// GetSharedWithMe lists the shares granted to the caller.
+func (s *ShareService) GetSharedWithMe() ([]domain.Share, error) {
+    if s.owner == "" {
+        return nil, fmt.Errorf("sharing requires an authenticated user")
+    }
+    
+    shares, err := s.shares.GetByGrantee(s.owner)
+    if err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to get shares: %w", err))
+    }
+    
+    return shares, nil
+}
+
+func (s *ShareService) ownedTask(taskID int) (*domain.Task, error) {
+    if s.owner == "" {
+        return nil, fmt.Errorf("sharing requires an authenticated user")
+    }
+    
+    task, err := s.tasks.GetTaskByID(taskID)
+    if err != nil {
+        return nil, err
+    }
+    
+    if err := s.tasks.authorize(task, domain.RoleOwner); err != nil {
+        return nil, err
+    }
+    
+    return task, nil
+}
+
+func (s *ShareService) checkProject(project string) (string, error) {
+    if s.owner == "" {
+        return "", fmt.Errorf("sharing requires an authenticated user")
+    }
+    
+    project = strings.ToLower(strings.TrimSpace(project))
+    if project == "" {
+        return "", fmt.Errorf("project is required")
+    }
+    
+    return project, nil
+}
+
+func (s *ShareService) newShare(owner string, req domain.CreateShareRequest) (*domain.Share, error) {
+    if !req.Role.Valid() {
+        return nil, fmt.Errorf("role must be owner, editor or viewer")
+    }
+    
+    grantee := strings.TrimSpace(req.GranteeID)
+    if req.Username != "" {
+        if s.users == nil {
+            return nil, fmt.Errorf("usernames are not supported, use grantee_id")
+        }
+        user, err := s.users.GetByUsername(s.tenant, strings.ToLower(strings.TrimSpace(req.Username)))
+        if err != nil {
+            return nil, fmt.Errorf("user '%s' %w", req.Username, domain.ErrNotFound)
+        }
+        grantee = strconv.Itoa(user.ID)
+    }
+    
+    if grantee == "" {
+        return nil, fmt.Errorf("grantee_id or username is required")
+    }
+    
+    if grantee == owner {
+        return nil, fmt.Errorf("can't share with the owner")
+    }
+    
+    return &domain.Share{
+        OwnerID:   owner,
+        GranteeID: grantee,
+        Role:      req.Role,
+    }, nil
+}
+
+func (s *ShareService) find(keep func(domain.Share) bool) ([]domain.Share, error) {
+    shares, err := s.shares.GetAll()
+    if err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to get shares: %w", err))
+    }
+    
+    found := make([]domain.Share, 0)
+    for _, share := range shares {
+        if keep(share) {
+            found = append(found, share)
+        }
+    }
+    
+    return found, nil
+}
+
+func (s *ShareService) remove(match func(domain.Share) bool) error {
+    shares, err := s.find(match)
+    if err != nil {
+        return err
+    }
+    
+    if len(shares) == 0 {
+        return fmt.Errorf("share %w", domain.ErrNotFound)
+    }
+    
+    for _, share := range shares {
+        if err := s.shares.Delete(share.ID); err != nil {
+            return domain.Internal(fmt.Errorf("failed to delete share: %w", err))
+        }
+        // The grantee's sync clients learn from this which tasks to drop.
+        if err := s.tasks.repo.Revoke(share); err != nil {
+            return domain.Internal(fmt.Errorf("failed to record revoked share: %w", err))
+        }
+    }
+    
+    return nil
+}
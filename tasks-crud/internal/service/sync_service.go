@@ -0,0 +1,193 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/repository"
+)
+
+var errSyncNotApplied = errors.New("sync change not applied")
+
+func (s *TaskService) GetChangesSince(token string) (*domain.SyncResponse, error) {
+    since, err := parseSyncToken(token)
+    if err != nil {
+        return nil, err
+    }
+
+    tasks, tombstones, revision, err := s.repo.ChangesSince(since)
+    if err != nil {
+        return nil, fmt.Errorf("invalid sync token: %w", err)
+    }
+
+    return &domain.SyncResponse{
+        Token:   formatSyncToken(revision),
+        Tasks:   tasks,
+        Deleted: tombstones,
+    }, nil
+}
+
+func (s *TaskService) ApplySyncChanges(req domain.SyncPushRequest) (*domain.SyncPushResponse, error) {
+    if len(req.Changes) == 0 {
+        return nil, fmt.Errorf("changes are required")
+    }
+
+    results := make([]domain.SyncChangeResult, 0, len(req.Changes))
+    for i, change := range req.Changes {
+        result := s.applySyncChange(change)
+        result.Index = i
+        results = append(results, result)
+    }
+
+    revision, err := s.repo.CurrentRevision()
+    if err != nil {
+        return nil, fmt.Errorf("failed to get current revision: %w", err)
+    }
+
+    return &domain.SyncPushResponse{
+        Token:   formatSyncToken(revision),
+        Results: results,
+    }, nil
+}
+
+// applySyncChange applies one change in its own transaction, so the
+// revision check can't be overtaken by a concurrent write and a change
+// that is not applied leaves nothing behind.
+func (s *TaskService) applySyncChange(change domain.SyncChange) domain.SyncChangeResult {
+    var result domain.SyncChangeResult
+    err := s.repo.WithinTransaction(func(tx repository.TaskRepository) error {
+        result = s.inTransaction(tx).applySyncOp(change)
+        if result.Status != domain.SyncStatusApplied {
+            return errSyncNotApplied
+        }
+        return nil
+    })
+    if err != nil && !errors.Is(err, errSyncNotApplied) {
+        return syncRejected(fmt.Errorf("failed to apply change: %w", err))
+    }
+
+    return result
+}
+
+func (s *TaskService) applySyncOp(change domain.SyncChange) domain.SyncChangeResult {
+    switch change.Op {
+    case domain.SyncOpCreate:
+        return s.applySyncCreate(change)
+    case domain.SyncOpUpdate:
+        return s.applySyncUpdate(change)
+    case domain.SyncOpDelete:
+        return s.applySyncDelete(change)
+    default:
+        return syncRejected(fmt.Errorf("unknown op '%s'", change.Op))
+    }
+}
+
+func (s *TaskService) applySyncCreate(change domain.SyncChange) domain.SyncChangeResult {
+    if change.Title == nil {
+        return syncRejected(fmt.Errorf("title is required"))
+    }
+
+    task, err := s.CreateTask(domain.CreateTaskRequest{Title: *change.Title})
+    if err != nil {
+        return syncRejected(err)
+    }
+
+    if change.Completed != nil && *change.Completed {
+        task, err = s.UpdateTask(task.ID, domain.UpdateTaskRequest{Completed: change.Completed})
+        if err != nil {
+            return syncRejected(err)
+        }
+    }
+
+    return domain.SyncChangeResult{Status: domain.SyncStatusApplied, Task: task}
+}
+
+func (s *TaskService) applySyncUpdate(change domain.SyncChange) domain.SyncChangeResult {
+    if change.ID <= 0 {
+        return syncRejected(fmt.Errorf("invalid task id: %d", change.ID))
+    }
+
+    current, err := s.repo.GetByID(change.ID)
+    if err != nil {
+        return syncDeletedOnServer(change.ID)
+    }
+
+    if current.Revision != change.BaseRevision {
+        return syncConflict(current)
+    }
+
+    task, err := s.UpdateTask(change.ID, domain.UpdateTaskRequest{
+        Title:     change.Title,
+        Completed: change.Completed,
+    })
+    if err != nil {
+        return syncRejected(err)
+    }
+
+    return domain.SyncChangeResult{Status: domain.SyncStatusApplied, Task: task}
+}
+
+func (s *TaskService) applySyncDelete(change domain.SyncChange) domain.SyncChangeResult {
+    if change.ID <= 0 {
+        return syncRejected(fmt.Errorf("invalid task id: %d", change.ID))
+    }
+
+    current, err := s.repo.GetByID(change.ID)
+    if err != nil {
+        return domain.SyncChangeResult{Status: domain.SyncStatusApplied, Deleted: true}
+    }
+
+    if current.Revision != change.BaseRevision {
+        return syncConflict(current)
+    }
+
+    if err := s.DeleteTask(change.ID); err != nil {
+        return syncRejected(err)
+    }
+
+    return domain.SyncChangeResult{Status: domain.SyncStatusApplied, Deleted: true}
+}
+
+func syncRejected(err error) domain.SyncChangeResult {
+    return domain.SyncChangeResult{
+        Status: domain.SyncStatusRejected,
+        Error:  err.Error(),
+    }
+}
+
+func syncConflict(current *domain.Task) domain.SyncChangeResult {
+    return domain.SyncChangeResult{
+        Status: domain.SyncStatusConflict,
+        Task:   current,
+        Error:  fmt.Sprintf("task %d was modified on server (revision %d)", current.ID, current.Revision),
+    }
+}
+
+func syncDeletedOnServer(id int) domain.SyncChangeResult {
+    return domain.SyncChangeResult{
+        Status:  domain.SyncStatusConflict,
+        Deleted: true,
+        Error:   fmt.Sprintf("task %d not found on server", id),
+    }
+}
+
+func parseSyncToken(token string) (int64, error) {
+    token = strings.TrimSpace(token)
+    if token == "" {
+        return 0, nil
+    }
+
+    revision, err := strconv.ParseInt(token, 10, 64)
+    if err != nil || revision < 0 {
+        return 0, fmt.Errorf("invalid sync token '%s'", token)
+    }
+
+    return revision, nil
+}
+
+func formatSyncToken(revision int64) string {
+    return strconv.FormatInt(revision, 10)
+}
\ No newline at end of file
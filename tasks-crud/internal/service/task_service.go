@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"iter"
 	"strings"
 
 	"tasks-crud/internal/domain"
@@ -9,7 +11,10 @@ import (
 )
 
 type TaskService struct {
-    repo repository.TaskRepository  
+    repo   repository.TaskRepository
+    shares repository.ShareRepository
+    scope  *repository.OwnedTaskRepository
+    quotas domain.Quotas
 }
 
 func NewTaskService(repo repository.TaskRepository) *TaskService {
@@ -18,10 +23,128 @@ func NewTaskService(repo repository.TaskRepository) *TaskService {
     }
 }
 
+// WithShares returns a service whose scoped copies (see ForOwner) also see
+// the tasks shared with their owner.
+func (s *TaskService) WithShares(shares repository.ShareRepository) *TaskService {
+    return &TaskService{
+        repo:   s.repo,
+        shares: shares,
+        scope:  s.scope,
+        quotas: s.quotas,
+    }
+}
+
+// ForOwner returns a service acting for owner: it sees owner's tasks and
+// those shared with owner, creates tasks owned by owner and checks owner's
+// role before every change.
+func (s *TaskService) ForOwner(owner string) *TaskService {
+    scope := repository.NewOwnedTaskRepository(s.repo, owner, s.shares)
+    return &TaskService{
+        repo:   scope,
+        shares: s.shares,
+        scope:  scope,
+        quotas: s.quotas,
+    }
+}
+
+// WithQuotas returns a service that enforces quotas in CreateTask.
+func (s *TaskService) WithQuotas(quotas domain.Quotas) *TaskService {
+    return &TaskService{
+        repo:   s.repo,
+        shares: s.shares,
+        scope:  s.scope,
+        quotas: quotas,
+    }
+}
+
+// ForPrincipal is ForOwner for an authenticated caller, who in addition
+// can't do more on any task than their role (if any) allows.
+func (s *TaskService) ForPrincipal(principal *domain.Principal) *TaskService {
+    scope := repository.NewOwnedTaskRepository(s.repo, principal.Owner(), s.shares).Limit(principal.Role)
+    return &TaskService{
+        repo:   scope,
+        shares: s.shares,
+        scope:  scope,
+        quotas: s.quotas,
+    }
+}
+
+// ForTenant returns a service working on the given tenant's stores.
+func (s *TaskService) ForTenant(data *repository.TenantData) *TaskService {
+    return &TaskService{
+        repo:   data.Tasks,
+        shares: data.Shares,
+        quotas: s.quotas,
+    }
+}
+
+// WithContext scopes the service to the tenant and the principal the
+// middlewares put into ctx. Without a principal (authentication disabled)
+// all tasks of the tenant are visible.
+func (s *TaskService) WithContext(ctx context.Context) *TaskService {
+    if data := repository.TenantDataFromContext(ctx); data != nil {
+        s = s.ForTenant(data)
+    }
+    
+    principal := domain.PrincipalFromContext(ctx)
+    if principal == nil {
+        return s
+    }
+    return s.ForPrincipal(principal)
+}
+
+// inTransaction returns a service working on tx with the same access
+// checks as s.
+func (s *TaskService) inTransaction(tx repository.TaskRepository) *TaskService {
+    return &TaskService{
+        repo:   tx,
+        shares: s.shares,
+        scope:  s.scope,
+        quotas: s.quotas,
+    }
+}
+
+// WithinTransaction runs fn with a service working on a single
+// transaction: the changes fn makes are kept only if it returns nil, and no
+// other change can come in between its reads and writes.
+func (s *TaskService) WithinTransaction(fn func(tx *TaskService) error) error {
+    return s.repo.WithinTransaction(func(tx repository.TaskRepository) error {
+        return fn(s.inTransaction(tx))
+    })
+}
+
+// Owner returns the owner the service acts for, or "" if it isn't scoped
+// to one.
+func (s *TaskService) Owner() string {
+    if s.scope == nil {
+        return ""
+    }
+    return s.scope.Owner()
+}
+
+// authorize checks that the caller holds at least role on task. A task the
+// caller can't see at all is reported as not found, one they can see but
+// not change as domain.ErrForbidden.
+func (s *TaskService) authorize(task *domain.Task, role domain.Role) error {
+    if s.scope == nil {
+        return nil
+    }
+    
+    granted := s.scope.Role(*task)
+    if granted == "" {
+        return fmt.Errorf("task %d %w", task.ID, domain.ErrNotFound)
+    }
+    if !granted.Allows(role) {
+        return fmt.Errorf("%w: %s role required for task %d", domain.ErrForbidden, role, task.ID)
+    }
+    
+    return nil
+}
+
 func (s *TaskService) GetAllTasks() ([]domain.Task, error) {
     tasks, err := s.repo.GetAll()
     if err != nil {
-        return nil, fmt.Errorf("failed to get tasks: %w", err)
+        return nil, domain.Internal(fmt.Errorf("failed to get tasks: %w", err))
     }
     
     return tasks, nil
@@ -34,7 +157,7 @@ func (s *TaskService) GetTaskByID(id int) (*domain.Task, error) {
     
     task, err := s.repo.GetByID(id)
     if err != nil {
-        return nil, fmt.Errorf("task not found")
+        return nil, fmt.Errorf("task %w", domain.ErrNotFound)
     }
     
     return task, nil
@@ -45,17 +168,37 @@ func (s *TaskService) CreateTask(req domain.CreateTaskRequest) (*domain.Task, er
         return nil, err
     }
     
+    if s.scope != nil && !s.scope.CanCreate() {
+        return nil, fmt.Errorf("%w: %s role required to create tasks", domain.ErrForbidden, domain.RoleEditor)
+    }
+    
     task := &domain.Task{
         Title:     strings.TrimSpace(req.Title), 
         Completed: false,
+        Priority:  req.Priority,
+        Tags:      normalizeTags(req.Tags),
+        DueDate:   req.DueDate,
+        ParentID:  normalizeParentID(req.ParentID),
+    }
+    
+    if err := s.validateParent(0, task.ParentID); err != nil {
+        return nil, err
     }
 
-    if isDuplicate, err := s.isDuplicateTitle(task.Title); err == nil && isDuplicate {
-        return nil, fmt.Errorf("task with title '%s' already exists", task.Title)
+    // The checks run under the repository's lock with the insert, so
+    // concurrent creates can't all pass the quota or the duplicate check
+    // before any is stored.
+    _, owner := s.unscoped()
+    var refused error
+    err := s.repo.CreateIf(task, func(stored iter.Seq[domain.Task]) error {
+        refused = s.checkCreate(task.Title, owner, stored)
+        return refused
+    })
+    if refused != nil {
+        return nil, refused
     }
-    
-    if err := s.repo.Create(task); err != nil {
-        return nil, fmt.Errorf("failed to create task: %w", err)
+    if err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to create task: %w", err))
     }
     
     return task, nil
@@ -72,7 +215,11 @@ func (s *TaskService) UpdateTask(id int, req domain.UpdateTaskRequest) (*domain.
     
     existingTask, err := s.repo.GetByID(id)
     if err != nil {
-        return nil, fmt.Errorf("task %d not found: %w", id, err)
+        return nil, fmt.Errorf("task %d %w: %w", id, domain.ErrNotFound, err)
+    }
+    
+    if err := s.authorize(existingTask, domain.RoleEditor); err != nil {
+        return nil, err
     }
     
     updatedTask := *existingTask  
@@ -89,8 +236,64 @@ func (s *TaskService) UpdateTask(id int, req domain.UpdateTaskRequest) (*domain.
         updatedTask.Completed = *req.Completed
     }
     
+    if req.Priority != nil {
+        updatedTask.Priority = *req.Priority
+    }
+    
+    if req.Tags != nil {
+        updatedTask.Tags = normalizeTags(req.Tags)
+    }
+    
+    if req.DueDate != nil {
+        updatedTask.DueDate = req.DueDate
+    }
+    
+    if req.ParentID != nil {
+        updatedTask.ParentID = normalizeParentID(req.ParentID)
+        if err := s.validateParent(id, updatedTask.ParentID); err != nil {
+            return nil, err
+        }
+    }
+    
     if err := s.repo.Update(id, &updatedTask); err != nil {
-        return nil, fmt.Errorf("failed to update task: %w", err)
+        return nil, domain.Internal(fmt.Errorf("failed to update task: %w", err))
+    }
+    
+    return &updatedTask, nil
+}
+
+func (s *TaskService) ReplaceTask(id int, req domain.ReplaceTaskRequest) (*domain.Task, error) {
+    if id <= 0 {
+        return nil, fmt.Errorf("invalid task id: %d", id)
+    }
+    
+    if err := validateUpdateRequest(domain.UpdateTaskRequest{Title: &req.Title, Priority: &req.Priority, Tags: req.Tags}); err != nil {
+        return nil, err
+    }
+    
+    existingTask, err := s.repo.GetByID(id)
+    if err != nil {
+        return nil, fmt.Errorf("task %d %w: %w", id, domain.ErrNotFound, err)
+    }
+    
+    if err := s.authorize(existingTask, domain.RoleEditor); err != nil {
+        return nil, err
+    }
+    
+    updatedTask := *existingTask
+    updatedTask.Title = strings.TrimSpace(req.Title)
+    updatedTask.Completed = req.Completed
+    updatedTask.Priority = req.Priority
+    updatedTask.Tags = normalizeTags(req.Tags)
+    updatedTask.DueDate = req.DueDate
+    updatedTask.ParentID = normalizeParentID(req.ParentID)
+    
+    if err := s.validateParent(id, updatedTask.ParentID); err != nil {
+        return nil, err
+    }
+    
+    if err := s.repo.Update(id, &updatedTask); err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to update task: %w", err))
     }
     
     return &updatedTask, nil
@@ -101,12 +304,28 @@ func (s *TaskService) DeleteTask(id int) error {
         return fmt.Errorf("invalid task id: %d", id)
     }
     
-    if _, err := s.repo.GetByID(id); err != nil {
-        return fmt.Errorf("task %d not found: %w", id, err)
+    existingTask, err := s.repo.GetByID(id)
+    if err != nil {
+        return fmt.Errorf("task %d %w: %w", id, domain.ErrNotFound, err)
+    }
+    
+    if err := s.authorize(existingTask, domain.RoleOwner); err != nil {
+        return err
+    }
+    
+    subtasks, err := s.GetSubtasks(id)
+    if err != nil {
+        return err
+    }
+    for _, subtask := range subtasks {
+        subtask.ParentID = nil
+        if err := s.repo.Update(subtask.ID, &subtask); err != nil {
+            return domain.Internal(fmt.Errorf("failed to detach subtask %d: %w", subtask.ID, err))
+        }
     }
 
     if err := s.repo.Delete(id); err != nil {
-        return fmt.Errorf("failed to delete task: %w", err)
+        return domain.Internal(fmt.Errorf("failed to delete task: %w", err))
     }
     
     fmt.Printf("Task %d deleted\n", id)
@@ -114,6 +333,93 @@ func (s *TaskService) DeleteTask(id int) error {
     return nil
 }
 
+func (s *TaskService) GetSubtasks(id int) ([]domain.Task, error) {
+    tasks, err := s.repo.List(domain.TaskFilter{})
+    if err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to get subtasks: %w", err))
+    }
+    
+    subtasks := make([]domain.Task, 0)
+    for _, task := range tasks {
+        if task.ParentID != nil && *task.ParentID == id {
+            subtasks = append(subtasks, task)
+        }
+    }
+    
+    return subtasks, nil
+}
+
+// GetSubtasksByParent groups the visible subtasks by parent ID, so the
+// subtasks of many tasks can be looked up with one listing.
+func (s *TaskService) GetSubtasksByParent() (map[int][]domain.Task, error) {
+    tasks, err := s.repo.List(domain.TaskFilter{})
+    if err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to get subtasks: %w", err))
+    }
+    
+    subtasks := make(map[int][]domain.Task)
+    for _, task := range tasks {
+        if task.ParentID != nil {
+            subtasks[*task.ParentID] = append(subtasks[*task.ParentID], task)
+        }
+    }
+    
+    return subtasks, nil
+}
+
+func (s *TaskService) GetTaskHistory(id int) ([]domain.TaskEvent, error) {
+    if id <= 0 {
+        return nil, fmt.Errorf("invalid task id")
+    }
+    
+    history, err := s.repo.History(id)
+    if err != nil {
+        return nil, fmt.Errorf("task %d %w: %w", id, domain.ErrNotFound, err)
+    }
+    
+    return history, nil
+}
+
+func (s *TaskService) WatchTasks(ctx context.Context) (<-chan domain.TaskEvent, error) {
+    events, err := s.repo.Watch(ctx)
+    if err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to watch tasks: %w", err))
+    }
+    
+    return events, nil
+}
+
+func (s *TaskService) validateParent(id int, parentID *int) error {
+    if parentID == nil {
+        return nil
+    }
+    
+    for current, depth := *parentID, 0; ; depth++ {
+        if current == id {
+            return fmt.Errorf("task cannot be a subtask of itself")
+        }
+        if depth > 100 {
+            return fmt.Errorf("subtask nesting is too deep")
+        }
+        
+        parent, err := s.repo.GetByID(current)
+        if err != nil {
+            return fmt.Errorf("parent task %d does not exist", *parentID)
+        }
+        if parent.ParentID == nil {
+            return nil
+        }
+        current = *parent.ParentID
+    }
+}
+
+func normalizeParentID(parentID *int) *int {
+    if parentID == nil || *parentID == 0 {
+        return nil
+    }
+    return parentID
+}
+
 func validateCreateRequest(req domain.CreateTaskRequest) error {
     if strings.TrimSpace(req.Title) == "" {
         return fmt.Errorf("title is required")
@@ -123,7 +429,11 @@ func validateCreateRequest(req domain.CreateTaskRequest) error {
         return fmt.Errorf("title is too long (max 200 characters)")
     }
     
-    return nil
+    if err := validatePriority(req.Priority); err != nil {
+        return err
+    }
+    
+    return validateTags(req.Tags)
 }
 
 func validateUpdateRequest(req domain.UpdateTaskRequest) error {
@@ -137,21 +447,114 @@ func validateUpdateRequest(req domain.UpdateTaskRequest) error {
         }
     }
     
+    if req.Priority != nil {
+        if err := validatePriority(*req.Priority); err != nil {
+            return err
+        }
+    }
+    
+    return validateTags(req.Tags)
+}
+
+func validatePriority(priority int) error {
+    if priority < domain.PriorityNone || priority > domain.PriorityHigh {
+        return fmt.Errorf("priority must be between %d and %d", domain.PriorityNone, domain.PriorityHigh)
+    }
+    
     return nil
 }
 
+func validateTags(tags []string) error {
+    if len(tags) > 20 {
+        return fmt.Errorf("too many tags (max 20)")
+    }
+    
+    for _, tag := range normalizeTags(tags) {
+        if len(tag) > 50 {
+            return fmt.Errorf("tag '%s' is too long (max 50 characters)", tag)
+        }
+        if strings.ContainsAny(tag, " \t,:\"") {
+            return fmt.Errorf("tag '%s' contains invalid characters", tag)
+        }
+    }
+    
+    return nil
+}
+
+func normalizeTags(tags []string) []string {
+    normalized := make([]string, 0, len(tags))
+    seen := make(map[string]bool)
+    
+    for _, tag := range tags {
+        tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+        if tag == "" || seen[tag] {
+            continue
+        }
+        seen[tag] = true
+        normalized = append(normalized, tag)
+    }
+    
+    return normalized
+}
+
+// isDuplicateTitle checks every task of the tenant, including those the
+// caller can't see: titles are unique per tenant.
 func (s *TaskService) isDuplicateTitle(title string) (bool, error) {
-    tasks, err := s.repo.GetAll()
+    repo, _ := s.unscoped()
+    tasks, err := repo.GetAll()
     if err != nil {
         return false, err
     }
     
-    cleanTitle := strings.ToLower(strings.TrimSpace(title))
     for _, task := range tasks {
-        if strings.ToLower(strings.TrimSpace(task.Title)) == cleanTitle {
+        if sameTitle(task.Title, title) {
             return true, nil
         }
     }
     
     return false, nil
-}
\ No newline at end of file
+}
+
+// sameTitle compares titles the way they must be unique: ignoring case and
+// surrounding spaces.
+func sameTitle(a, b string) bool {
+    return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+// unscoped returns the repository behind the caller's scope, inside the
+// current transaction if any, and the caller ("" without a scope).
+func (s *TaskService) unscoped() (repository.TaskRepository, string) {
+    if owned, ok := s.repo.(*repository.OwnedTaskRepository); ok {
+        return owned.Unscoped(), owned.Owner()
+    }
+    return s.repo, ""
+}
+
+// checkCreate fails with domain.ErrAlreadyExists if a stored task has
+// title already, whoever owns it, and with domain.ErrQuotaExceeded when
+// one more task would go over owner's or the tenant's task quota.
+func (s *TaskService) checkCreate(title, owner string, stored iter.Seq[domain.Task]) error {
+    total, owned, duplicate := 0, 0, false
+    for task := range stored {
+        total++
+        if owner != "" && task.OwnerID == owner {
+            owned++
+        }
+        duplicate = duplicate || sameTitle(task.Title, title)
+    }
+    
+    if duplicate {
+        // Says nothing of the other task, which may be someone else's.
+        return fmt.Errorf("a task with this title %w", domain.ErrAlreadyExists)
+    }
+    
+    if limit := s.quotas.MaxTasksPerTenant; limit > 0 && total >= limit {
+        return fmt.Errorf("%w: the limit is %d tasks in total", domain.ErrQuotaExceeded, limit)
+    }
+    
+    if limit := s.quotas.MaxTasksPerUser; limit > 0 && owned >= limit {
+        return fmt.Errorf("%w: the limit is %d tasks per user", domain.ErrQuotaExceeded, limit)
+    }
+    
+    return nil
+}
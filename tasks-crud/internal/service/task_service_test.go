@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/repository"
+)
+
+// Titles are unique per tenant, so a title another user has is refused,
+// but without telling whose task has it.
+func TestDuplicateTitlesAreCheckedAcrossTheTenant(t *testing.T) {
+    tenant := NewTaskService(repository.NewEmptyInMemoryTaskRepository()).ForTenant(repository.NewTenantData())
+    alice, bob := tenant.ForOwner("alice"), tenant.ForOwner("bob")
+    
+    task, err := alice.CreateTask(domain.CreateTaskRequest{Title: "Salary review"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    _, err = bob.CreateTask(domain.CreateTaskRequest{Title: "salary review"})
+    if !errors.Is(err, domain.ErrAlreadyExists) {
+        t.Fatalf("bob got %v, want ErrAlreadyExists for a title alice has", err)
+    }
+    if strings.Contains(err.Error(), "alice") || strings.Contains(err.Error(), strconv.Itoa(task.ID)) {
+        t.Errorf("the error %q tells bob about alice's task", err)
+    }
+    if _, err := alice.CreateTask(domain.CreateTaskRequest{Title: "Salary Review"}); err == nil {
+        t.Fatal("alice could create the same title twice")
+    }
+    
+    other := NewTaskService(repository.NewEmptyInMemoryTaskRepository()).ForTenant(repository.NewTenantData()).ForOwner("bob")
+    if _, err := other.CreateTask(domain.CreateTaskRequest{Title: "salary review"}); err != nil {
+        t.Fatalf("a title of another tenant was refused: %v", err)
+    }
+}
+
+func TestDeleteTenantRunsCleanups(t *testing.T) {
+    tenants := repository.NewInMemoryTenantRepository()
+    var cleaned []*repository.TenantData
+    service := NewTenantService(tenants, nil, nil, nil).WithCleanup(func(data *repository.TenantData) {
+        cleaned = append(cleaned, data)
+    })
+    
+    if _, err := service.CreateTenant(domain.CreateTenantRequest{ID: "acme"}); err != nil {
+        t.Fatal(err)
+    }
+    data, err := tenants.Data("acme")
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    if err := service.DeleteTenant("acme"); err != nil {
+        t.Fatal(err)
+    }
+    if len(cleaned) != 1 || cleaned[0] != data {
+        t.Fatalf("cleanup got %v, want the deleted tenant's stores", cleaned)
+    }
+}
+
+func TestConcurrentCreatesDontExceedQuota(t *testing.T) {
+    const limit = 5
+    alice := NewTaskService(repository.NewEmptyInMemoryTaskRepository()).
+        WithQuotas(domain.Quotas{MaxTasksPerUser: limit}).
+        ForTenant(repository.NewTenantData()).
+        ForOwner("alice")
+    
+    var wg sync.WaitGroup
+    for i := 0; i < 50; i++ {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+            alice.CreateTask(domain.CreateTaskRequest{Title: fmt.Sprintf("task %d", i)})
+        }(i)
+    }
+    wg.Wait()
+    
+    tasks, err := alice.GetAllTasks()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(tasks) != limit {
+        t.Fatalf("%d tasks created, want the quota of %d", len(tasks), limit)
+    }
+}
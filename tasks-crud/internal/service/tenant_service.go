@@ -0,0 +1,188 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/repository"
+)
+
+const (
+    minTenantIDLength = 2
+    maxTenantIDLength = 32
+)
+
+// TenantService manages tenants. Deleting a tenant also removes its
+// accounts, their sessions and API keys.
+type TenantService struct {
+    tenants  repository.TenantRepository
+    users    repository.UserRepository
+    sessions repository.SessionRepository
+    apiKeys  repository.APIKeyRepository
+    cleanups []func(data *repository.TenantData)
+}
+
+// NewTenantService manages tenants in tenants. users with their sessions,
+// and apiKeys are cleaned up on deletion; both may be nil when accounts
+// are disabled.
+func NewTenantService(tenants repository.TenantRepository, users repository.UserRepository, sessions repository.SessionRepository, apiKeys repository.APIKeyRepository) *TenantService {
+    return &TenantService{
+        tenants:  tenants,
+        users:    users,
+        sessions: sessions,
+        apiKeys:  apiKeys,
+    }
+}
+
+// WithCleanup returns a service that also calls cleanup with the stores
+// of every tenant it deletes, so that whatever was kept for them elsewhere
+// can be dropped.
+func (s *TenantService) WithCleanup(cleanup func(data *repository.TenantData)) *TenantService {
+    return &TenantService{
+        tenants:  s.tenants,
+        users:    s.users,
+        sessions: s.sessions,
+        apiKeys:  s.apiKeys,
+        cleanups: append(append([]func(*repository.TenantData){}, s.cleanups...), cleanup),
+    }
+}
+
+func (s *TenantService) GetAllTenants() ([]domain.Tenant, error) {
+    tenants, err := s.tenants.GetAll()
+    if err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to get tenants: %w", err))
+    }
+    
+    return tenants, nil
+}
+
+func (s *TenantService) GetTenant(id string) (*domain.Tenant, error) {
+    return s.tenants.GetByID(id)
+}
+
+// CreateTenant creates an active tenant with empty stores. The ID is used
+// in headers and as a subdomain, so it is limited to [a-z0-9-].
+func (s *TenantService) CreateTenant(req domain.CreateTenantRequest) (*domain.Tenant, error) {
+    id, err := validateTenantID(req.ID)
+    if err != nil {
+        return nil, err
+    }
+    
+    name := strings.TrimSpace(req.Name)
+    if name == "" {
+        name = id
+    }
+    if len(name) > 100 {
+        return nil, fmt.Errorf("name is too long (max 100 characters)")
+    }
+    
+    tenant := &domain.Tenant{
+        ID:     id,
+        Name:   name,
+        Status: domain.TenantStatusActive,
+    }
+    
+    if err := s.tenants.Create(tenant); err != nil {
+        if errors.Is(err, domain.ErrAlreadyExists) {
+            return nil, err
+        }
+        return nil, domain.Internal(fmt.Errorf("failed to create tenant: %w", err))
+    }
+    
+    return tenant, nil
+}
+
+// SuspendTenant blocks every request to a tenant; its data is kept.
+func (s *TenantService) SuspendTenant(id string) (*domain.Tenant, error) {
+    return s.setStatus(id, domain.TenantStatusSuspended)
+}
+
+func (s *TenantService) ResumeTenant(id string) (*domain.Tenant, error) {
+    return s.setStatus(id, domain.TenantStatusActive)
+}
+
+// DeleteTenant removes a tenant with all its data. It can't be undone.
+func (s *TenantService) DeleteTenant(id string) error {
+    if _, err := s.tenants.GetByID(id); err != nil {
+        return err
+    }
+    
+    data, err := s.tenants.Data(id)
+    if err != nil {
+        return err
+    }
+    
+    if err := s.tenants.Delete(id); err != nil {
+        return domain.Internal(fmt.Errorf("failed to delete tenant: %w", err))
+    }
+    
+    for _, cleanup := range s.cleanups {
+        cleanup(data)
+    }
+    
+    if s.apiKeys != nil {
+        if err := s.apiKeys.DeleteByTenant(id); err != nil {
+            return domain.Internal(fmt.Errorf("failed to delete api keys: %w", err))
+        }
+    }
+    
+    if s.users != nil {
+        users, err := s.users.DeleteByTenant(id)
+        if err != nil {
+            return domain.Internal(fmt.Errorf("failed to delete users: %w", err))
+        }
+        for _, user := range users {
+            if err := s.sessions.RevokeUser(user.ID, "", time.Now()); err != nil {
+                return domain.Internal(fmt.Errorf("failed to revoke sessions: %w", err))
+            }
+        }
+    }
+    
+    return nil
+}
+
+func (s *TenantService) setStatus(id, status string) (*domain.Tenant, error) {
+    tenant, err := s.tenants.GetByID(id)
+    if err != nil {
+        return nil, err
+    }
+    
+    if tenant.Status == status {
+        return tenant, nil
+    }
+    
+    tenant.Status = status
+    tenant.SuspendedAt = nil
+    if status == domain.TenantStatusSuspended {
+        now := time.Now()
+        tenant.SuspendedAt = &now
+    }
+    
+    if err := s.tenants.Update(id, tenant); err != nil {
+        return nil, domain.Internal(fmt.Errorf("failed to update tenant: %w", err))
+    }
+    
+    return tenant, nil
+}
+
+func validateTenantID(id string) (string, error) {
+    id = strings.ToLower(strings.TrimSpace(id))
+    if len(id) < minTenantIDLength || len(id) > maxTenantIDLength {
+        return "", fmt.Errorf("tenant id must be %d to %d characters long", minTenantIDLength, maxTenantIDLength)
+    }
+    
+    for _, r := range id {
+        if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+            return "", fmt.Errorf("tenant id may only contain letters, digits and '-'")
+        }
+    }
+    
+    if strings.HasPrefix(id, "-") || strings.HasSuffix(id, "-") {
+        return "", fmt.Errorf("tenant id can't start or end with '-'")
+    }
+    
+    return id, nil
+}
@@ -0,0 +1,143 @@
+// Package todotxt parses and formats lines of the todo.txt format
+// (https://github.com/todotxt/todo.txt). Parsing followed by String is
+// lossless for well-formed lines.
+package todotxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+// Item is a single todo.txt line. Projects, contexts and key:value
+// extensions stay inline in Description, in their original order.
+type Item struct {
+    Completed      bool
+    Priority       byte
+    CompletionDate *time.Time
+    CreationDate   *time.Time
+    Description    string
+}
+
+// Line is an item together with its 1-based line number in the input.
+type Line struct {
+    Number int
+    Item   Item
+    Err    error
+}
+
+// Parse parses one line. Leading and trailing whitespace is ignored.
+func Parse(line string) (Item, error) {
+    var item Item
+    rest := strings.TrimSpace(line)
+    
+    if strings.HasPrefix(rest, "x ") {
+        item.Completed = true
+        rest = strings.TrimLeft(rest[2:], " ")
+        
+        if date, tail, ok := cutDate(rest); ok {
+            item.CompletionDate = date
+            rest = tail
+        }
+    } else if len(rest) >= 4 && rest[0] == '(' && rest[1] >= 'A' && rest[1] <= 'Z' && rest[2] == ')' && rest[3] == ' ' {
+        item.Priority = rest[1]
+        rest = strings.TrimLeft(rest[4:], " ")
+    }
+    
+    if date, tail, ok := cutDate(rest); ok {
+        item.CreationDate = date
+        rest = tail
+    }
+    
+    item.Description = strings.TrimSpace(rest)
+    if item.Description == "" {
+        return Item{}, fmt.Errorf("task description is empty")
+    }
+    
+    return item, nil
+}
+
+// String formats the item as a todo.txt line.
+func (i Item) String() string {
+    parts := make([]string, 0, 5)
+    
+    if i.Completed {
+        parts = append(parts, "x")
+        if i.CompletionDate != nil {
+            parts = append(parts, i.CompletionDate.Format(DateLayout))
+        }
+    } else if i.Priority != 0 {
+        parts = append(parts, "("+string(i.Priority)+")")
+    }
+    
+    if i.CreationDate != nil {
+        parts = append(parts, i.CreationDate.Format(DateLayout))
+    }
+    
+    parts = append(parts, i.Description)
+    return strings.Join(parts, " ")
+}
+
+// IsProject reports whether word is a +project token.
+func IsProject(word string) bool {
+    return len(word) > 1 && word[0] == '+'
+}
+
+// IsContext reports whether word is an @context token.
+func IsContext(word string) bool {
+    return len(word) > 1 && word[0] == '@'
+}
+
+// ParseExtension splits a key:value token. Keys may not contain spaces or
+// colons, and URLs such as https://example.com are not extensions.
+func ParseExtension(word string) (string, string, bool) {
+    key, value, ok := strings.Cut(word, ":")
+    if !ok || key == "" || value == "" || strings.HasPrefix(value, "//") || strings.Contains(value, ":") {
+        return "", "", false
+    }
+    if IsProject(key) || IsContext(key) {
+        return "", "", false
+    }
+    return key, value, true
+}
+
+// Read parses every non-blank line of r. Lines that fail to parse are
+// returned with Err set.
+func Read(r io.Reader) ([]Line, error) {
+    scanner := bufio.NewScanner(r)
+    scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+    
+    lines := make([]Line, 0)
+    for number := 1; scanner.Scan(); number++ {
+        text := strings.TrimPrefix(scanner.Text(), "\ufeff")
+        if strings.TrimSpace(text) == "" {
+            continue
+        }
+        item, err := Parse(text)
+        lines = append(lines, Line{Number: number, Item: item, Err: err})
+    }
+    
+    if err := scanner.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read todo.txt: %w", err)
+    }
+    return lines, nil
+}
+
+func cutDate(s string) (*time.Time, string, bool) {
+    if len(s) < len(DateLayout) {
+        return nil, s, false
+    }
+    if len(s) > len(DateLayout) && s[len(DateLayout)] != ' ' {
+        return nil, s, false
+    }
+    
+    date, err := time.Parse(DateLayout, s[:len(DateLayout)])
+    if err != nil {
+        return nil, s, false
+    }
+    return &date, strings.TrimLeft(s[len(DateLayout):], " "), true
+}
@@ -0,0 +1,150 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"tasks-crud/internal/domain"
+)
+
+var csvHeader = []string{"id", "title", "completed", "priority", "tags", "due_date", "parent_id", "created_at", "updated_at"}
+
+type csvEncoder struct {
+    w           *csv.Writer
+    wroteHeader bool
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+    return &csvEncoder{w: csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) Encode(task domain.Task) error {
+    if err := e.writeHeader(); err != nil {
+        return err
+    }
+    
+    parentID := ""
+    if task.ParentID != nil {
+        parentID = strconv.Itoa(*task.ParentID)
+    }
+    
+    return e.w.Write([]string{
+        strconv.Itoa(task.ID),
+        task.Title,
+        strconv.FormatBool(task.Completed),
+        formatPriority(task.Priority),
+        strings.Join(task.Tags, ","),
+        formatDate(task.DueDate),
+        parentID,
+        task.CreatedAt.Format(time.RFC3339),
+        task.UpdatedAt.Format(time.RFC3339),
+    })
+}
+
+func (e *csvEncoder) Close() error {
+    if err := e.writeHeader(); err != nil {
+        return err
+    }
+    e.w.Flush()
+    return e.w.Error()
+}
+
+func (e *csvEncoder) writeHeader() error {
+    if e.wroteHeader {
+        return nil
+    }
+    e.wroteHeader = true
+    return e.w.Write(csvHeader)
+}
+
+// decodeCSV reads columns by header name, so columns may come in any order
+// and unknown ones (id, timestamps) are ignored. Only title is required.
+func decodeCSV(r io.Reader) ([]domain.ImportRecord, error) {
+    reader := csv.NewReader(r)
+    reader.FieldsPerRecord = -1
+    reader.TrimLeadingSpace = true
+    
+    header, err := reader.Read()
+    if err == io.EOF {
+        return nil, fmt.Errorf("CSV is empty")
+    }
+    if err != nil {
+        return nil, fmt.Errorf("invalid CSV header: %w", err)
+    }
+    
+    columns := make(map[string]int, len(header))
+    for i, name := range header {
+        name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+        columns[name] = i
+    }
+    if _, ok := columns["title"]; !ok {
+        return nil, fmt.Errorf("CSV header must contain a 'title' column")
+    }
+    
+    records := make([]domain.ImportRecord, 0)
+    for row := 2; ; row++ {
+        fields, err := reader.Read()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            var parseErr *csv.ParseError
+            if errors.As(err, &parseErr) && parseErr.Err != csv.ErrQuote && parseErr.Err != csv.ErrBareQuote {
+                return nil, fmt.Errorf("invalid CSV: %w", err)
+            }
+            records = append(records, domain.ImportRecord{Row: row, Err: err})
+            continue
+        }
+        
+        get := func(name string) string {
+            if i, ok := columns[name]; ok && i < len(fields) {
+                return fields[i]
+            }
+            return ""
+        }
+        
+        values := map[string]string{
+            "title":     get("title"),
+            "completed": get("completed"),
+            "priority":  get("priority"),
+            "tags":      get("tags"),
+            "due_date":  get("due_date"),
+        }
+        records = append(records, recordFromValues(row, values))
+    }
+    
+    return records, nil
+}
+
+// recordFromValues builds a record from textual field values shared by the
+// CSV and Markdown decoders.
+func recordFromValues(row int, values map[string]string) domain.ImportRecord {
+    record := domain.ImportRecord{
+        Row: row,
+        Task: domain.CreateTaskRequest{
+            Title: strings.TrimSpace(values["title"]),
+            Tags:  splitTags(values["tags"]),
+        },
+    }
+    
+    var err error
+    if record.Task.Priority, err = parsePriority(values["priority"]); err != nil {
+        record.Err = err
+        return record
+    }
+    if record.Task.DueDate, err = parseDate(values["due_date"]); err != nil {
+        record.Err = err
+        return record
+    }
+    if record.Completed, err = parseBool(values["completed"]); err != nil {
+        record.Err = err
+        return record
+    }
+    
+    return record
+}
@@ -0,0 +1,239 @@
+package transfer
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/ical"
+)
+
+const (
+    calendarProductID = "-//tasks-crud//Todo API//RU"
+    uidSuffix         = "@tasks-crud"
+)
+
+// iCalendar priorities run from 1 (highest) to 9 (lowest), 0 meaning
+// undefined; RFC 5545 groups them as 1-4 high, 5 medium and 6-9 low.
+var icsPriorities = map[int]int{
+    domain.PriorityHigh:   1,
+    domain.PriorityMedium: 5,
+    domain.PriorityLow:    9,
+}
+
+type icsEncoder struct {
+    w           *ical.Writer
+    name        string
+    wroteHeader bool
+}
+
+func newICSEncoder(w io.Writer) Encoder {
+    return NewCalendarEncoder(w, "Tasks")
+}
+
+// NewCalendarEncoder streams tasks as the VTODO components of a single
+// VCALENDAR named name.
+func NewCalendarEncoder(w io.Writer, name string) Encoder {
+    return &icsEncoder{w: ical.NewWriter(w), name: name}
+}
+
+func (e *icsEncoder) Encode(task domain.Task) error {
+    if err := e.writeHeader(); err != nil {
+        return err
+    }
+    return e.w.Component(VTodo(task))
+}
+
+func (e *icsEncoder) Close() error {
+    if err := e.writeHeader(); err != nil {
+        return err
+    }
+    if err := e.w.End("VCALENDAR"); err != nil {
+        return err
+    }
+    return e.w.Flush()
+}
+
+func (e *icsEncoder) writeHeader() error {
+    if e.wroteHeader {
+        return nil
+    }
+    e.wroteHeader = true
+    
+    calendar := Calendar()
+    calendar.AddText("X-WR-CALNAME", e.name)
+    
+    if err := e.w.Begin(calendar.Name); err != nil {
+        return err
+    }
+    for _, prop := range calendar.Properties {
+        if err := e.w.Property(prop); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Calendar returns a VCALENDAR wrapping the given components.
+func Calendar(components ...*ical.Component) *ical.Component {
+    calendar := ical.NewComponent("VCALENDAR")
+    calendar.Add("VERSION", "2.0", nil)
+    calendar.Add("PRODID", calendarProductID, nil)
+    calendar.Add("CALSCALE", "GREGORIAN", nil)
+    calendar.Components = components
+    return calendar
+}
+
+// TaskUID is the iCalendar UID of a task.
+func TaskUID(id int) string {
+    return "task-" + strconv.Itoa(id) + uidSuffix
+}
+
+// TaskIDFromUID reverses TaskUID; ok is false for UIDs issued elsewhere.
+func TaskIDFromUID(uid string) (int, bool) {
+    rest, found := strings.CutPrefix(uid, "task-")
+    if !found {
+        return 0, false
+    }
+    rest, found = strings.CutSuffix(rest, uidSuffix)
+    if !found {
+        return 0, false
+    }
+    id, err := strconv.Atoi(rest)
+    return id, err == nil && id > 0
+}
+
+// VTodo converts a task to a VTODO. Due dates at midnight UTC are
+// written as all-day DATE values. Tasks have no recurrence, so no RRULE is
+// produced.
+func VTodo(task domain.Task) *ical.Component {
+    todo := ical.NewComponent("VTODO")
+    todo.Add("UID", TaskUID(task.ID), nil)
+    todo.AddTime("DTSTAMP", task.UpdatedAt)
+    todo.AddText("SUMMARY", task.Title)
+    todo.AddTime("CREATED", task.CreatedAt)
+    todo.AddTime("LAST-MODIFIED", task.UpdatedAt)
+    todo.Add("SEQUENCE", strconv.FormatInt(task.Revision, 10), nil)
+    
+    if task.Completed {
+        todo.Add("STATUS", "COMPLETED", nil)
+        todo.AddTime("COMPLETED", task.UpdatedAt)
+        todo.Add("PERCENT-COMPLETE", "100", nil)
+    } else {
+        todo.Add("STATUS", "NEEDS-ACTION", nil)
+    }
+    
+    if priority, ok := icsPriorities[task.Priority]; ok {
+        todo.Add("PRIORITY", strconv.Itoa(priority), nil)
+    }
+    
+    if task.DueDate != nil {
+        due := task.DueDate.UTC()
+        if due.Equal(due.Truncate(24 * time.Hour)) {
+            todo.AddDate("DUE", due)
+        } else {
+            todo.AddTime("DUE", due)
+        }
+    }
+    
+    if len(task.Tags) > 0 {
+        categories := make([]string, len(task.Tags))
+        for i, tag := range task.Tags {
+            categories[i] = ical.Escape(tag)
+        }
+        todo.Add("CATEGORIES", strings.Join(categories, ","), nil)
+    }
+    
+    if task.ParentID != nil {
+        todo.Add("RELATED-TO", TaskUID(*task.ParentID), map[string]string{"RELTYPE": "PARENT"})
+    }
+    
+    return todo
+}
+
+// VTodoRecord converts a VTODO into an import record. SUMMARY is required;
+// UID, RELATED-TO and RRULE are ignored because they refer to the source
+// calendar, and cancelled to-dos are rejected.
+func VTodoRecord(todo *ical.Component) domain.ImportRecord {
+    record := domain.ImportRecord{Row: todo.Line}
+    
+    if summary := todo.Get("SUMMARY"); summary != nil {
+        record.Task.Title = strings.TrimSpace(summary.Text())
+    }
+    
+    if status := todo.Get("STATUS"); status != nil {
+        switch strings.ToUpper(status.Value) {
+        case "COMPLETED":
+            record.Completed = true
+        case "CANCELLED":
+            record.Err = fmt.Errorf("cancelled to-dos are not imported")
+            return record
+        }
+    }
+    if todo.Get("COMPLETED") != nil {
+        record.Completed = true
+    }
+    
+    if priority := todo.Get("PRIORITY"); priority != nil {
+        value, err := strconv.Atoi(strings.TrimSpace(priority.Value))
+        if err != nil || value < 0 || value > 9 {
+            record.Err = fmt.Errorf("invalid priority '%s'", priority.Value)
+            return record
+        }
+        record.Task.Priority = priorityFromICS(value)
+    }
+    
+    if due := todo.Get("DUE"); due != nil {
+        t, err := due.Time()
+        if err != nil {
+            record.Err = fmt.Errorf("invalid due date '%s'", due.Value)
+            return record
+        }
+        record.Task.DueDate = &t
+    }
+    
+    for _, categories := range todo.GetAll("CATEGORIES") {
+        for _, tag := range ical.SplitList(categories.Value) {
+            if tag = strings.TrimSpace(tag); tag != "" {
+                record.Task.Tags = append(record.Task.Tags, tag)
+            }
+        }
+    }
+    
+    return record
+}
+
+func priorityFromICS(value int) int {
+    switch {
+    case value == 0:
+        return domain.PriorityNone
+    case value <= 4:
+        return domain.PriorityHigh
+    case value == 5:
+        return domain.PriorityMedium
+    default:
+        return domain.PriorityLow
+    }
+}
+
+func decodeICS(r io.Reader) ([]domain.ImportRecord, error) {
+    components, err := ical.Parse(r)
+    if err != nil {
+        return nil, err
+    }
+    
+    records := make([]domain.ImportRecord, 0)
+    for _, component := range components {
+        if component.Name != "VCALENDAR" {
+            return nil, fmt.Errorf("line %d: expected VCALENDAR, got %s", component.Line, component.Name)
+        }
+        for _, todo := range component.Find("VTODO") {
+            records = append(records, VTodoRecord(todo))
+        }
+    }
+    
+    return records, nil
+}
@@ -0,0 +1,103 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"tasks-crud/internal/domain"
+)
+
+type jsonEncoder struct {
+    w     *bufio.Writer
+    count int
+}
+
+func newJSONEncoder(w io.Writer) *jsonEncoder {
+    return &jsonEncoder{w: bufio.NewWriter(w)}
+}
+
+func (e *jsonEncoder) Encode(task domain.Task) error {
+    data, err := json.Marshal(task)
+    if err != nil {
+        return err
+    }
+    
+    separator := ",\n  "
+    if e.count == 0 {
+        separator = "[\n  "
+    }
+    e.count++
+    
+    if _, err := e.w.WriteString(separator); err != nil {
+        return err
+    }
+    _, err = e.w.Write(data)
+    return err
+}
+
+func (e *jsonEncoder) Close() error {
+    closing := "\n]\n"
+    if e.count == 0 {
+        closing = "[]\n"
+    }
+    if _, err := e.w.WriteString(closing); err != nil {
+        return err
+    }
+    return e.w.Flush()
+}
+
+type jsonImportTask struct {
+    Title     string     `json:"title"`
+    Completed bool       `json:"completed"`
+    Priority  int        `json:"priority"`
+    Tags      []string   `json:"tags"`
+    DueDate   *time.Time `json:"due_date"`
+}
+
+// decodeJSON reads an array of task objects, such as a JSON export. Elements
+// are decoded one by one so that a malformed element only rejects that row.
+func decodeJSON(r io.Reader) ([]domain.ImportRecord, error) {
+    decoder := json.NewDecoder(r)
+    
+    token, err := decoder.Token()
+    if err != nil {
+        return nil, fmt.Errorf("invalid JSON: %w", err)
+    }
+    if delim, ok := token.(json.Delim); !ok || delim != '[' {
+        return nil, fmt.Errorf("invalid JSON: expected an array of tasks")
+    }
+    
+    records := make([]domain.ImportRecord, 0)
+    for row := 1; decoder.More(); row++ {
+        var raw json.RawMessage
+        if err := decoder.Decode(&raw); err != nil {
+            return nil, fmt.Errorf("invalid JSON at element %d: %w", row, err)
+        }
+        
+        var task jsonImportTask
+        if err := json.Unmarshal(raw, &task); err != nil {
+            records = append(records, domain.ImportRecord{Row: row, Err: fmt.Errorf("invalid task: %w", err)})
+            continue
+        }
+        
+        records = append(records, domain.ImportRecord{
+            Row: row,
+            Task: domain.CreateTaskRequest{
+                Title:    task.Title,
+                Priority: task.Priority,
+                Tags:     task.Tags,
+                DueDate:  task.DueDate,
+            },
+            Completed: task.Completed,
+        })
+    }
+    
+    if _, err := decoder.Token(); err != nil {
+        return nil, fmt.Errorf("invalid JSON: %w", err)
+    }
+    
+    return records, nil
+}
@@ -0,0 +1,187 @@
+package transfer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"tasks-crud/internal/domain"
+)
+
+var markdownHeader = []string{"ID", "Done", "Title", "Priority", "Tags", "Due"}
+
+type markdownEncoder struct {
+    w           *bufio.Writer
+    wroteHeader bool
+}
+
+func newMarkdownEncoder(w io.Writer) *markdownEncoder {
+    return &markdownEncoder{w: bufio.NewWriter(w)}
+}
+
+func (e *markdownEncoder) Encode(task domain.Task) error {
+    e.writeHeader()
+    
+    done := " "
+    if task.Completed {
+        done = "x"
+    }
+    
+    e.writeRow([]string{
+        strconv.Itoa(task.ID),
+        done,
+        task.Title,
+        formatPriority(task.Priority),
+        strings.Join(task.Tags, ", "),
+        formatDate(task.DueDate),
+    })
+    return nil
+}
+
+func (e *markdownEncoder) Close() error {
+    e.writeHeader()
+    return e.w.Flush()
+}
+
+func (e *markdownEncoder) writeHeader() {
+    if e.wroteHeader {
+        return
+    }
+    e.wroteHeader = true
+    
+    e.writeRow(markdownHeader)
+    separator := make([]string, len(markdownHeader))
+    for i := range separator {
+        separator[i] = "---"
+    }
+    e.writeRow(separator)
+}
+
+func (e *markdownEncoder) writeRow(cells []string) {
+    e.w.WriteString("|")
+    for _, cell := range cells {
+        e.w.WriteString(" ")
+        e.w.WriteString(escapeMarkdownCell(cell))
+        e.w.WriteString(" |")
+    }
+    e.w.WriteString("\n")
+}
+
+func escapeMarkdownCell(value string) string {
+    value = strings.ReplaceAll(value, "\\", "\\\\")
+    value = strings.ReplaceAll(value, "|", "\\|")
+    return strings.Join(strings.Fields(value), " ")
+}
+
+// decodeMarkdown accepts a table like the one produced by the exporter
+// (columns matched by header name) and GitHub task list items such as
+// "- [x] Title". Other lines are ignored.
+func decodeMarkdown(r io.Reader) ([]domain.ImportRecord, error) {
+    scanner := bufio.NewScanner(r)
+    scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+    
+    records := make([]domain.ImportRecord, 0)
+    var columns map[string]int
+    
+    for line := 1; scanner.Scan(); line++ {
+        text := strings.TrimSpace(scanner.Text())
+        
+        if title, completed, ok := parseTaskListItem(text); ok {
+            records = append(records, domain.ImportRecord{
+                Row:       line,
+                Task:      domain.CreateTaskRequest{Title: title, Tags: []string{}},
+                Completed: completed,
+            })
+            columns = nil
+            continue
+        }
+        
+        if !strings.HasPrefix(text, "|") {
+            columns = nil
+            continue
+        }
+        
+        cells := splitMarkdownRow(text)
+        switch {
+        case columns == nil:
+            columns = make(map[string]int, len(cells))
+            for i, cell := range cells {
+                columns[strings.ToLower(cell)] = i
+            }
+        case isSeparatorRow(cells):
+        default:
+            if _, ok := columns["title"]; !ok {
+                return nil, fmt.Errorf("line %d: table has no 'Title' column", line)
+            }
+            get := func(name string) string {
+                if i, ok := columns[name]; ok && i < len(cells) {
+                    return cells[i]
+                }
+                return ""
+            }
+            records = append(records, recordFromValues(line, map[string]string{
+                "title":     get("title"),
+                "completed": get("done"),
+                "priority":  get("priority"),
+                "tags":      get("tags"),
+                "due_date":  get("due"),
+            }))
+        }
+    }
+    
+    if err := scanner.Err(); err != nil {
+        return nil, fmt.Errorf("failed to read Markdown: %w", err)
+    }
+    
+    return records, nil
+}
+
+func parseTaskListItem(line string) (string, bool, bool) {
+    for _, bullet := range []string{"- ", "* ", "+ "} {
+        rest, ok := strings.CutPrefix(line, bullet)
+        if !ok {
+            continue
+        }
+        switch {
+        case strings.HasPrefix(rest, "[ ] "):
+            return strings.TrimSpace(rest[4:]), false, true
+        case strings.HasPrefix(rest, "[x] "), strings.HasPrefix(rest, "[X] "):
+            return strings.TrimSpace(rest[4:]), true, true
+        }
+    }
+    return "", false, false
+}
+
+func splitMarkdownRow(line string) []string {
+    line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+    
+    cells := make([]string, 0)
+    var cell strings.Builder
+    escaped := false
+    for _, r := range line {
+        switch {
+        case escaped:
+            cell.WriteRune(r)
+            escaped = false
+        case r == '\\':
+            escaped = true
+        case r == '|':
+            cells = append(cells, strings.TrimSpace(cell.String()))
+            cell.Reset()
+        default:
+            cell.WriteRune(r)
+        }
+    }
+    return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func isSeparatorRow(cells []string) bool {
+    for _, cell := range cells {
+        if strings.Trim(cell, ":-") != "" || cell == "" {
+            return false
+        }
+    }
+    return true
+}
@@ -0,0 +1,186 @@
+package transfer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/todotxt"
+)
+
+// todo.txt priorities map onto ours as (A) high, (B) medium and (C)-(Z) low.
+var todoPriorities = map[int]byte{
+    domain.PriorityHigh:   'A',
+    domain.PriorityMedium: 'B',
+    domain.PriorityLow:    'C',
+}
+
+const (
+    todoDueKey      = "due"
+    todoPriorityKey = "pri"
+)
+
+type todoEncoder struct {
+    w *bufio.Writer
+}
+
+func newTodoEncoder(w io.Writer) *todoEncoder {
+    return &todoEncoder{w: bufio.NewWriter(w)}
+}
+
+func (e *todoEncoder) Encode(task domain.Task) error {
+    _, err := e.w.WriteString(TodoItem(task).String() + "\n")
+    return err
+}
+
+func (e *todoEncoder) Close() error {
+    return e.w.Flush()
+}
+
+// TodoItem converts a task to a todo.txt item. Plain tags become +projects,
+// tags starting with @ become @contexts and the due date a due: extension.
+// Completed tasks lose their (A) prefix, as the format requires, and keep
+// the priority in a pri: extension instead. Title words that would read as
+// any of these are escaped with a backslash.
+func TodoItem(task domain.Task) todotxt.Item {
+    created := task.CreatedAt
+    item := todotxt.Item{
+        Completed:    task.Completed,
+        CreationDate: &created,
+    }
+    
+    words := strings.Fields(task.Title)
+    for i, word := range words {
+        if needsTodoEscape(word) {
+            words[i] = `\` + word
+        }
+    }
+    for _, tag := range task.Tags {
+        if todotxt.IsContext(tag) {
+            words = append(words, tag)
+        } else {
+            words = append(words, "+"+tag)
+        }
+    }
+    if task.DueDate != nil {
+        words = append(words, todoDueKey+":"+task.DueDate.Format(todotxt.DateLayout))
+    }
+    
+    priority := todoPriorities[task.Priority]
+    if task.Completed {
+        completed := task.UpdatedAt
+        if task.CompletedAt != nil {
+            completed = *task.CompletedAt
+        }
+        item.CompletionDate = &completed
+        if priority != 0 {
+            words = append(words, todoPriorityKey+":"+string(priority))
+        }
+    } else {
+        item.Priority = priority
+    }
+    
+    item.Description = strings.Join(words, " ")
+    return item
+}
+
+// TodoRecord converts a todo.txt item into an import record, the reverse
+// of TodoItem. Projects and contexts become tags, due: the due date and
+// pri: the priority of a completed task; other key:value extensions stay in
+// the title so nothing is lost. The creation and completion dates become
+// those of the task.
+func TodoRecord(row int, item todotxt.Item) domain.ImportRecord {
+    record := domain.ImportRecord{
+        Row:         row,
+        Completed:   item.Completed,
+        CreatedAt:   item.CreationDate,
+        CompletedAt: item.CompletionDate,
+        Task: domain.CreateTaskRequest{
+            Priority: todoPriority(item.Priority),
+            Tags:     make([]string, 0),
+        },
+    }
+    
+    words := make([]string, 0)
+    for _, word := range strings.Fields(item.Description) {
+        if escaped, ok := strings.CutPrefix(word, `\`); ok && needsTodoEscape(escaped) {
+            words = append(words, escaped)
+            continue
+        }
+        
+        switch {
+        case todotxt.IsProject(word):
+            record.Task.Tags = append(record.Task.Tags, word[1:])
+            continue
+        case todotxt.IsContext(word):
+            record.Task.Tags = append(record.Task.Tags, word)
+            continue
+        }
+        
+        key, value, ok := todotxt.ParseExtension(word)
+        switch {
+        case ok && key == todoDueKey:
+            dueDate, err := parseDate(value)
+            if err != nil && record.Err == nil {
+                record.Err = err
+            }
+            record.Task.DueDate = dueDate
+        case ok && key == todoPriorityKey && len(value) == 1 && value[0] >= 'A' && value[0] <= 'Z':
+            record.Task.Priority = todoPriority(value[0])
+        default:
+            words = append(words, word)
+        }
+    }
+    
+    record.Task.Title = strings.Join(words, " ")
+    return record
+}
+
+// needsTodoEscape reports whether a title word would be read back as
+// something else: a project, a context, a due: or pri: extension, or an
+// escaped word.
+func needsTodoEscape(word string) bool {
+    if todotxt.IsProject(word) || todotxt.IsContext(word) {
+        return true
+    }
+    if key, _, ok := todotxt.ParseExtension(word); ok && (key == todoDueKey || key == todoPriorityKey) {
+        return true
+    }
+    if escaped, ok := strings.CutPrefix(word, `\`); ok {
+        return needsTodoEscape(escaped)
+    }
+    return false
+}
+
+func todoPriority(letter byte) int {
+    switch {
+    case letter == 0:
+        return domain.PriorityNone
+    case letter == 'A':
+        return domain.PriorityHigh
+    case letter == 'B':
+        return domain.PriorityMedium
+    default:
+        return domain.PriorityLow
+    }
+}
+
+func decodeTodoTxt(r io.Reader) ([]domain.ImportRecord, error) {
+    lines, err := todotxt.Read(r)
+    if err != nil {
+        return nil, err
+    }
+    
+    records := make([]domain.ImportRecord, 0, len(lines))
+    for _, line := range lines {
+        if line.Err != nil {
+            records = append(records, domain.ImportRecord{Row: line.Number, Err: fmt.Errorf("invalid todo.txt line: %w", line.Err)})
+            continue
+        }
+        records = append(records, TodoRecord(line.Number, line.Item))
+    }
+    
+    return records, nil
+}
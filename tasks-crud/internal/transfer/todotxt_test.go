@@ -0,0 +1,100 @@
+package transfer_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/repository"
+	"tasks-crud/internal/service"
+	"tasks-crud/internal/todotxt"
+	"tasks-crud/internal/transfer"
+)
+
+// Importing a todo.txt file and exporting it again gives back the same
+// lines: dates, priorities, tags and escaped title words all survive.
+func TestTodoTxtImportExportRoundTrip(t *testing.T) {
+    input := strings.Join([]string{
+        `(A) 2024-03-01 Call \@bob about \+1 and \\+2 +work @phone due:2024-03-05`,
+        `x 2024-03-04 2024-03-02 Pay rent \due:monday +home pri:B`,
+        `2024-03-03 Read https://example.com note:kept`,
+        `(C) 2024-03-03 Plan trip`,
+    }, "\n") + "\n"
+    
+    records, err := transfer.Decode(domain.TransferFormatTodoTxt, strings.NewReader(input))
+    if err != nil {
+        t.Fatal(err)
+    }
+    taskService := service.NewTaskService(repository.NewEmptyInMemoryTaskRepository())
+    resp, err := taskService.ImportTasks(records, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if resp.Created != len(records) {
+        t.Fatalf("imported %d of %d tasks: %+v", resp.Created, len(records), resp.Results)
+    }
+    
+    tasks, err := taskService.GetAllTasks()
+    if err != nil {
+        t.Fatal(err)
+    }
+    var output bytes.Buffer
+    encoder, err := transfer.NewEncoder(domain.TransferFormatTodoTxt, &output)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, task := range tasks {
+        if err := encoder.Encode(task); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if err := encoder.Close(); err != nil {
+        t.Fatal(err)
+    }
+    
+    if output.String() != input {
+        t.Errorf("exported\n%s\nwant\n%s", output.String(), input)
+    }
+}
+
+func TestTodoTxtDatesMapOntoTask(t *testing.T) {
+    item, err := todotxt.Parse(`x 2024-03-04 2024-03-02 Pay rent`)
+    if err != nil {
+        t.Fatal(err)
+    }
+    
+    record := transfer.TodoRecord(1, item)
+    created := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+    completed := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+    if record.CreatedAt == nil || !record.CreatedAt.Equal(created) {
+        t.Errorf("CreatedAt = %v, want %v", record.CreatedAt, created)
+    }
+    if !record.Completed || record.CompletedAt == nil || !record.CompletedAt.Equal(completed) {
+        t.Errorf("Completed = %v, CompletedAt = %v, want %v", record.Completed, record.CompletedAt, completed)
+    }
+}
+
+func TestTodoItemEscapesTitleWords(t *testing.T) {
+    task := domain.Task{
+        Title:     `Email @bob re +1 due:friday pri:A \@x plain\word`,
+        Tags:      []string{"work"},
+        CreatedAt: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+    }
+    
+    line := transfer.TodoItem(task).String()
+    want := `2024-03-01 Email \@bob re \+1 \due:friday \pri:A \\@x plain\word +work`
+    if line != want {
+        t.Fatalf("TodoItem = %q, want %q", line, want)
+    }
+    
+    item, err := todotxt.Parse(line)
+    if err != nil {
+        t.Fatal(err)
+    }
+    record := transfer.TodoRecord(1, item)
+    if record.Task.Title != task.Title || len(record.Task.Tags) != 1 || record.Task.DueDate != nil || record.Task.Priority != domain.PriorityNone {
+        t.Errorf("TodoRecord = %+v, want title %q and only the work tag", record.Task, task.Title)
+    }
+}
@@ -0,0 +1,173 @@
+// Package transfer reads and writes tasks in interchange formats: CSV, JSON,
+// Markdown tables, todo.txt and iCalendar.
+package transfer
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	"tasks-crud/internal/domain"
+)
+
+const dateLayout = "2006-01-02"
+
+var priorityNames = []string{"none", "low", "medium", "high"}
+
+// Encoder writes tasks one at a time so that exports can be streamed.
+type Encoder interface {
+    Encode(task domain.Task) error
+    // Close writes any trailing output and flushes buffered data.
+    Close() error
+}
+
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+    switch format {
+    case domain.TransferFormatCSV:
+        return newCSVEncoder(w), nil
+    case domain.TransferFormatJSON:
+        return newJSONEncoder(w), nil
+    case domain.TransferFormatMarkdown:
+        return newMarkdownEncoder(w), nil
+    case domain.TransferFormatTodoTxt:
+        return newTodoEncoder(w), nil
+    case domain.TransferFormatICS:
+        return newICSEncoder(w), nil
+    }
+    return nil, unsupportedFormat(format)
+}
+
+// Decode parses every task in r. Rows that can't be parsed are returned
+// with Err set instead of failing the whole import; an error is returned
+// only if the input as a whole is unreadable.
+func Decode(format string, r io.Reader) ([]domain.ImportRecord, error) {
+    switch format {
+    case domain.TransferFormatCSV:
+        return decodeCSV(r)
+    case domain.TransferFormatJSON:
+        return decodeJSON(r)
+    case domain.TransferFormatMarkdown:
+        return decodeMarkdown(r)
+    case domain.TransferFormatTodoTxt:
+        return decodeTodoTxt(r)
+    case domain.TransferFormatICS:
+        return decodeICS(r)
+    }
+    return nil, unsupportedFormat(format)
+}
+
+// ContentType returns the MIME type and file extension of format.
+func ContentType(format string) (string, string) {
+    switch format {
+    case domain.TransferFormatCSV:
+        return "text/csv; charset=utf-8", "csv"
+    case domain.TransferFormatJSON:
+        return "application/json", "json"
+    case domain.TransferFormatMarkdown:
+        return "text/markdown; charset=utf-8", "md"
+    case domain.TransferFormatTodoTxt:
+        return "text/plain; charset=utf-8", "txt"
+    case domain.TransferFormatICS:
+        return "text/calendar; charset=utf-8", "ics"
+    }
+    return "application/octet-stream", "txt"
+}
+
+// FormatFromContentType maps a request Content-Type onto a format, or
+// returns "" if it is not recognised.
+func FormatFromContentType(contentType string) string {
+    mediaType, _, err := mime.ParseMediaType(contentType)
+    if err != nil {
+        return ""
+    }
+    
+    switch mediaType {
+    case "text/csv", "application/csv":
+        return domain.TransferFormatCSV
+    case "application/json":
+        return domain.TransferFormatJSON
+    case "text/markdown", "text/x-markdown":
+        return domain.TransferFormatMarkdown
+    case "text/plain", "text/x-todo":
+        return domain.TransferFormatTodoTxt
+    case "text/calendar":
+        return domain.TransferFormatICS
+    }
+    return ""
+}
+
+func unsupportedFormat(format string) error {
+    return fmt.Errorf("unsupported format '%s' (expected csv, json, markdown, todotxt or ics)", format)
+}
+
+func formatPriority(priority int) string {
+    if priority >= 0 && priority < len(priorityNames) {
+        return priorityNames[priority]
+    }
+    return strconv.Itoa(priority)
+}
+
+func parsePriority(value string) (int, error) {
+    value = strings.TrimSpace(value)
+    if value == "" {
+        return domain.PriorityNone, nil
+    }
+    
+    for i, name := range priorityNames {
+        if strings.EqualFold(value, name) {
+            return i, nil
+        }
+    }
+    
+    priority, err := strconv.Atoi(value)
+    if err != nil {
+        return 0, fmt.Errorf("invalid priority '%s'", value)
+    }
+    return priority, nil
+}
+
+func formatDate(t *time.Time) string {
+    if t == nil {
+        return ""
+    }
+    return t.Format(dateLayout)
+}
+
+// parseDate accepts a plain date or a full RFC 3339 timestamp.
+func parseDate(value string) (*time.Time, error) {
+    value = strings.TrimSpace(value)
+    if value == "" {
+        return nil, nil
+    }
+    
+    if t, err := time.Parse(dateLayout, value); err == nil {
+        return &t, nil
+    }
+    if t, err := time.Parse(time.RFC3339, value); err == nil {
+        return &t, nil
+    }
+    return nil, fmt.Errorf("invalid due date '%s'", value)
+}
+
+func parseBool(value string) (bool, error) {
+    switch strings.ToLower(strings.TrimSpace(value)) {
+    case "", "false", "no", "0":
+        return false, nil
+    case "true", "yes", "1", "x", "✓":
+        return true, nil
+    }
+    return false, fmt.Errorf("invalid completed value '%s'", value)
+}
+
+func splitTags(value string) []string {
+    tags := make([]string, 0)
+    for _, tag := range strings.Split(value, ",") {
+        if tag = strings.TrimSpace(tag); tag != "" {
+            tags = append(tags, tag)
+        }
+    }
+    return tags
+}
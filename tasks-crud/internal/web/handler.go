@@ -0,0 +1,479 @@
+package web
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"tasks-crud/internal/domain"
+	"tasks-crud/internal/middleware"
+	"tasks-crud/internal/query"
+	"tasks-crud/internal/service"
+)
+
+//go:embed templates/*.html static/*
+var files embed.FS
+
+const (
+    dateLayout   = "2006-01-02"
+    defaultQuery = "status:open"
+)
+
+var priorityLabels = []string{"нет", "низкий", "средний", "высокий"}
+
+type Handler struct {
+    service *service.TaskService
+    auth    *service.AuthService
+    pages   map[string]*template.Template
+    static  http.Handler
+}
+
+func NewHandler(service *service.TaskService) (*Handler, error) {
+    funcs := template.FuncMap{
+        "date":     formatDate,
+        "priority": priorityLabel,
+        "join":     strings.Join,
+    }
+    
+    pages := make(map[string]*template.Template)
+    for _, page := range []string{"index.html", "edit.html", "login.html"} {
+        tmpl, err := template.New(page).Funcs(funcs).ParseFS(files, "templates/layout.html", "templates/"+page)
+        if err != nil {
+            return nil, fmt.Errorf("failed to parse template %s: %w", page, err)
+        }
+        pages[page] = tmpl
+    }
+    
+    static, err := fs.Sub(files, "static")
+    if err != nil {
+        return nil, fmt.Errorf("failed to load static files: %w", err)
+    }
+    
+    return &Handler{
+        service: service,
+        pages:   pages,
+        static:  http.StripPrefix("/static/", http.FileServer(http.FS(static))),
+    }, nil
+}
+
+// WithLogin returns a handler that signs users in with auth, for when
+// authentication is enabled. The session lives in cookies; see
+// middleware.WebSession.
+func (h *Handler) WithLogin(auth *service.AuthService) *Handler {
+    return &Handler{
+        service: h.service,
+        auth:    auth,
+        pages:   h.pages,
+        static:  h.static,
+    }
+}
+
+// IsPage reports whether r is for a page of the UI that needs a signed in
+// user when authentication is enabled: everything but the login page and
+// the static files.
+func IsPage(r *http.Request) bool {
+    path := r.URL.Path
+    return path == "/" || path == "/logout" || path == "/tasks" || strings.HasPrefix(path, "/tasks/")
+}
+
+// Register mounts the UI on router. Form posts are CSRF-protected.
+func (h *Handler) Register(router *mux.Router) {
+    router.PathPrefix("/static/").Handler(h.static).Methods("GET", "HEAD")
+    
+    ui := router.NewRoute().Subrouter()
+    ui.Use(middleware.CSRF)
+    if h.auth != nil {
+        ui.HandleFunc("/login", h.loginPage).Methods("GET")
+        ui.HandleFunc("/login", h.login).Methods("POST")
+        ui.HandleFunc("/logout", h.logout).Methods("POST")
+    }
+    ui.HandleFunc("/", h.index).Methods("GET")
+    ui.HandleFunc("/tasks", h.create).Methods("POST")
+    ui.HandleFunc("/tasks/{id:[0-9]+}/edit", h.edit).Methods("GET")
+    ui.HandleFunc("/tasks/{id:[0-9]+}", h.update).Methods("POST")
+    ui.HandleFunc("/tasks/{id:[0-9]+}/toggle", h.toggle).Methods("POST")
+    ui.HandleFunc("/tasks/{id:[0-9]+}/delete", h.delete).Methods("POST")
+}
+
+type taskForm struct {
+    Title     string
+    Priority  int
+    Tags      string
+    DueDate   string
+    Completed bool
+}
+
+type indexPage struct {
+    CSRFToken  string
+    SignedIn   bool
+    Return     string
+    Query      string
+    Tasks      []domain.Task
+    Form       taskForm
+    Error      string
+    QueryError string
+    Priorities []string
+}
+
+type loginPage struct {
+    CSRFToken string
+    SignedIn  bool
+    Username  string
+    ReturnTo  string
+    Error     string
+}
+
+type editPage struct {
+    CSRFToken  string
+    SignedIn   bool
+    Task       *domain.Task
+    Form       taskForm
+    Error      string
+    ReturnTo   string
+    Priorities []string
+}
+
+func (h *Handler) index(w http.ResponseWriter, r *http.Request) {
+    h.renderIndex(w, r, http.StatusOK, taskForm{}, "")
+}
+
+func (h *Handler) renderIndex(w http.ResponseWriter, r *http.Request, status int, form taskForm, formError string) {
+    q := defaultQuery
+    if values, ok := r.URL.Query()["q"]; ok {
+        q = strings.TrimSpace(values[0])
+    }
+    
+    page := indexPage{
+        CSRFToken:  middleware.CSRFToken(r),
+        SignedIn:   h.signedIn(r),
+        Return:     "/?" + r.URL.Query().Encode(),
+        Query:      q,
+        Form:       form,
+        Error:      formError,
+        Priorities: priorityLabels,
+    }
+    
+    tasks, err := h.service.WithContext(r.Context()).ListTasks(q)
+    if err != nil {
+        var parseErr *query.ParseError
+        if !errors.As(err, &parseErr) {
+            http.Error(w, "Не удалось загрузить задачи", http.StatusInternalServerError)
+            return
+        }
+        page.QueryError = err.Error()
+        status = http.StatusBadRequest
+    }
+    page.Tasks = tasks
+    
+    h.render(w, status, "index.html", page)
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+    form := readForm(r)
+    
+    req := domain.CreateTaskRequest{
+        Title:    form.Title,
+        Priority: form.Priority,
+        Tags:     splitTags(form.Tags),
+    }
+    dueDate, err := parseDate(form.DueDate)
+    if err == nil {
+        req.DueDate = dueDate
+        _, err = h.service.WithContext(r.Context()).CreateTask(req)
+    }
+    if err != nil {
+        if wantsJSON(r) {
+            sendJSONError(w, errorStatus(err, http.StatusUnprocessableEntity), err)
+            return
+        }
+        r.URL.RawQuery = returnQuery(r)
+        h.renderIndex(w, r, errorStatus(err, http.StatusUnprocessableEntity), form, err.Error())
+        return
+    }
+    
+    redirectBack(w, r)
+}
+
+func (h *Handler) edit(w http.ResponseWriter, r *http.Request) {
+    task, ok := h.task(w, r)
+    if !ok {
+        return
+    }
+    
+    h.render(w, http.StatusOK, "edit.html", editPage{
+        CSRFToken:  middleware.CSRFToken(r),
+        SignedIn:   h.signedIn(r),
+        Task:       task,
+        Form:       formFromTask(task),
+        ReturnTo:   safeReturn(r.URL.Query().Get("return")),
+        Priorities: priorityLabels,
+    })
+}
+
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+    task, ok := h.task(w, r)
+    if !ok {
+        return
+    }
+    
+    form := readForm(r)
+    dueDate, err := parseDate(form.DueDate)
+    if err == nil {
+        _, err = h.service.WithContext(r.Context()).ReplaceTask(task.ID, domain.ReplaceTaskRequest{
+            Title:     form.Title,
+            Completed: form.Completed,
+            Priority:  form.Priority,
+            Tags:      splitTags(form.Tags),
+            DueDate:   dueDate,
+            ParentID:  task.ParentID,
+        })
+    }
+    if err != nil {
+        h.render(w, errorStatus(err, http.StatusUnprocessableEntity), "edit.html", editPage{
+            CSRFToken:  middleware.CSRFToken(r),
+            SignedIn:   h.signedIn(r),
+            Task:       task,
+            Form:       form,
+            Error:      err.Error(),
+            ReturnTo:   safeReturn(r.PostFormValue("return")),
+            Priorities: priorityLabels,
+        })
+        return
+    }
+    
+    redirectBack(w, r)
+}
+
+func (h *Handler) toggle(w http.ResponseWriter, r *http.Request) {
+    task, ok := h.task(w, r)
+    if !ok {
+        return
+    }
+    
+    completed := !task.Completed
+    updated, err := h.service.WithContext(r.Context()).UpdateTask(task.ID, domain.UpdateTaskRequest{Completed: &completed})
+    if err != nil {
+        status := errorStatus(err, http.StatusUnprocessableEntity)
+        if wantsJSON(r) {
+            sendJSONError(w, status, err)
+            return
+        }
+        http.Error(w, err.Error(), status)
+        return
+    }
+    
+    if wantsJSON(r) {
+        sendJSON(w, http.StatusOK, updated)
+        return
+    }
+    redirectBack(w, r)
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
+    task, ok := h.task(w, r)
+    if !ok {
+        return
+    }
+    
+    if err := h.service.WithContext(r.Context()).DeleteTask(task.ID); err != nil {
+        status := errorStatus(err, http.StatusNotFound)
+        if wantsJSON(r) {
+            sendJSONError(w, status, err)
+            return
+        }
+        http.Error(w, err.Error(), status)
+        return
+    }
+    
+    if wantsJSON(r) {
+        w.WriteHeader(http.StatusNoContent)
+        return
+    }
+    redirectBack(w, r)
+}
+
+func (h *Handler) loginPage(w http.ResponseWriter, r *http.Request) {
+    h.render(w, http.StatusOK, "login.html", loginPage{
+        CSRFToken: middleware.CSRFToken(r),
+        ReturnTo:  safeReturn(r.URL.Query().Get("return")),
+    })
+}
+
+// login starts a session for the user and sends them back to the page
+// they came from.
+func (h *Handler) login(w http.ResponseWriter, r *http.Request) {
+    username := strings.TrimSpace(r.PostFormValue("username"))
+    tokens, err := h.auth.WithContext(r.Context()).Login(domain.LoginRequest{
+        Username: username,
+        Password: r.PostFormValue("password"),
+    })
+    if err != nil {
+        h.render(w, http.StatusUnauthorized, "login.html", loginPage{
+            CSRFToken: middleware.CSRFToken(r),
+            Username:  username,
+            ReturnTo:  safeReturn(r.PostFormValue("return")),
+            Error:     "Неверное имя пользователя или пароль",
+        })
+        return
+    }
+    
+    middleware.SetSession(w, r, tokens)
+    redirectBack(w, r)
+}
+
+// logout ends the session, also for the access token in the cookie.
+func (h *Handler) logout(w http.ResponseWriter, r *http.Request) {
+    if err := h.auth.WithContext(r.Context()).Logout(domain.PrincipalFromContext(r.Context())); err != nil {
+        log.Printf("web: failed to end session: %v", err)
+    }
+    
+    middleware.ClearSession(w, r)
+    http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+func (h *Handler) signedIn(r *http.Request) bool {
+    return h.auth != nil && domain.PrincipalFromContext(r.Context()) != nil
+}
+
+// task loads the task named in the URL, writing a 404 page if it is missing.
+func (h *Handler) task(w http.ResponseWriter, r *http.Request) (*domain.Task, bool) {
+    id, _ := strconv.Atoi(mux.Vars(r)["id"])
+    task, err := h.service.WithContext(r.Context()).GetTaskByID(id)
+    if err != nil {
+        if wantsJSON(r) {
+            sendJSONError(w, http.StatusNotFound, err)
+        } else {
+            http.Error(w, "Задача не найдена", http.StatusNotFound)
+        }
+        return nil, false
+    }
+    return task, true
+}
+
+func (h *Handler) render(w http.ResponseWriter, status int, page string, data interface{}) {
+    w.Header().Set("Content-Type", "text/html; charset=utf-8")
+    w.WriteHeader(status)
+    if err := h.pages[page].ExecuteTemplate(w, "layout", data); err != nil {
+        log.Printf("web: failed to render %s: %v", page, err)
+    }
+}
+
+func readForm(r *http.Request) taskForm {
+    priority, _ := strconv.Atoi(r.PostFormValue("priority"))
+    return taskForm{
+        Title:     strings.TrimSpace(r.PostFormValue("title")),
+        Priority:  priority,
+        Tags:      r.PostFormValue("tags"),
+        DueDate:   strings.TrimSpace(r.PostFormValue("due_date")),
+        Completed: r.PostFormValue("completed") != "",
+    }
+}
+
+func formFromTask(task *domain.Task) taskForm {
+    form := taskForm{
+        Title:     task.Title,
+        Priority:  task.Priority,
+        Tags:      strings.Join(task.Tags, ", "),
+        Completed: task.Completed,
+    }
+    if task.DueDate != nil {
+        form.DueDate = task.DueDate.Format(dateLayout)
+    }
+    return form
+}
+
+func splitTags(value string) []string {
+    tags := make([]string, 0)
+    for _, tag := range strings.Split(value, ",") {
+        if tag = strings.TrimSpace(tag); tag != "" {
+            tags = append(tags, tag)
+        }
+    }
+    return tags
+}
+
+func parseDate(value string) (*time.Time, error) {
+    if value == "" {
+        return nil, nil
+    }
+    date, err := time.Parse(dateLayout, value)
+    if err != nil {
+        return nil, fmt.Errorf("invalid due date '%s' (expected YYYY-MM-DD)", value)
+    }
+    return &date, nil
+}
+
+func formatDate(t *time.Time) string {
+    if t == nil {
+        return ""
+    }
+    return t.Format(dateLayout)
+}
+
+func priorityLabel(priority int) string {
+    if priority >= 0 && priority < len(priorityLabels) {
+        return priorityLabels[priority]
+    }
+    return strconv.Itoa(priority)
+}
+
+// redirectBack sends the browser to the page the form was posted from.
+func redirectBack(w http.ResponseWriter, r *http.Request) {
+    http.Redirect(w, r, safeReturn(r.PostFormValue("return")), http.StatusSeeOther)
+}
+
+// safeReturn only allows local paths, so "return" can't be used as an open redirect.
+func safeReturn(target string) string {
+    if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+        return "/"
+    }
+    return target
+}
+
+func returnQuery(r *http.Request) string {
+    _, rawQuery, _ := strings.Cut(safeReturn(r.PostFormValue("return")), "?")
+    return rawQuery
+}
+
+func wantsJSON(r *http.Request) bool {
+    return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+func sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(statusCode)
+    json.NewEncoder(w).Encode(data)
+}
+
+func sendJSONError(w http.ResponseWriter, statusCode int, err error) {
+    resp := domain.ErrorResponse{
+        Error:   http.StatusText(statusCode),
+        Details: err.Error(),
+    }
+    if errors.Is(err, domain.ErrQuotaExceeded) {
+        resp.Code = domain.QuotaExceededCode
+    }
+    sendJSON(w, statusCode, resp)
+}
+
+// errorStatus is 403 for changes the user's role doesn't allow, 409 for
+// those over a quota and fallback otherwise.
+func errorStatus(err error, fallback int) int {
+    switch {
+    case errors.Is(err, domain.ErrForbidden):
+        return http.StatusForbidden
+    case errors.Is(err, domain.ErrQuotaExceeded):
+        return http.StatusConflict
+    }
+    return fallback
+}
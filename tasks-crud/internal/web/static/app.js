@@ -0,0 +1,55 @@
+// Progressive enhancement: without JavaScript every form posts and the server
+// redirects back. With it, toggling and deleting update the row in place.
+(function () {
+  "use strict";
+
+  async function submit(form) {
+    const response = await fetch(form.action, {
+      method: "POST",
+      body: new FormData(form),
+      headers: { "Accept": "application/json" },
+      credentials: "same-origin",
+    });
+    if (!response.ok) {
+      throw new Error("request failed with status " + response.status);
+    }
+    return response.status === 204 ? null : response.json();
+  }
+
+  const handlers = {
+    async toggle(form) {
+      const task = await submit(form);
+      const row = form.closest("tr");
+      const button = form.querySelector("button");
+      row.classList.toggle("done", task.completed);
+      button.textContent = task.completed ? "✓" : "○";
+      button.setAttribute("aria-pressed", String(task.completed));
+      button.title = task.completed ? "Вернуть в работу" : "Выполнено";
+    },
+
+    async delete(form) {
+      await submit(form);
+      form.closest("tr").remove();
+    },
+  };
+
+  document.addEventListener("submit", async function (event) {
+    const form = event.target;
+    const handler = handlers[form.dataset.enhance];
+    if (!handler) {
+      return;
+    }
+    if (form.dataset.confirm && !window.confirm(form.dataset.confirm)) {
+      event.preventDefault();
+      return;
+    }
+
+    event.preventDefault();
+    try {
+      await handler(form);
+    } catch (err) {
+      // Fall back to a regular form post so the server can render the result.
+      form.submit();
+    }
+  });
+})();